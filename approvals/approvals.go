@@ -0,0 +1,352 @@
+package approvals
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stateKey is the key of the runs in the state store.
+const stateKey = "approval_runs"
+
+// finishedRetention is how long runs are kept after they were rejected, completed or expired.
+const finishedRetention = 90 * 24 * time.Hour
+
+// DefaultExpiry is how long a run may await approval by default before it expires.
+const DefaultExpiry = 30 * 24 * time.Hour
+
+var (
+	// ErrNotFound is returned for runs that do not exist.
+	ErrNotFound = errors.New("run not found")
+	// ErrNotPending is returned when a run that is not pending is approved or rejected.
+	ErrNotPending = errors.New("run is not pending")
+	// ErrUnknownCandidate is returned when a dashboard that is not a candidate of a run is excluded from the run.
+	ErrUnknownCandidate = errors.New("dashboard is not a candidate of the run")
+)
+
+// Status is the stage of a run in the approval workflow.
+type Status string
+
+const (
+	// StatusPending runs await approval.
+	StatusPending Status = "pending"
+	// StatusApproved runs have been approved. Their candidates are deleted in the next prune run.
+	StatusApproved Status = "approved"
+	// StatusRejected runs have been rejected. Their candidates are not deleted.
+	StatusRejected Status = "rejected"
+	// StatusCompleted runs have been approved and their candidates have been deleted or dropped.
+	StatusCompleted Status = "completed"
+	// StatusExpired runs were not reviewed before they expired. Their candidates are not deleted, and a later prune run
+	// records those that are still unused again.
+	StatusExpired Status = "expired"
+)
+
+// store persists runs between restarts of Frigg.
+type store interface {
+	Get(key string, target any) (bool, error)
+	Set(key string, value any) error
+}
+
+// Candidate is an unused dashboard whose deletion awaits approval.
+type Candidate struct {
+	Name       string    `json:"name"`
+	UID        string    `json:"uid"`
+	Title      string    `json:"title"`
+	Folder     string    `json:"folder,omitempty"`
+	Owner      string    `json:"owner,omitempty"`
+	LastViewed time.Time `json:"last_viewed,omitzero"`
+	// Hash is the hash of the dashboard's JSON when the dashboard became a candidate. A dashboard that has changed since
+	// is not deleted.
+	Hash string `json:"hash"`
+}
+
+// Run is the set of candidates that a prune run found in a namespace.
+type Run struct {
+	// ID identifies the run across namespaces.
+	ID         string      `json:"id"`
+	Namespace  string      `json:"namespace"`
+	Status     Status      `json:"status"`
+	Created    time.Time   `json:"created"`
+	Candidates []Candidate `json:"candidates"`
+	// Excluded are the names of the candidates that the approver excluded from deletion.
+	Excluded []string `json:"excluded,omitempty"`
+	// Reviewed is when the run was approved or rejected.
+	Reviewed time.Time `json:"reviewed,omitzero"`
+	// Completed is when the run was completed or expired.
+	Completed time.Time `json:"completed,omitzero"`
+	// Deleted are the names of the candidates that were deleted once the run had been approved. Deleted is recorded
+	// before the run is completed if a prune run fails after it deleted some of the candidates.
+	Deleted []string `json:"deleted,omitempty"`
+	// Dropped are the names of the approved candidates that were not deleted, e.g., as they were viewed after the run.
+	Dropped []string `json:"dropped,omitempty"`
+}
+
+// RunID returns the ID of the run of a prune run in namespace with runID.
+func RunID(namespace, runID string) string {
+	return namespace + "-" + runID
+}
+
+// Approved returns the candidate with name if r has been approved and name was not excluded.
+func (r *Run) Approved(name string) (*Candidate, bool) {
+	if r.Status != StatusApproved || r.excluded(name) {
+		return nil, false
+	}
+
+	return r.candidate(name)
+}
+
+func (r *Run) candidate(name string) (*Candidate, bool) {
+	for i := range r.Candidates {
+		if r.Candidates[i].Name == name {
+			return &r.Candidates[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Run) excluded(name string) bool {
+	for _, excluded := range r.Excluded {
+		if excluded == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry records runs in a state store.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	// mu guards the runs in store, which are read, changed and written back as a whole.
+	mu    sync.Mutex
+	store store
+	// expiry is how long a run may await approval before it expires.
+	expiry time.Duration
+}
+
+// NewRegistry creates a Registry whose pending runs expire once they have awaited approval for expiry.
+func NewRegistry(store store, expiry time.Duration) *Registry {
+	return &Registry{store: store, expiry: expiry}
+}
+
+// Propose records run as pending. Propose removes runs that were rejected, completed or expired more than 90 days
+// before run was created.
+func (r *Registry) Propose(run *Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]Run, 0, len(runs)+1)
+	for _, existing := range runs {
+		finished := existing.Completed
+		if existing.Status == StatusRejected {
+			finished = existing.Reviewed
+		}
+		if !finished.IsZero() && run.Created.Sub(finished) > finishedRetention {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+
+	run.Status = StatusPending
+	kept = append(kept, *run)
+
+	return r.save(kept)
+}
+
+// List returns all runs, sorted by creation time.
+func (r *Registry) List() ([]Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Created.Before(runs[j].Created)
+	})
+
+	return runs, nil
+}
+
+// Open returns the pending and approved runs in namespace at now, sorted by creation time. Open first expires the
+// pending runs in namespace that have awaited approval for longer than the expiry of the Registry.
+func (r *Registry) Open(namespace string, now time.Time) ([]Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := false
+	for i := range runs {
+		run := &runs[i]
+		if run.Namespace == namespace && run.Status == StatusPending && now.Sub(run.Created) > r.expiry {
+			run.Status = StatusExpired
+			run.Completed = now
+			expired = true
+		}
+	}
+
+	if expired {
+		if err := r.save(runs); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Created.Before(runs[j].Created)
+	})
+
+	open := make([]Run, 0, len(runs))
+	for _, run := range runs {
+		if run.Namespace == namespace && (run.Status == StatusPending || run.Status == StatusApproved) {
+			open = append(open, run)
+		}
+	}
+
+	return open, nil
+}
+
+// Get returns the run with id. Get returns ErrNotFound if the run does not exist.
+func (r *Registry) Get(id string) (*Run, error) {
+	runs, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].ID == id {
+			return &runs[i], nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Approve approves the pending run with id, excluding the candidates named in exclude from deletion.
+func (r *Registry) Approve(id string, exclude []string, now time.Time) (*Run, error) {
+	return r.update(id, func(run *Run) error {
+		if run.Status != StatusPending {
+			return ErrNotPending
+		}
+
+		for _, name := range exclude {
+			if _, ok := run.candidate(name); !ok {
+				return errors.Wrapf(ErrUnknownCandidate, "excluding %q", name)
+			}
+		}
+
+		run.Status = StatusApproved
+		run.Excluded = exclude
+		run.Reviewed = now
+
+		return nil
+	})
+}
+
+// Reject rejects the pending run with id.
+func (r *Registry) Reject(id string, now time.Time) (*Run, error) {
+	return r.update(id, func(run *Run) error {
+		if run.Status != StatusPending {
+			return ErrNotPending
+		}
+
+		run.Status = StatusRejected
+		run.Reviewed = now
+
+		return nil
+	})
+}
+
+// Complete records that the candidates named in deleted were deleted and that those named in dropped were not.
+func (r *Registry) Complete(id string, deleted, dropped []string, now time.Time) error {
+	_, err := r.update(id, func(run *Run) error {
+		run.Status = StatusCompleted
+		run.Completed = now
+		run.Deleted = deleted
+		run.Dropped = dropped
+
+		return nil
+	})
+
+	return err
+}
+
+// Record records that the candidates named in deleted were deleted without completing the run with id. Record is used
+// when a prune run fails after it deleted some of the approved candidates, so that a later prune run does not report
+// them as dropped when it completes the run.
+func (r *Registry) Record(id string, deleted []string) error {
+	_, err := r.update(id, func(run *Run) error {
+		for _, name := range deleted {
+			if !slices.Contains(run.Deleted, name) {
+				run.Deleted = append(run.Deleted, name)
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// update applies change to the run with id and saves the run unless change returns an error.
+func (r *Registry) update(id string, change func(run *Run) error) (*Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].ID != id {
+			continue
+		}
+
+		if err := change(&runs[i]); err != nil {
+			return nil, err
+		}
+
+		if err := r.save(runs); err != nil {
+			return nil, err
+		}
+
+		return &runs[i], nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// load returns the runs in the store. load must be called with mu held.
+func (r *Registry) load() ([]Run, error) {
+	var runs []Run
+	if _, err := r.store.Get(stateKey, &runs); err != nil {
+		return nil, errors.Wrap(err, "getting approval runs")
+	}
+
+	return runs, nil
+}
+
+// save writes runs to the store. save must be called with mu held.
+func (r *Registry) save(runs []Run) error {
+	if err := r.store.Set(stateKey, runs); err != nil {
+		return errors.Wrap(err, "saving approval runs")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package approvals_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/approvals"
+	"github.com/LasseHels/frigg/state"
+)
+
+func newRegistry(t *testing.T) *approvals.Registry {
+	t.Helper()
+
+	store, err := state.NewStore("")
+	require.NoError(t, err)
+
+	return approvals.NewRegistry(store, approvals.DefaultExpiry)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	candidates := []approvals.Candidate{
+		{Name: "latency", UID: "uid1", Title: "Latency", Hash: "hash1"},
+		{Name: "errors", UID: "uid2", Title: "Errors", Hash: "hash2"},
+	}
+
+	t.Run("approves run", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		id := approvals.RunID("default", "20261001T120000Z")
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:         id,
+			Namespace:  "default",
+			Created:    created,
+			Candidates: candidates,
+		}))
+
+		open, err := registry.Open("default", created)
+		require.NoError(t, err)
+		require.Len(t, open, 1)
+		assert.Equal(t, "default-20261001T120000Z", open[0].ID)
+		assert.Equal(t, approvals.StatusPending, open[0].Status)
+
+		other, err := registry.Open("org-2", created)
+		require.NoError(t, err)
+		assert.Empty(t, other)
+
+		approved := created.Add(time.Hour)
+		run, err := registry.Approve(id, []string{"errors"}, approved)
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusApproved, run.Status)
+		assert.Equal(t, approved, run.Reviewed)
+
+		_, ok := run.Approved("errors")
+		assert.False(t, ok, "excluded candidate must not be approved")
+		candidate, ok := run.Approved("latency")
+		require.True(t, ok)
+		assert.Equal(t, "hash1", candidate.Hash)
+
+		_, err = registry.Approve(id, nil, approved)
+		require.ErrorIs(t, err, approvals.ErrNotPending)
+
+		require.NoError(t, registry.Complete(id, []string{"latency"}, nil, approved.Add(time.Hour)))
+
+		run, err = registry.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusCompleted, run.Status)
+		assert.Equal(t, []string{"latency"}, run.Deleted)
+
+		open, err = registry.Open("default", created)
+		require.NoError(t, err)
+		assert.Empty(t, open)
+	})
+
+	t.Run("rejects exclusion of unknown candidate", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:         "default-1",
+			Namespace:  "default",
+			Created:    created,
+			Candidates: candidates,
+		}))
+
+		_, err := registry.Approve("default-1", []string{"hosts"}, created)
+		require.ErrorIs(t, err, approvals.ErrUnknownCandidate)
+		require.EqualError(t, err, `excluding "hosts": dashboard is not a candidate of the run`)
+
+		run, err := registry.Get("default-1")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusPending, run.Status)
+	})
+
+	t.Run("rejects run", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:         "default-1",
+			Namespace:  "default",
+			Created:    created,
+			Candidates: candidates,
+		}))
+
+		run, err := registry.Reject("default-1", created)
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusRejected, run.Status)
+		_, ok := run.Approved("latency")
+		assert.False(t, ok)
+
+		_, err = registry.Reject("default-2", created)
+		require.ErrorIs(t, err, approvals.ErrNotFound)
+	})
+
+	t.Run("removes runs that finished long ago", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		require.NoError(t, registry.Propose(&approvals.Run{ID: "default-1", Namespace: "default", Created: created}))
+		_, err := registry.Reject("default-1", created)
+		require.NoError(t, err)
+
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:        "default-2",
+			Namespace: "default",
+			Created:   created.Add(89 * 24 * time.Hour),
+		}))
+		runs, err := registry.List()
+		require.NoError(t, err)
+		assert.Len(t, runs, 2)
+
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:        "default-3",
+			Namespace: "default",
+			Created:   created.Add(91 * 24 * time.Hour),
+		}))
+		runs, err = registry.List()
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, "default-2", runs[0].ID)
+		assert.Equal(t, "default-3", runs[1].ID)
+	})
+
+	t.Run("expires pending runs", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		require.NoError(t, registry.Propose(&approvals.Run{ID: "default-1", Namespace: "default", Created: created}))
+		require.NoError(t, registry.Propose(&approvals.Run{ID: "default-2", Namespace: "default", Created: created}))
+		_, err := registry.Approve("default-2", nil, created)
+		require.NoError(t, err)
+		require.NoError(t, registry.Propose(&approvals.Run{ID: "org-2-1", Namespace: "org-2", Created: created}))
+
+		open, err := registry.Open("default", created.Add(approvals.DefaultExpiry))
+		require.NoError(t, err)
+		assert.Len(t, open, 2, "runs must not expire before the expiry has passed")
+
+		expired := created.Add(approvals.DefaultExpiry + time.Minute)
+		open, err = registry.Open("default", expired)
+		require.NoError(t, err)
+		require.Len(t, open, 1, "approved runs must not expire")
+		assert.Equal(t, "default-2", open[0].ID)
+
+		run, err := registry.Get("default-1")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusExpired, run.Status)
+		assert.Equal(t, expired, run.Completed)
+
+		_, err = registry.Approve("default-1", nil, expired)
+		require.ErrorIs(t, err, approvals.ErrNotPending)
+
+		run, err = registry.Get("org-2-1")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusPending, run.Status, "runs in other namespaces must not expire")
+	})
+
+	t.Run("records deleted candidates", func(t *testing.T) {
+		t.Parallel()
+
+		registry := newRegistry(t)
+		require.NoError(t, registry.Propose(&approvals.Run{
+			ID:         "default-1",
+			Namespace:  "default",
+			Created:    created,
+			Candidates: candidates,
+		}))
+		_, err := registry.Approve("default-1", nil, created)
+		require.NoError(t, err)
+
+		require.NoError(t, registry.Record("default-1", []string{"latency"}))
+		require.NoError(t, registry.Record("default-1", []string{"latency", "errors"}))
+
+		run, err := registry.Get("default-1")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusApproved, run.Status)
+		assert.Equal(t, []string{"latency", "errors"}, run.Deleted)
+
+		require.ErrorIs(t, registry.Record("default-2", nil), approvals.ErrNotFound)
+	})
+}
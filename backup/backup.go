@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Dashboard is a Grafana dashboard that is backed up before it is deleted.
+type Dashboard struct {
+	Namespace string
+	Name      string
+	UID       string
+	Title     string
+	// FolderUID is the UID of the folder that contains the dashboard. FolderUID is empty for dashboards that are not in
+	// a folder.
+	FolderUID string
+	// FolderTitle is the title of the folder that contains the dashboard.
+	FolderTitle       string
+	Tags              []string
+	CreationTimestamp time.Time
+	// Reads is the number of times the dashboard was read in the prune period.
+	Reads int
+	// Users is the number of unique users that read the dashboard in the prune period.
+	Users int
+	// LastViewed is the time of the last read in the prune period. LastViewed is zero if the dashboard was not read in
+	// the prune period.
+	LastViewed time.Time
+	// Owner is the person or team that owns the dashboard, e.g., an email address. Owner is empty if the owner of the
+	// dashboard could not be resolved.
+	Owner string
+	// Bots are the users whose reads the prune run excluded from dashboard usage as they read dashboards in bulk. All
+	// dashboards backed up in the same run share the same Bots.
+	Bots []string
+	// RunID identifies the prune run that backed up the dashboard. All dashboards backed up in the same run share the
+	// same RunID.
+	RunID string
+	// RunStart is the time at which the prune run that backed up the dashboard started.
+	RunStart time.Time
+	// JSON is the dashboard's raw JSON spec. JSON is the content that is written to storage.
+	JSON []byte
+	// Location is the URL at which the backup of the dashboard can be viewed. The storage backend sets Location when it
+	// backs up the dashboard. Location is empty if the dashboard has not been backed up.
+	Location string
+}
+
+// Hash returns the hex-encoded SHA-256 hash of the dashboard's JSON.
+func (d *Dashboard) Hash() string {
+	return Hash(d.JSON)
+}
+
+// Hash returns the hex-encoded SHA-256 hash of content.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ProposalState describes how far a proposed deletion has come in its review.
+type ProposalState string
+
+const (
+	// ProposalPending is a proposal that has not been reviewed yet.
+	ProposalPending ProposalState = "pending"
+	// ProposalApproved is a proposal whose dashboards may be deleted.
+	ProposalApproved ProposalState = "approved"
+	// ProposalRejected is a proposal whose dashboards must not be deleted.
+	ProposalRejected ProposalState = "rejected"
+)
+
+// Proposal is a set of dashboards from a single namespace whose deletion must be reviewed before it happens.
+type Proposal struct {
+	// ID identifies the proposal in the storage backend, e.g., a pull request number.
+	ID         string
+	URL        string
+	Namespace  string
+	State      ProposalState
+	Dashboards []ProposedDashboard
+}
+
+// ProposedDashboard is a dashboard whose deletion has been proposed.
+type ProposedDashboard struct {
+	Name string `json:"name"`
+	// Hash of the dashboard's JSON at the time the deletion was proposed. See Dashboard.Hash.
+	Hash string `json:"hash"`
+	// Path of the dashboard's backup in the storage backend. Path is empty for proposals made before paths were
+	// recorded.
+	Path string `json:"path,omitempty"`
+	// Location is the URL at which the backup of the dashboard can be viewed once the proposal has been approved. See
+	// Dashboard.Location. Location is empty if the proposal has not been approved or if Path is empty.
+	Location string `json:"-"`
+}
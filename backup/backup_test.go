@@ -0,0 +1,17 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+func TestDashboard_Hash(t *testing.T) {
+	t.Parallel()
+
+	d := &backup.Dashboard{JSON: []byte(`{"title":"Dashboard 1"}`)}
+
+	assert.Equal(t, "aafb26ee015dba5c6f13aba5ffb53e224a87a2c7ed0386e234e44e4d3c768e0d", d.Hash())
+}
@@ -17,16 +17,25 @@ import (
 	"go.uber.org/multierr"
 	"gopkg.in/yaml.v3"
 
+	"github.com/LasseHels/frigg/approvals"
+	"github.com/LasseHels/frigg/frigg/handlers"
 	"github.com/LasseHels/frigg/github"
 	"github.com/LasseHels/frigg/grafana"
 	"github.com/LasseHels/frigg/log"
 	"github.com/LasseHels/frigg/loki"
+	"github.com/LasseHels/frigg/notify"
 	"github.com/LasseHels/frigg/server"
+	"github.com/LasseHels/frigg/snooze"
+	"github.com/LasseHels/frigg/state"
 )
 
 type Secrets struct {
 	Grafana grafana.Secrets `yaml:"grafana" json:"grafana" validate:"required"`
 	Backup  BackupSecrets   `yaml:"backup" json:"backup" validate:"required"`
+	Notify  notify.Secrets  `yaml:"notify" json:"notify"`
+	Server  server.Secrets  `yaml:"server" json:"server"`
+	// Snooze is required if snooze links are enabled.
+	Snooze *snooze.Secrets `yaml:"snooze" json:"snooze"`
 }
 
 type BackupSecrets struct {
@@ -40,10 +49,18 @@ type Config struct {
 	Grafana grafana.Config      `yaml:"grafana" validate:"required"`
 	Prune   grafana.PruneConfig `yaml:"prune" validate:"required"`
 	Backup  BackupConfig        `yaml:"backup" validate:"required"`
+	State   state.Config        `yaml:"state"`
+	// Notify is nil if the owners of dashboards are not notified.
+	Notify *notify.Config `yaml:"notify"`
+	// Snooze is nil if snooze links are disabled.
+	Snooze *snooze.Config `yaml:"snooze"`
 }
 
 type BackupConfig struct {
 	GitHub github.Config `yaml:"github" validate:"required"`
+	// Retention is how long backups are kept after their dashboards were deleted. Backups are kept forever if Retention
+	// is zero. Retention has a minimum value of 1 hour (3600000000000 nanoseconds).
+	Retention time.Duration `yaml:"retention" validate:"omitempty,min=3600000000000"`
 }
 
 // NewConfig creates a new Config with default values and loads configuration from the given path.
@@ -63,6 +80,23 @@ func NewConfig(path string) (*Config, error) {
 		c.Prune.ChunkSize = c.Prune.Period
 	}
 
+	if c.Snooze != nil && c.Snooze.Duration == 0 {
+		c.Snooze.Duration = snooze.DefaultDuration
+	}
+
+	if c.Prune.Approval != nil && c.Prune.Approval.Expiry == 0 {
+		c.Prune.Approval.Expiry = approvals.DefaultExpiry
+	}
+
+	if archive := c.Prune.Archive; archive != nil {
+		if archive.Folder == "" {
+			archive.Folder = grafana.DefaultArchiveFolder
+		}
+		if archive.Title == "" {
+			archive.Title = grafana.DefaultArchiveTitle
+		}
+	}
+
 	return c, nil
 }
 
@@ -94,8 +128,12 @@ func (c *Config) defaults() {
 	c.Prune.Interval = 10 * time.Minute
 	c.Prune.LowerThreshold = 10
 	c.Prune.ChunkSize = 4 * time.Hour
+	c.Prune.Action = grafana.ActionDelete
 	c.Backup.GitHub.Branch = "main"
 	c.Backup.GitHub.Directory = "deleted-dashboards"
+	c.Backup.GitHub.Mode = github.ModeCommit
+	c.Backup.GitHub.PathTemplate = github.DefaultPathTemplate
+	c.Backup.GitHub.MessageTemplate = github.DefaultMessageTemplate
 	defaultQueryLimit := 100
 	c.Loki.QueryLimit = &defaultQueryLimit
 }
@@ -126,11 +164,32 @@ func (c *Config) load(path string) error {
 func (c *Config) newGitHubClient(
 	httpClient *http.Client,
 	secrets *Secrets,
+	store *state.Store,
+	registerer prometheus.Registerer,
 	logger *slog.Logger,
 ) (*github.Client, error) {
-	client := gogithub.NewClient(httpClient).WithAuthToken(secrets.Backup.GitHub.Token)
+	templates, err := c.Backup.GitHub.Templates()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing backup templates")
+	}
+
+	var client *gogithub.Client
+	if app := secrets.Backup.GitHub.App; app != nil {
+		transport, err := github.NewAppTransport(&github.AppTransportOptions{
+			Base:    httpClient.Transport,
+			APIURL:  c.Backup.GitHub.APIURL,
+			Secrets: app,
+			Logger:  logger,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "creating GitHub App transport")
+		}
+		client = gogithub.NewClient(&http.Client{Transport: transport})
+	} else {
+		client = gogithub.NewClient(httpClient).WithAuthToken(secrets.Backup.GitHub.Token)
+	}
+
 	if c.Backup.GitHub.APIURL != "" {
-		var err error
 		client, err = client.WithEnterpriseURLs(c.Backup.GitHub.APIURL, c.Backup.GitHub.APIURL)
 		if err != nil {
 			return nil, errors.Wrap(err, "setting GitHub API URL")
@@ -138,14 +197,26 @@ func (c *Config) newGitHubClient(
 	}
 
 	return github.NewClient(&github.ClientOptions{
-		Client:     client,
-		Repository: c.Backup.GitHub.Repository,
-		Branch:     c.Backup.GitHub.Branch,
-		Directory:  c.Backup.GitHub.Directory,
-		Logger:     logger,
+		Client:        client,
+		Repository:    c.Backup.GitHub.Repository,
+		Branch:        c.Backup.GitHub.Branch,
+		Directory:     c.Backup.GitHub.Directory,
+		Logger:        logger,
+		Mode:          c.Backup.GitHub.Mode,
+		DeferDeletion: c.deferDeletion(),
+		Verify:        c.Backup.GitHub.Verify,
+		Metrics:       github.NewMetrics(registerer),
+		Templates:     templates,
+		Retention:     c.Backup.Retention,
+		State:         store,
 	}), nil
 }
 
+// deferDeletion reports whether dashboards must only be deleted once their deletion has been approved.
+func (c *Config) deferDeletion() bool {
+	return c.Backup.GitHub.PullRequest != nil && c.Backup.GitHub.PullRequest.DeferDeletion
+}
+
 // mustParseURL parses a URL and panics if it cannot be parsed.
 // This should only be used when the URL has already been validated.
 func mustParseURL(rawURL string) *url.URL {
@@ -157,8 +228,9 @@ func mustParseURL(rawURL string) *url.URL {
 }
 
 // Initialise Frigg from the provided Config.
-// Initialise assumes that the provided Config has already been validated and might panic if not.
-func (c *Config) Initialise(logger *slog.Logger, gatherer prometheus.Gatherer, secrets *Secrets) (*Frigg, error) {
+// Initialise assumes that the provided Config has already been validated, including against secrets with
+// ValidateSecrets, and might panic if not.
+func (c *Config) Initialise(logger *slog.Logger, registry *prometheus.Registry, secrets *Secrets) (*Frigg, error) {
 	s := server.New(c.Server, logger)
 
 	httpClient := &http.Client{}
@@ -173,11 +245,76 @@ func (c *Config) Initialise(logger *slog.Logger, gatherer prometheus.Gatherer, s
 
 	grafanaURL := mustParseURL(c.Grafana.Endpoint)
 
-	githubClient, err := c.newGitHubClient(httpClient, secrets, logger)
+	store, err := state.NewStore(c.State.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating state store")
+	}
+
+	githubClient, err := c.newGitHubClient(httpClient, secrets, store, registry, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating GitHub client")
 	}
 
+	prunerMetrics := grafana.NewMetrics(registry)
+
+	var policy *grafana.Policy
+	if c.Prune.Policy != nil {
+		policy, err = c.Prune.Policy.Compile()
+		if err != nil {
+			return nil, errors.Wrap(err, "compiling prune policy")
+		}
+	}
+
+	var ignore grafana.IgnoreConfig
+	if c.Prune.Ignore != nil {
+		ignore = *c.Prune.Ignore
+	}
+
+	ignoredUserPatterns, err := ignore.CompileUserPatterns()
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling ignored user patterns")
+	}
+
+	var protect grafana.ProtectConfig
+	if c.Prune.Protect != nil {
+		protect = *c.Prune.Protect
+	}
+
+	var routes []server.Route
+	var links *snooze.Links
+	var snoozes *snooze.Registry
+	if c.Snooze != nil {
+		signer := snooze.NewSigner(secrets.Snooze.SigningKey)
+		links = snooze.NewLinks(mustParseURL(c.Snooze.ExternalURL), c.Snooze.Duration, signer)
+		snoozes = snooze.NewRegistry(store)
+		routes = append(routes, c.snoozeRoutes(snoozes, signer, secrets, logger)...)
+	}
+
+	var runs *approvals.Registry
+	if c.Prune.Approval != nil {
+		runs = approvals.NewRegistry(store, c.Prune.Approval.Expiry)
+		routes = append(routes, runRoutes(runs, secrets.Server.APIToken, logger)...)
+	}
+
+	var notifier *notify.Notifier
+	var owners notify.OwnersConfig
+	if c.Notify != nil {
+		notifyOpts := &notify.NewNotifierOptions{
+			Config:     c.Notify,
+			Secrets:    &secrets.Notify,
+			HTTPClient: httpClient,
+			Logger:     logger,
+		}
+		if links != nil {
+			notifyOpts.Snoozer = links
+		}
+		notifier, err = notify.NewNotifier(notifyOpts)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating notifier")
+		}
+		owners = c.Notify.Owners
+	}
+
 	var pruners []dashboardPruner
 	for namespace, token := range secrets.Grafana.Tokens {
 		grafanaClient, err := grafana.NewClient(&grafana.NewClientOptions{
@@ -192,39 +329,229 @@ func (c *Config) Initialise(logger *slog.Logger, gatherer prometheus.Gatherer, s
 			return nil, errors.Wrapf(err, "creating Grafana client for namespace %s", namespace)
 		}
 
-		var skipTags []string
-		if c.Prune.Skip != nil && c.Prune.Skip.Tags != nil {
-			skipTags = c.Prune.Skip.Tags.Any
+		var skipTags, skipFolders []string
+		if c.Prune.Skip != nil {
+			if c.Prune.Skip.Tags != nil {
+				skipTags = c.Prune.Skip.Tags.Any
+			}
+			skipFolders = c.Prune.Skip.Folders
 		}
 
-		pruner := grafana.NewDashboardPruner(&grafana.NewDashboardPrunerOptions{
-			Grafana:        grafanaClient,
-			Logger:         logger,
-			Namespace:      namespace,
-			Interval:       c.Prune.Interval,
-			IgnoredUsers:   c.Prune.IgnoredUsers,
-			Period:         c.Prune.Period,
-			Labels:         c.Prune.Labels,
-			Dry:            c.Prune.Dry,
-			LowerThreshold: c.Prune.LowerThreshold,
-			SkipTags:       skipTags,
-			MaxDeletions:   c.Prune.MaxDeletions,
-			ChunkSize:      c.Prune.ChunkSize,
-		})
+		var includeFolders []string
+		if c.Prune.Include != nil {
+			includeFolders = c.Prune.Include.Folders
+		}
+
+		var canaries []grafana.CanaryConfig
+		var retention *grafana.RetentionConfig
+		if c.Prune.Safety != nil {
+			canaries = c.Prune.Safety.Canaries
+			retention = c.Prune.Safety.Retention
+		}
+
+		opts := &grafana.NewDashboardPrunerOptions{
+			Grafana:               grafanaClient,
+			Logger:                logger,
+			Namespace:             namespace,
+			Interval:              c.Prune.Interval,
+			IgnoredUsers:          c.Prune.IgnoredUsers,
+			Period:                c.Prune.Period,
+			Labels:                c.Prune.Labels,
+			Dry:                   c.Prune.Dry,
+			LowerThreshold:        c.Prune.LowerThreshold,
+			MinReads:              c.Prune.MinReads,
+			MinUsers:              c.Prune.MinUsers,
+			SkipTags:              skipTags,
+			SkipFolders:           skipFolders,
+			IncludeFolders:        includeFolders,
+			MaxDeletions:          c.Prune.MaxDeletions,
+			ChunkSize:             c.Prune.ChunkSize,
+			Backups:               githubClient,
+			DeferDeletion:         c.deferDeletion(),
+			MaxDeletionPercentage: c.Prune.MaxDeletionPercentage,
+			AnomalyDetection:      c.Prune.AnomalyDetection,
+			History:               store,
+			Canaries:              canaries,
+			Retention:             retention,
+			Policy:                policy,
+			BotDetection:          c.Prune.BotDetection,
+			IgnoredUserPatterns:   ignoredUserPatterns,
+			IgnoreServiceAccounts: ignore.ServiceAccounts,
+			IgnoredTeams:          ignore.Teams,
+			IgnoredRoles:          ignore.Roles,
+			QueryReads:            c.Prune.QueryReads,
+			EditWindow:            c.Prune.EditWindow,
+			ProtectReferences:     protect.References,
+			ReferenceDepth:        protect.ReferenceDepth,
+			ProtectAlertRules:     protect.AlertRules,
+			ProtectStarred:        protect.Starred,
+			ProtectPublic:         protect.PublicDashboards,
+			ProtectSnapshots:      protect.Snapshots,
+			OwnerFolders:          owners.Folders,
+			DefaultOwner:          owners.Default,
+			Archive:               c.Prune.Archive,
+			Tombstone:             c.Prune.Tombstone,
+			Metrics:               prunerMetrics,
+		}
+		// A nil *notify.Notifier must not be assigned to the interface-typed Notifier as it would not compare to nil.
+		if notifier != nil {
+			opts.Notifier = notifier
+		}
+		if snoozes != nil {
+			opts.Snoozes = snoozes
+		}
+		if runs != nil && c.Prune.Approval.Requires(namespace) {
+			opts.Approvals = runs
+		}
+
+		pruner := grafana.NewDashboardPruner(opts)
 		pruners = append(pruners, pruner)
 	}
 
-	return New(logger, s, gatherer, pruners), nil
+	return New(logger, s, registry, pruners, routes), nil
+}
+
+// snoozeRoutes returns the route that records snooze links and, if an API token is configured, the authenticated
+// routes that list and revoke snoozes.
+func (c *Config) snoozeRoutes(
+	snoozes *snooze.Registry,
+	signer *snooze.Signer,
+	secrets *Secrets,
+	logger *slog.Logger,
+) []server.Route {
+	routes := []server.Route{
+		{
+			Path:    "/api/v1/snooze",
+			Methods: []string{http.MethodGet, http.MethodPost},
+			Func:    handlers.Snooze(snoozes, signer, logger),
+		},
+	}
+
+	token := secrets.Server.APIToken
+	if token == "" {
+		return routes
+	}
+
+	return append(
+		routes,
+		server.Route{
+			Path:    "/api/v1/snoozes",
+			Methods: []string{http.MethodGet},
+			Func:    handlers.Authenticated(token, handlers.Snoozes(snoozes, logger)),
+		},
+		server.Route{
+			Path:    "/api/v1/snoozes/{namespace}/{name}",
+			Methods: []string{http.MethodDelete},
+			Func:    handlers.Authenticated(token, handlers.RevokeSnooze(snoozes, logger)),
+		},
+	)
+}
+
+// runRoutes returns the authenticated routes that list, approve and reject approval runs.
+func runRoutes(runs *approvals.Registry, token string, logger *slog.Logger) []server.Route {
+	return []server.Route{
+		{
+			Path:    "/api/v1/runs",
+			Methods: []string{http.MethodGet},
+			Func:    handlers.Authenticated(token, handlers.Runs(runs, logger)),
+		},
+		{
+			Path:    "/api/v1/runs/{id}",
+			Methods: []string{http.MethodGet},
+			Func:    handlers.Authenticated(token, handlers.Run(runs, logger)),
+		},
+		{
+			Path:    "/api/v1/runs/{id}/approve",
+			Methods: []string{http.MethodPost},
+			Func:    handlers.Authenticated(token, handlers.ApproveRun(runs, logger)),
+		},
+		{
+			Path:    "/api/v1/runs/{id}/reject",
+			Methods: []string{http.MethodPost},
+			Func:    handlers.Authenticated(token, handlers.RejectRun(runs, logger)),
+		},
+	}
 }
 
 // validate ensures the configuration is valid.
 func (c *Config) validate() error {
-	return validate(c)
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	if _, err := c.Backup.GitHub.Templates(); err != nil {
+		return errors.Wrap(err, "validating backup templates")
+	}
+
+	if c.Prune.Policy != nil {
+		if _, err := c.Prune.Policy.Compile(); err != nil {
+			return errors.Wrap(err, "validating prune policy")
+		}
+	}
+
+	if c.Prune.Ignore != nil {
+		if _, err := c.Prune.Ignore.CompileUserPatterns(); err != nil {
+			return errors.Wrap(err, "validating ignored user patterns")
+		}
+	}
+
+	if c.Prune.AnomalyDetection != nil && c.State.Path == "" {
+		return errors.New(
+			"prune.anomaly_detection requires state.path, as the statistics of previous runs are otherwise lost when " +
+				"Frigg restarts",
+		)
+	}
+
+	if c.Prune.Approval != nil && c.deferDeletion() {
+		return errors.New("prune.approval cannot be combined with backup.github.pull_request.defer_deletion")
+	}
+
+	if c.Prune.Action == grafana.ActionArchive && (c.Prune.Approval != nil || c.deferDeletion()) {
+		return errors.New(
+			"prune.action archive cannot be combined with prune.approval or backup.github.pull_request.defer_deletion",
+		)
+	}
+
+	if c.Snooze != nil && c.State.Path == "" {
+		return errors.New("snooze requires state.path, as snoozes are otherwise lost when Frigg restarts")
+	}
+
+	if c.Prune.Approval != nil && c.State.Path == "" {
+		return errors.New(
+			"prune.approval requires state.path, as pending and approved runs are otherwise lost when Frigg restarts",
+		)
+	}
+
+	return nil
+}
+
+// ValidateSecrets ensures that secrets hold the secrets that the features enabled in the Config require. The Config
+// and secrets must have been validated on their own already.
+func (c *Config) ValidateSecrets(secrets *Secrets) error {
+	if c.Snooze != nil && secrets.Snooze == nil {
+		return errors.New("snooze requires secrets.snooze, as snooze links are signed with its signing key")
+	}
+
+	if c.Prune.Approval != nil && secrets.Server.APIToken == "" {
+		return errors.New("prune.approval requires secrets.server.api_token, as approval runs are reviewed through the API")
+	}
+
+	return nil
 }
 
 // validate ensures the secrets configuration is valid.
 func (s *Secrets) validate() error {
-	return validate(s)
+	if err := validate(s); err != nil {
+		return err
+	}
+
+	// Parse the GitHub App private key now rather than when Frigg is initialised so that a malformed key is reported
+	// alongside the other validation errors.
+	if s.Backup.GitHub.App != nil {
+		return s.Backup.GitHub.App.Validate()
+	}
+
+	return nil
 }
 
 // validate performs validation on any struct using the validator package.
@@ -2,18 +2,23 @@ package frigg_test
 
 import (
 	"log/slog"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/LasseHels/frigg/approvals"
 	"github.com/LasseHels/frigg/frigg"
 	"github.com/LasseHels/frigg/github"
 	"github.com/LasseHels/frigg/grafana"
 	"github.com/LasseHels/frigg/log"
 	"github.com/LasseHels/frigg/loki"
+	"github.com/LasseHels/frigg/notify"
 	"github.com/LasseHels/frigg/server"
+	"github.com/LasseHels/frigg/snooze"
+	"github.com/LasseHels/frigg/state"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -55,28 +60,101 @@ func TestNewConfig(t *testing.T) {
 					Dry:          false,
 					Interval:     5 * time.Minute,
 					IgnoredUsers: []string{"admin"},
-					Period:       720 * time.Hour,
+					Ignore: &grafana.IgnoreConfig{
+						UserPatterns:    []string{"^sa-"},
+						ServiceAccounts: true,
+						Teams:           []string{"NOC"},
+						Roles:           []string{"Admin"},
+					},
+					Period: 720 * time.Hour,
 					Labels: map[string]string{
 						"app": "grafana",
 						"env": "test",
 					},
 					LowerThreshold: 50,
+					MinReads:       3,
+					MinUsers:       2,
+					QueryReads:     true,
+					EditWindow:     168 * time.Hour,
 					Skip: &grafana.SkipConfig{
 						Tags: &grafana.SkipTagsConfig{
 							Any: []string{"keep", "safeguard"},
 						},
+						Folders: []string{"Shared", "fef30w4jaxla8b"},
+					},
+					Include: &grafana.IncludeConfig{
+						Folders: []string{"Sandbox"},
+					},
+					MaxDeletions:          intPtr(25),
+					MaxDeletionPercentage: floatPtr(12.5),
+					ChunkSize:             4 * time.Hour,
+					Action:                grafana.ActionDelete,
+					AnomalyDetection: &grafana.AnomalyDetectionConfig{
+						MaxDrop:          0.4,
+						Runs:             5,
+						IgnoreRunsBefore: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+					},
+					Safety: &grafana.SafetyConfig{
+						Canaries: []grafana.CanaryConfig{
+							{Namespace: "default", Name: "frigg-canary"},
+						},
+						Retention: &grafana.RetentionConfig{
+							Action:    grafana.RetentionActionClamp,
+							Tolerance: 2 * time.Hour,
+						},
+					},
+					Policy: &grafana.PolicyConfig{
+						Skip:  []string{`dashboard.title.startsWith("[Team-X]")`},
+						Force: []string{`dashboard.reads == 0 && dashboard.created < now - duration("2160h")`},
+					},
+					BotDetection: &grafana.BotDetectionConfig{
+						MaxDashboards:          50,
+						Window:                 10 * time.Minute,
+						MaxDashboardPercentage: floatPtr(80),
+					},
+					Protect: &grafana.ProtectConfig{
+						References:       true,
+						ReferenceDepth:   2,
+						AlertRules:       true,
+						Starred:          true,
+						PublicDashboards: true,
+						Snapshots:        true,
 					},
-					MaxDeletions: intPtr(25),
-					ChunkSize:    4 * time.Hour,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "backup-branch",
-						Directory:  "archived-dashboards",
-						APIURL:     "https://github.example.com/api/v3",
+						Repository:      exampleRepository(t),
+						Branch:          "backup-branch",
+						Directory:       "archived-dashboards",
+						APIURL:          "https://github.example.com/api/v3",
+						Mode:            github.ModeCommit,
+						PathTemplate:    "{{ .Date }}/{{ .FolderTitle | slug }}/{{ .UID }}.json",
+						MessageTemplate: "Back up {{ .Title }} ({{ .RunID }})",
+						Verify:          true,
+					},
+				},
+				State: state.Config{
+					Path: "/var/lib/frigg/state.json",
+				},
+				Notify: &notify.Config{
+					Events: []notify.Event{notify.EventDeleted},
+					Owners: notify.OwnersConfig{
+						Folders: map[string]string{"Sandbox": "sandbox-team@example.com"},
+						Default: "grafana-admins@example.com",
+					},
+					Webhook: &notify.WebhookConfig{URL: "https://hooks.example.com/frigg"},
+					Email: &notify.EmailConfig{
+						Host:     "smtp.example.com",
+						Port:     587,
+						From:     "frigg@example.com",
+						Username: "frigg",
+						To:       []string{"grafana-admins@example.com"},
 					},
 				},
+				Snooze: &snooze.Config{
+					ExternalURL: "https://frigg.example.com",
+					Duration:    168 * time.Hour,
+				},
 			},
 			expectedError: "",
 		},
@@ -106,12 +184,16 @@ func TestNewConfig(t *testing.T) {
 					},
 					LowerThreshold: 10,
 					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -143,12 +225,16 @@ func TestNewConfig(t *testing.T) {
 					},
 					LowerThreshold: 10,
 					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -267,12 +353,16 @@ func TestNewConfig(t *testing.T) {
 					},
 					LowerThreshold: 10,
 					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -299,7 +389,13 @@ func TestNewConfig(t *testing.T) {
 			configPath:     "testdata/empty_skip_config.yaml",
 			expectedConfig: nil,
 			expectedError: "validating configuration: Key: 'Config.Prune.Skip.Tags' Error:" +
-				"Field validation for 'Tags' failed on the 'required' tag",
+				"Field validation for 'Tags' failed on the 'required_without' tag",
+		},
+		"empty include folders": {
+			configPath:     "testdata/empty_include_folders.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Include.Folders' Error:" +
+				"Field validation for 'Folders' failed on the 'min' tag",
 		},
 		"empty skip tags config": {
 			configPath:     "testdata/empty_skip_tags_config.yaml",
@@ -313,6 +409,118 @@ func TestNewConfig(t *testing.T) {
 			expectedError: "validating configuration: Key: 'Config.Prune.Skip.Tags.Any' Error:" +
 				"Field validation for 'Any' failed on the 'min' tag",
 		},
+		"negative min users": {
+			configPath:     "testdata/negative_min_users.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.MinUsers' Error:" +
+				"Field validation for 'MinUsers' failed on the 'min' tag",
+		},
+		"invalid ignored user pattern": {
+			configPath:     "testdata/invalid_ignored_user_pattern.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: validating ignored user patterns: compiling user pattern " +
+				"\"sa-(\": error parsing regexp: missing closing ): `sa-(`",
+		},
+		"invalid ignored role": {
+			configPath:     "testdata/invalid_ignored_role.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Ignore.Roles[0]' Error:" +
+				"Field validation for 'Roles[0]' failed on the 'oneof' tag",
+		},
+		"bot detection without window": {
+			configPath:     "testdata/bot_detection_without_window.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.BotDetection.Window' Error:" +
+				"Field validation for 'Window' failed on the 'required_with' tag",
+		},
+		"invalid notification sender": {
+			configPath:     "testdata/invalid_notify_email_from.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Notify.Email.From' Error:" +
+				"Field validation for 'From' failed on the 'email' tag",
+		},
+		"approval without namespaces": {
+			configPath:     "testdata/approval_without_namespaces.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Approval.Namespaces' Error:" +
+				"Field validation for 'Namespaces' failed on the 'min' tag",
+		},
+		"approval with deferred deletion": {
+			configPath:     "testdata/approval_with_defer_deletion.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: prune.approval cannot be combined with " +
+				"backup.github.pull_request.defer_deletion",
+		},
+		"approval without state path": {
+			configPath:     "testdata/approval_without_state_path.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: prune.approval requires state.path, as pending and approved runs " +
+				"are otherwise lost when Frigg restarts",
+		},
+		"approval with defaults": {
+			configPath: "testdata/approval_with_defaults.yaml",
+			expectedConfig: &frigg.Config{
+				Log: log.Config{
+					Level: slog.LevelInfo,
+				},
+				Server: server.Config{
+					Host: "localhost",
+					Port: 8080,
+				},
+				Loki: loki.Config{
+					Endpoint:   "http://loki.example.com",
+					QueryLimit: intPtr(100),
+				},
+				Grafana: grafana.Config{
+					Endpoint: "http://example.com",
+				},
+				Prune: grafana.PruneConfig{
+					Dry:            true,
+					Interval:       10 * time.Minute,
+					Period:         720 * time.Hour,
+					Labels:         map[string]string{"app": "grafana"},
+					LowerThreshold: 10,
+					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
+					Approval: &grafana.ApprovalConfig{
+						Namespaces: []string{"default"},
+						Expiry:     approvals.DefaultExpiry,
+					},
+				},
+				Backup: frigg.BackupConfig{
+					GitHub: github.Config{
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
+					},
+				},
+				State: state.Config{
+					Path: "/var/lib/frigg/state.json",
+				},
+			},
+			expectedError: "",
+		},
+		"approval expiry below minimum": {
+			configPath:     "testdata/approval_expiry_below_minimum.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Approval.Expiry' Error:" +
+				"Field validation for 'Expiry' failed on the 'min' tag",
+		},
+		"snooze duration below minimum": {
+			configPath:     "testdata/snooze_duration_below_minimum.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Snooze.Duration' Error:" +
+				"Field validation for 'Duration' failed on the 'min' tag",
+		},
+		"snooze without state path": {
+			configPath:     "testdata/snooze_without_state_path.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: snooze requires state.path, as snoozes are otherwise lost when " +
+				"Frigg restarts",
+		},
 		"zero max deletions": {
 			configPath:     "testdata/zero_max_deletions.yaml",
 			expectedConfig: nil,
@@ -325,6 +533,50 @@ func TestNewConfig(t *testing.T) {
 			expectedError: "validating configuration: Key: 'Config.Prune.MaxDeletions' Error:" +
 				"Field validation for 'MaxDeletions' failed on the 'min' tag",
 		},
+		"zero max deletion percentage": {
+			configPath:     "testdata/zero_max_deletion_percentage.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.MaxDeletionPercentage' Error:" +
+				"Field validation for 'MaxDeletionPercentage' failed on the 'gt' tag",
+		},
+		"max deletion percentage above 100": {
+			configPath:     "testdata/max_deletion_percentage_above_100.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.MaxDeletionPercentage' Error:" +
+				"Field validation for 'MaxDeletionPercentage' failed on the 'lte' tag",
+		},
+		"anomaly detection max drop too high": {
+			configPath:     "testdata/anomaly_detection_max_drop_too_high.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.AnomalyDetection.MaxDrop' Error:" +
+				"Field validation for 'MaxDrop' failed on the 'lt' tag",
+		},
+		"anomaly detection without state path": {
+			configPath:     "testdata/anomaly_detection_without_state_path.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: prune.anomaly_detection requires state.path, as the statistics of " +
+				"previous runs are otherwise lost when Frigg restarts",
+		},
+		"canary without name": {
+			configPath:     "testdata/canary_without_name.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Safety.Canaries[0].Name' Error:" +
+				"Field validation for 'Name' failed on the 'required' tag",
+		},
+		"invalid retention action": {
+			configPath:     "testdata/invalid_retention_action.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Safety.Retention.Action' Error:" +
+				"Field validation for 'Action' failed on the 'oneof' tag",
+		},
+		"invalid policy expression": {
+			configPath:     "testdata/invalid_policy_expression.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: validating prune policy: compiling skip expression: " +
+				"\"dashboard.owner == 'me'\": ERROR: <input>:1:10: undefined field 'owner'\n" +
+				" | dashboard.owner == 'me'\n" +
+				" | .........^",
+		},
 		"chunk size below minimum": {
 			configPath:     "testdata/chunk_size_below_minimum.yaml",
 			expectedConfig: nil,
@@ -355,12 +607,16 @@ func TestNewConfig(t *testing.T) {
 					Labels:         map[string]string{"app": "grafana"},
 					LowerThreshold: 10,
 					ChunkSize:      10 * time.Minute,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -390,17 +646,137 @@ func TestNewConfig(t *testing.T) {
 					Labels:         map[string]string{"app": "grafana"},
 					LowerThreshold: 10,
 					ChunkSize:      2 * time.Hour,
+					Action:         grafana.ActionDelete,
+				},
+				Backup: frigg.BackupConfig{
+					GitHub: github.Config{
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
+					},
+				},
+			},
+			expectedError: "",
+		},
+		"archive with defaults": {
+			configPath: "testdata/archive_with_defaults.yaml",
+			expectedConfig: &frigg.Config{
+				Log: log.Config{
+					Level: slog.LevelInfo,
+				},
+				Server: server.Config{
+					Host: "localhost",
+					Port: 8080,
+				},
+				Loki: loki.Config{
+					Endpoint:   "http://loki.example.com",
+					QueryLimit: intPtr(100),
+				},
+				Grafana: grafana.Config{
+					Endpoint: "http://example.com",
+				},
+				Prune: grafana.PruneConfig{
+					Dry:            true,
+					Interval:       10 * time.Minute,
+					Period:         720 * time.Hour,
+					Labels:         map[string]string{"app": "grafana"},
+					LowerThreshold: 10,
+					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionArchive,
+					Archive: &grafana.ArchiveConfig{
+						Folder: "frigg-archive",
+						Title:  "Frigg Archive",
+						Period: 2160 * time.Hour,
+					},
+				},
+				Backup: frigg.BackupConfig{
+					GitHub: github.Config{
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
+					},
+				},
+			},
+			expectedError: "",
+		},
+		"archive action without archive config": {
+			configPath:     "testdata/archive_without_archive_config.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Archive' Error:" +
+				"Field validation for 'Archive' failed on the 'required_if' tag",
+		},
+		"archive config with delete action": {
+			configPath:     "testdata/archive_config_with_delete_action.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Archive' Error:" +
+				"Field validation for 'Archive' failed on the 'excluded_unless' tag",
+		},
+		"archive with approval": {
+			configPath:     "testdata/archive_with_approval.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: prune.action archive cannot be combined with prune.approval or " +
+				"backup.github.pull_request.defer_deletion",
+		},
+		"invalid prune action": {
+			configPath:     "testdata/invalid_prune_action.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Action' Error:" +
+				"Field validation for 'Action' failed on the 'oneof' tag",
+		},
+		"tombstone": {
+			configPath: "testdata/tombstone.yaml",
+			expectedConfig: &frigg.Config{
+				Log: log.Config{
+					Level: slog.LevelInfo,
+				},
+				Server: server.Config{
+					Host: "localhost",
+					Port: 8080,
+				},
+				Loki: loki.Config{
+					Endpoint:   "http://loki.example.com",
+					QueryLimit: intPtr(100),
+				},
+				Grafana: grafana.Config{
+					Endpoint: "http://example.com",
+				},
+				Prune: grafana.PruneConfig{
+					Dry:            true,
+					Interval:       10 * time.Minute,
+					Period:         720 * time.Hour,
+					Labels:         map[string]string{"app": "grafana"},
+					LowerThreshold: 10,
+					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
+					Tombstone: &grafana.TombstoneConfig{
+						Period: 720 * time.Hour,
+					},
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
 			expectedError: "",
 		},
+		"tombstone period below minimum": {
+			configPath:     "testdata/tombstone_period_below_minimum.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Prune.Tombstone.Period' Error:" +
+				"Field validation for 'Period' failed on the 'min' tag",
+		},
 		"chunk size truncated to period when exceeding": {
 			configPath: "testdata/chunk_size_exceeds_period.yaml",
 			expectedConfig: &frigg.Config{
@@ -425,12 +801,16 @@ func TestNewConfig(t *testing.T) {
 					Labels:         map[string]string{"app": "grafana"},
 					LowerThreshold: 10,
 					ChunkSize:      time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -460,12 +840,16 @@ func TestNewConfig(t *testing.T) {
 					Labels:         map[string]string{"app": "grafana"},
 					LowerThreshold: 10,
 					ChunkSize:      time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -495,12 +879,16 @@ func TestNewConfig(t *testing.T) {
 					Labels:         map[string]string{"app": "grafana"},
 					LowerThreshold: 10,
 					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
 				},
 				Backup: frigg.BackupConfig{
 					GitHub: github.Config{
-						Repository: exampleRepository(t),
-						Branch:     "main",
-						Directory:  "deleted-dashboards",
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
 					},
 				},
 			},
@@ -512,6 +900,125 @@ func TestNewConfig(t *testing.T) {
 			expectedError: "validating configuration: Key: 'Config.Loki.QueryLimit' Error:" +
 				"Field validation for 'QueryLimit' failed on the 'min' tag",
 		},
+		"backup in pull request mode": {
+			configPath: "testdata/backup_pull_request_mode.yaml",
+			expectedConfig: &frigg.Config{
+				Log: log.Config{
+					Level: slog.LevelInfo,
+				},
+				Server: server.Config{
+					Host: "localhost",
+					Port: 8080,
+				},
+				Loki: loki.Config{
+					Endpoint:   "http://loki.example.com",
+					QueryLimit: intPtr(100),
+				},
+				Grafana: grafana.Config{
+					Endpoint: "http://example.com",
+				},
+				Prune: grafana.PruneConfig{
+					Dry:            true,
+					Interval:       10 * time.Minute,
+					Period:         720 * time.Hour,
+					Labels:         map[string]string{"app": "grafana"},
+					LowerThreshold: 10,
+					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
+				},
+				Backup: frigg.BackupConfig{
+					GitHub: github.Config{
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModePullRequest,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
+						PullRequest: &github.PullRequestConfig{
+							DeferDeletion: true,
+						},
+					},
+				},
+			},
+			expectedError: "",
+		},
+		"backup retention": {
+			configPath: "testdata/backup_retention.yaml",
+			expectedConfig: &frigg.Config{
+				Log: log.Config{
+					Level: slog.LevelInfo,
+				},
+				Server: server.Config{
+					Host: "localhost",
+					Port: 8080,
+				},
+				Loki: loki.Config{
+					Endpoint:   "http://loki.example.com",
+					QueryLimit: intPtr(100),
+				},
+				Grafana: grafana.Config{
+					Endpoint: "http://example.com",
+				},
+				Prune: grafana.PruneConfig{
+					Dry:            true,
+					Interval:       10 * time.Minute,
+					Period:         720 * time.Hour,
+					Labels:         map[string]string{"app": "grafana"},
+					LowerThreshold: 10,
+					ChunkSize:      4 * time.Hour,
+					Action:         grafana.ActionDelete,
+				},
+				Backup: frigg.BackupConfig{
+					GitHub: github.Config{
+						Repository:      exampleRepository(t),
+						Branch:          "main",
+						Directory:       "deleted-dashboards",
+						Mode:            github.ModeCommit,
+						PathTemplate:    github.DefaultPathTemplate,
+						MessageTemplate: github.DefaultMessageTemplate,
+					},
+					Retention: 8760 * time.Hour,
+				},
+			},
+			expectedError: "",
+		},
+		"retention below minimum": {
+			configPath:     "testdata/retention_below_minimum.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Backup.Retention' Error:" +
+				"Field validation for 'Retention' failed on the 'min' tag",
+		},
+		"pull request config in commit mode": {
+			configPath:     "testdata/pull_request_config_in_commit_mode.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Backup.GitHub.PullRequest' Error:" +
+				"Field validation for 'PullRequest' failed on the 'excluded_unless' tag",
+		},
+		"invalid backup mode": {
+			configPath:     "testdata/invalid_backup_mode.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: Key: 'Config.Backup.GitHub.Mode' Error:" +
+				"Field validation for 'Mode' failed on the 'oneof' tag",
+		},
+		"invalid path template": {
+			configPath:     "testdata/invalid_path_template.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: validating backup templates: parsing path template: " +
+				`template: path:1: unexpected "}" in operand`,
+		},
+		"unknown template field": {
+			configPath:     "testdata/unknown_template_field.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: validating backup templates: executing message template: " +
+				`template: message:1:11: executing "message" at <.Ticket>: can't evaluate field Ticket in type ` +
+				"github.TemplateData",
+		},
+		"path template escaping directory": {
+			configPath:     "testdata/escaping_path_template.yaml",
+			expectedConfig: nil,
+			expectedError: "validating configuration: validating backup templates: " +
+				`path template produced invalid path "../example.json"`,
+		},
 		"query limit negative": {
 			configPath:     "testdata/query_limit_negative.yaml",
 			expectedConfig: nil,
@@ -561,12 +1068,16 @@ func TestNewConfig(t *testing.T) {
 				},
 				LowerThreshold: 10,
 				ChunkSize:      4 * time.Hour,
+				Action:         grafana.ActionDelete,
 			},
 			Backup: frigg.BackupConfig{
 				GitHub: github.Config{
-					Repository: exampleRepository(t),
-					Branch:     "main",
-					Directory:  "deleted-dashboards",
+					Repository:      exampleRepository(t),
+					Branch:          "main",
+					Directory:       "deleted-dashboards",
+					Mode:            github.ModeCommit,
+					PathTemplate:    github.DefaultPathTemplate,
+					MessageTemplate: github.DefaultMessageTemplate,
 				},
 			},
 		}
@@ -601,6 +1112,44 @@ func TestNewSecrets(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		"valid notification secrets": {
+			secretsPath: "testdata/valid_notify_secrets.yaml",
+			expectedSecrets: &frigg.Secrets{
+				Grafana: grafana.Secrets{
+					Tokens: map[string]string{
+						"default": "example-valid-token",
+					},
+				},
+				Backup: frigg.BackupSecrets{
+					GitHub: github.Secrets{
+						Token: "ghp_exampletoken123",
+					},
+				},
+				Notify: notify.Secrets{
+					Slack: &notify.SlackSecrets{WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX"},
+					Email: &notify.EmailSecrets{Password: "smtp-password"},
+				},
+			},
+			expectedError: "",
+		},
+		"valid snooze secrets": {
+			secretsPath: "testdata/valid_snooze_secrets.yaml",
+			expectedSecrets: &frigg.Secrets{
+				Grafana: grafana.Secrets{
+					Tokens: map[string]string{
+						"default": "example-valid-token",
+					},
+				},
+				Backup: frigg.BackupSecrets{
+					GitHub: github.Secrets{
+						Token: "ghp_exampletoken123",
+					},
+				},
+				Server: server.Secrets{APIToken: "an-api-token-of-sufficient-length"},
+				Snooze: &snooze.Secrets{SigningKey: "a-signing-key-of-at-least-32-bytes"},
+			},
+			expectedError: "",
+		},
 		"missing secrets file": {
 			secretsPath:     "testdata/nonexistent_secrets.yaml",
 			expectedSecrets: nil,
@@ -653,6 +1202,45 @@ func TestNewSecrets(t *testing.T) {
 			expectedError: "validating secrets: Key: 'Secrets.Backup' Error:" +
 				"Field validation for 'Backup' failed on the 'required' tag",
 		},
+		"valid github app secrets": {
+			secretsPath: "testdata/valid_app_secrets.yaml",
+			expectedSecrets: &frigg.Secrets{
+				Grafana: grafana.Secrets{
+					Tokens: map[string]string{
+						"default": "example-valid-token",
+					},
+				},
+				Backup: frigg.BackupSecrets{
+					GitHub: github.Secrets{
+						App: &github.AppSecrets{
+							ID:             12345,
+							InstallationID: 67890,
+							PrivateKey:     readFile(t, "testdata/app_private_key.pem"),
+						},
+					},
+				},
+			},
+			expectedError: "",
+		},
+		"invalid github app private key in secrets": {
+			secretsPath:     "testdata/invalid_app_private_key_secrets.yaml",
+			expectedSecrets: nil,
+			expectedError:   "validating secrets: parsing GitHub App private key: no PEM block found",
+		},
+		"both github token and app in secrets": {
+			secretsPath:     "testdata/token_and_app_secrets.yaml",
+			expectedSecrets: nil,
+			expectedError: "validating secrets: Key: 'Secrets.Backup.GitHub.Token' Error:" +
+				"Field validation for 'Token' failed on the 'excluded_with' tag; " +
+				"Key: 'Secrets.Backup.GitHub.App' Error:" +
+				"Field validation for 'App' failed on the 'excluded_with' tag",
+		},
+		"missing github app private key in secrets": {
+			secretsPath:     "testdata/missing_app_private_key_secrets.yaml",
+			expectedSecrets: nil,
+			expectedError: "validating secrets: Key: 'Secrets.Backup.GitHub.App.PrivateKey' Error:" +
+				"Field validation for 'PrivateKey' failed on the 'required' tag",
+		},
 		"invalid extension for secrets": {
 			secretsPath:     "testdata/invalid_extension_secrets.xml",
 			expectedSecrets: nil,
@@ -721,6 +1309,56 @@ func TestNewSecrets(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateSecrets(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		configPath    string
+		secretsPath   string
+		expectedError string
+	}{
+		"snooze with signing key": {
+			configPath:    "testdata/valid_config.yaml",
+			secretsPath:   "testdata/valid_snooze_secrets.yaml",
+			expectedError: "",
+		},
+		"snooze without signing key": {
+			configPath:    "testdata/valid_config.yaml",
+			secretsPath:   "testdata/valid_secrets.yaml",
+			expectedError: "snooze requires secrets.snooze, as snooze links are signed with its signing key",
+		},
+		"approval with API token": {
+			configPath:    "testdata/approval_with_defaults.yaml",
+			secretsPath:   "testdata/valid_snooze_secrets.yaml",
+			expectedError: "",
+		},
+		"approval without API token": {
+			configPath:  "testdata/approval_with_defaults.yaml",
+			secretsPath: "testdata/valid_secrets.yaml",
+			expectedError: "prune.approval requires secrets.server.api_token, as approval runs are reviewed through " +
+				"the API",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := frigg.NewConfig(tt.configPath)
+			require.NoError(t, err)
+			secrets, err := frigg.NewSecrets(tt.secretsPath)
+			require.NoError(t, err)
+
+			err = cfg.ValidateSecrets(secrets)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func exampleRepository(t testing.TB) github.Repository {
 	t.Helper()
 	r, err := github.NewRepository("octocat", "hello-world")
@@ -728,6 +1366,17 @@ func exampleRepository(t testing.TB) github.Repository {
 	return *r
 }
 
+func readFile(t testing.TB, path string) string {
+	t.Helper()
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(buf)
+}
+
 func intPtr(i int) *int {
 	return &i
 }
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
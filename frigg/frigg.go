@@ -21,14 +21,23 @@ type Frigg struct {
 	server   *server.Server
 	gatherer prometheus.Gatherer
 	pruners  []dashboardPruner
+	// routes are registered in addition to the routes that Frigg always serves.
+	routes []server.Route
 }
 
-func New(logger *slog.Logger, s *server.Server, gatherer prometheus.Gatherer, pruners []dashboardPruner) *Frigg {
+func New(
+	logger *slog.Logger,
+	s *server.Server,
+	gatherer prometheus.Gatherer,
+	pruners []dashboardPruner,
+	routes []server.Route,
+) *Frigg {
 	return &Frigg{
 		logger:   logger,
 		server:   s,
 		gatherer: gatherer,
 		pruners:  pruners,
+		routes:   routes,
 	}
 }
 
@@ -72,4 +81,8 @@ func (f *Frigg) registerRoutes() {
 		Methods: []string{"GET"},
 		Func:    promhttp.HandlerFor(f.gatherer, promhttp.HandlerOpts{}).ServeHTTP,
 	})
+
+	for _, route := range f.routes {
+		f.server.RegisterRoute(route)
+	}
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/LasseHels/frigg/approvals"
+)
+
+type runRegistry interface {
+	List() ([]approvals.Run, error)
+	Get(id string) (*approvals.Run, error)
+	Approve(id string, exclude []string, now time.Time) (*approvals.Run, error)
+	Reject(id string, now time.Time) (*approvals.Run, error)
+}
+
+// approveRequest is the optional body of a request to ApproveRun.
+type approveRequest struct {
+	// Exclude are the names of candidates that must not be deleted.
+	Exclude []string `json:"exclude"`
+}
+
+// Runs lists the approval runs as JSON.
+func Runs(registry runRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		runs, err := registry.List()
+		if err != nil {
+			l.Error("Failed to list approval runs", slog.String("error", err.Error()))
+			http.Error(w, "Failed to list runs.", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, l, runs)
+	}
+}
+
+// Run returns the approval run that is identified by the id route variable as JSON.
+func Run(registry runRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		run, err := registry.Get(mux.Vars(r)["id"])
+		if err != nil {
+			writeRunError(w, l, err)
+			return
+		}
+
+		writeJSON(w, l, run)
+	}
+}
+
+// ApproveRun approves the deletion of the candidates of the pending approval run that is identified by the id route
+// variable. The body of the request may exclude candidates from deletion, e.g., {"exclude": ["dashboard-name"]}.
+func ApproveRun(registry runRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body approveRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "The request body is not valid JSON.", http.StatusBadRequest)
+			return
+		}
+
+		run, err := registry.Approve(mux.Vars(r)["id"], body.Exclude, time.Now().UTC())
+		if err != nil {
+			writeRunError(w, l, err)
+			return
+		}
+
+		l.Info(
+			"Approved deletion run",
+			slog.String("run", run.ID),
+			slog.Int("candidate_count", len(run.Candidates)),
+			slog.Any("excluded", run.Excluded),
+		)
+		writeJSON(w, l, run)
+	}
+}
+
+// RejectRun rejects the deletion of the candidates of the pending approval run that is identified by the id route
+// variable.
+func RejectRun(registry runRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		run, err := registry.Reject(mux.Vars(r)["id"], time.Now().UTC())
+		if err != nil {
+			writeRunError(w, l, err)
+			return
+		}
+
+		l.Info("Rejected deletion run", slog.String("run", run.ID))
+		writeJSON(w, l, run)
+	}
+}
+
+// writeRunError responds with the status code that matches err.
+func writeRunError(w http.ResponseWriter, l *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, approvals.ErrNotFound):
+		http.Error(w, "The run does not exist.", http.StatusNotFound)
+	case errors.Is(err, approvals.ErrNotPending):
+		http.Error(w, "The run is not pending.", http.StatusConflict)
+	case errors.Is(err, approvals.ErrUnknownCandidate):
+		http.Error(w, err.Error()+".", http.StatusBadRequest)
+	default:
+		l.Error("Failed to access approval run", slog.String("error", err.Error()))
+		http.Error(w, "Failed to access run.", http.StatusInternalServerError)
+	}
+}
+
+// writeJSON writes v as JSON.
+func writeJSON(w http.ResponseWriter, l *slog.Logger, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		l.Error("Failed to write JSON response", slog.String("error", err.Error()))
+	}
+}
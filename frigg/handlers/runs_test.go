@@ -0,0 +1,199 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/approvals"
+	"github.com/LasseHels/frigg/frigg/handlers"
+	"github.com/LasseHels/frigg/state"
+)
+
+// runRouter returns a router that serves the approval run routes along with the registry that records their runs. The
+// registry holds a single pending run with the ID default-1.
+func runRouter(t *testing.T) (*mux.Router, *approvals.Registry) {
+	t.Helper()
+
+	store, err := state.NewStore("")
+	require.NoError(t, err)
+	registry := approvals.NewRegistry(store, approvals.DefaultExpiry)
+	require.NoError(t, registry.Propose(&approvals.Run{
+		ID:        "default-1",
+		Namespace: "default",
+		Created:   time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Candidates: []approvals.Candidate{
+			{Name: "latency", UID: "uid1", Title: "Latency", Hash: "hash1"},
+			{Name: "errors", UID: "uid2", Title: "Errors", Hash: "hash2"},
+		},
+	}))
+
+	l, _ := logger()
+	router := mux.NewRouter()
+	router.HandleFunc(
+		"/api/v1/runs",
+		handlers.Authenticated(apiToken, handlers.Runs(registry, l)),
+	).Methods(http.MethodGet)
+	router.HandleFunc(
+		"/api/v1/runs/{id}",
+		handlers.Authenticated(apiToken, handlers.Run(registry, l)),
+	).Methods(http.MethodGet)
+	router.HandleFunc(
+		"/api/v1/runs/{id}/approve",
+		handlers.Authenticated(apiToken, handlers.ApproveRun(registry, l)),
+	).Methods(http.MethodPost)
+	router.HandleFunc(
+		"/api/v1/runs/{id}/reject",
+		handlers.Authenticated(apiToken, handlers.RejectRun(registry, l)),
+	).Methods(http.MethodPost)
+
+	return router, registry
+}
+
+func serveRun(router *mux.Router, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+apiToken)
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestRuns(t *testing.T) {
+	t.Parallel()
+
+	router, _ := runRouter(t)
+
+	t.Run("requires API token", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/runs", http.NoBody))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("lists runs", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := serveRun(router, http.MethodGet, "/api/v1/runs", http.NoBody)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		//nolint:lll
+		assert.JSONEq(
+			t,
+			`[{"id":"default-1","namespace":"default","status":"pending","created":"2026-10-01T00:00:00Z","candidates":[{"name":"latency","uid":"uid1","title":"Latency","hash":"hash1"},{"name":"errors","uid":"uid2","title":"Errors","hash":"hash2"}]}]`,
+			recorder.Body.String(),
+		)
+	})
+
+	t.Run("returns run", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := serveRun(router, http.MethodGet, "/api/v1/runs/default-1", http.NoBody)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"id":"default-1"`)
+	})
+
+	t.Run("returns not found for unknown run", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := serveRun(router, http.MethodGet, "/api/v1/runs/default-2", http.NoBody)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, "The run does not exist.\n", recorder.Body.String())
+	})
+}
+
+func TestApproveRun(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body              io.Reader
+		expectedStatus    int
+		expectedBody      string
+		expectedRunStatus approvals.Status
+		expectedExcluded  []string
+	}{
+		"approves run without body": {
+			body:              http.NoBody,
+			expectedStatus:    http.StatusOK,
+			expectedRunStatus: approvals.StatusApproved,
+		},
+		"approves run with exclusions": {
+			body:              strings.NewReader(`{"exclude":["errors"]}`),
+			expectedStatus:    http.StatusOK,
+			expectedRunStatus: approvals.StatusApproved,
+			expectedExcluded:  []string{"errors"},
+		},
+		"rejects invalid JSON": {
+			body:              strings.NewReader(`{"exclude":`),
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      "The request body is not valid JSON.\n",
+			expectedRunStatus: approvals.StatusPending,
+		},
+		"rejects exclusion of unknown candidate": {
+			body:              strings.NewReader(`{"exclude":["hosts"]}`),
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      "excluding \"hosts\": dashboard is not a candidate of the run.\n",
+			expectedRunStatus: approvals.StatusPending,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			router, registry := runRouter(t)
+			recorder := serveRun(router, http.MethodPost, "/api/v1/runs/default-1/approve", test.body)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+
+			run, err := registry.Get("default-1")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRunStatus, run.Status)
+			assert.Equal(t, test.expectedExcluded, run.Excluded)
+		})
+	}
+
+	t.Run("rejects approval of run that is not pending", func(t *testing.T) {
+		t.Parallel()
+
+		router, _ := runRouter(t)
+		assert.Equal(t, http.StatusOK, serveRun(router, http.MethodPost, "/api/v1/runs/default-1/approve", http.NoBody).Code)
+
+		recorder := serveRun(router, http.MethodPost, "/api/v1/runs/default-1/approve", http.NoBody)
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, "The run is not pending.\n", recorder.Body.String())
+	})
+}
+
+func TestRejectRun(t *testing.T) {
+	t.Parallel()
+
+	router, registry := runRouter(t)
+
+	recorder := serveRun(router, http.MethodPost, "/api/v1/runs/default-1/reject", http.NoBody)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	run, err := registry.Get("default-1")
+	require.NoError(t, err)
+	assert.Equal(t, approvals.StatusRejected, run.Status)
+
+	recorder = serveRun(router, http.MethodPost, "/api/v1/runs/default-1/reject", http.NoBody)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = serveRun(router, http.MethodPost, "/api/v1/runs/default-2/reject", http.NoBody)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
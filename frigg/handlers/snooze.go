@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/LasseHels/frigg/snooze"
+)
+
+type snoozeRegistry interface {
+	Add(snooze *snooze.Snooze, now time.Time) error
+	List(now time.Time) ([]snooze.Snooze, error)
+	Revoke(namespace, name string) (bool, error)
+}
+
+type snoozeVerifier interface {
+	Verify(namespace, name string, until time.Time, signature string) bool
+}
+
+// snoozeConfirmation is the page that asks the owner of a dashboard to confirm its snooze. The form posts the
+// parameters of the snooze link back to the link.
+var snoozeConfirmation = template.Must(template.New("snooze").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Snooze dashboard {{.Namespace}}/{{.Name}}</title>
+</head>
+<body>
+<p>Snooze dashboard {{.Namespace}}/{{.Name}}? Frigg will not delete the dashboard before {{.Until}}.</p>
+<form method="post">
+<input type="hidden" name="ns" value="{{.Namespace}}">
+<input type="hidden" name="name" value="{{.Name}}">
+<input type="hidden" name="until" value="{{.Until}}">
+<input type="hidden" name="sig" value="{{.Signature}}">
+<button type="submit">Snooze</button>
+</form>
+</body>
+</html>
+`))
+
+// snoozeLink holds the parameters of a signed snooze link. Until is formatted as RFC 3339.
+type snoozeLink struct {
+	Namespace string
+	Name      string
+	Until     string
+	Signature string
+}
+
+// Snooze records the snooze of a signed snooze link. The link carries the namespace and name of the dashboard, the time
+// until which the dashboard is snoozed and the signature of the three in the parameters ns, name, until and sig.
+//
+// Opening the link sends a GET request, which only renders a page that asks for confirmation. Mail scanners and link
+// previews open links without the user's consent, so the snooze itself is only recorded when the page's form sends a
+// POST request with the same parameters.
+func Snooze(registry snoozeRegistry, verifier snoozeVerifier, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace, name, signature := r.FormValue("ns"), r.FormValue("name"), r.FormValue("sig")
+		if namespace == "" || name == "" || signature == "" {
+			http.Error(w, "The snooze link is incomplete.", http.StatusBadRequest)
+			return
+		}
+
+		until, err := time.Parse(time.RFC3339, r.FormValue("until"))
+		if err != nil {
+			http.Error(w, "The snooze link is incomplete.", http.StatusBadRequest)
+			return
+		}
+
+		if !verifier.Verify(namespace, name, until, signature) {
+			http.Error(w, "The snooze link is invalid.", http.StatusForbidden)
+			return
+		}
+
+		now := time.Now().UTC()
+		if !until.After(now) {
+			http.Error(w, "The snooze link has expired.", http.StatusGone)
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			link := snoozeLink{
+				Namespace: namespace,
+				Name:      name,
+				Until:     until.UTC().Format(time.RFC3339),
+				Signature: signature,
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := snoozeConfirmation.Execute(w, link); err != nil {
+				l.Error("Failed to write snooze confirmation", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		s := &snooze.Snooze{Namespace: namespace, Name: name, Until: until, Created: now}
+		if err := registry.Add(s, now); err != nil {
+			l.Error("Failed to snooze dashboard", slog.String("error", err.Error()))
+			http.Error(w, "The dashboard could not be snoozed, please try again later.", http.StatusInternalServerError)
+			return
+		}
+
+		l.Info(
+			"Snoozed dashboard",
+			slog.String("namespace", namespace),
+			slog.String("name", name),
+			slog.String("until", until.UTC().Format(time.RFC3339)),
+		)
+
+		message := fmt.Sprintf(
+			"Frigg will not delete dashboard %s/%s before %s.",
+			namespace,
+			name,
+			until.UTC().Format(time.RFC3339),
+		)
+		if _, err := w.Write([]byte(message)); err != nil {
+			l.Error("Failed to write snooze response", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Snoozes lists the snoozes that have not expired as JSON.
+func Snoozes(registry snoozeRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		snoozes, err := registry.List(time.Now().UTC())
+		if err != nil {
+			l.Error("Failed to list snoozes", slog.String("error", err.Error()))
+			http.Error(w, "Failed to list snoozes.", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, l, snoozes)
+	}
+}
+
+// RevokeSnooze revokes the snooze of the dashboard that is identified by the namespace and name route variables.
+func RevokeSnooze(registry snoozeRegistry, l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		namespace, name := vars["namespace"], vars["name"]
+
+		revoked, err := registry.Revoke(namespace, name)
+		if err != nil {
+			l.Error("Failed to revoke snooze", slog.String("error", err.Error()))
+			http.Error(w, "Failed to revoke snooze.", http.StatusInternalServerError)
+			return
+		}
+
+		if !revoked {
+			http.Error(w, "The dashboard is not snoozed.", http.StatusNotFound)
+			return
+		}
+
+		l.Info("Revoked snooze of dashboard", slog.String("namespace", namespace), slog.String("name", name))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,248 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/frigg/handlers"
+	"github.com/LasseHels/frigg/snooze"
+	"github.com/LasseHels/frigg/state"
+)
+
+const apiToken = "an-api-token-of-sufficient-length"
+
+// snoozeRouter returns a router that serves the snooze routes along with the registry that records their snoozes.
+func snoozeRouter(t *testing.T, signer *snooze.Signer) (*mux.Router, *snooze.Registry) {
+	t.Helper()
+
+	store, err := state.NewStore("")
+	require.NoError(t, err)
+	registry := snooze.NewRegistry(store)
+
+	l, _ := logger()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/snooze", handlers.Snooze(registry, signer, l)).Methods(
+		http.MethodGet,
+		http.MethodPost,
+	)
+	router.HandleFunc(
+		"/api/v1/snoozes",
+		handlers.Authenticated(apiToken, handlers.Snoozes(registry, l)),
+	).Methods(http.MethodGet)
+	router.HandleFunc(
+		"/api/v1/snoozes/{namespace}/{name}",
+		handlers.Authenticated(apiToken, handlers.RevokeSnooze(registry, l)),
+	).Methods(http.MethodDelete)
+
+	return router, registry
+}
+
+func snoozeQuery(namespace, name string, until time.Time, signature string) string {
+	q := url.Values{}
+	q.Set("ns", namespace)
+	q.Set("name", name)
+	q.Set("until", until.Format(time.RFC3339))
+	q.Set("sig", signature)
+
+	return "/api/v1/snooze?" + q.Encode()
+}
+
+func TestSnooze(t *testing.T) {
+	t.Parallel()
+
+	signer := snooze.NewSigner("a-signing-key-of-at-least-32-bytes")
+	until := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+	expired := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	tests := map[string]struct {
+		method         string
+		target         string
+		expectedStatus int
+		expectedBody   string
+		expectedCount  int
+	}{
+		"asks for confirmation": {
+			method:         http.MethodGet,
+			target:         snoozeQuery("default", "latency", until, signer.Sign("default", "latency", until)),
+			expectedStatus: http.StatusOK,
+			expectedBody: `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Snooze dashboard default/latency</title>
+</head>
+<body>
+<p>Snooze dashboard default/latency? Frigg will not delete the dashboard before ` + until.Format(time.RFC3339) + `.</p>
+<form method="post">
+<input type="hidden" name="ns" value="default">
+<input type="hidden" name="name" value="latency">
+<input type="hidden" name="until" value="` + until.Format(time.RFC3339) + `">
+<input type="hidden" name="sig" value="` + signer.Sign("default", "latency", until) + `">
+<button type="submit">Snooze</button>
+</form>
+</body>
+</html>
+`,
+			expectedCount: 0,
+		},
+		"snoozes dashboard": {
+			method:         http.MethodPost,
+			target:         snoozeQuery("default", "latency", until, signer.Sign("default", "latency", until)),
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Frigg will not delete dashboard default/latency before " + until.Format(time.RFC3339) + ".",
+			expectedCount:  1,
+		},
+		"rejects forged link": {
+			method:         http.MethodPost,
+			target:         snoozeQuery("default", "errors", until, signer.Sign("default", "latency", until)),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "The snooze link is invalid.\n",
+			expectedCount:  0,
+		},
+		"rejects forged link without confirmation": {
+			method:         http.MethodGet,
+			target:         snoozeQuery("default", "errors", until, signer.Sign("default", "latency", until)),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "The snooze link is invalid.\n",
+			expectedCount:  0,
+		},
+		"rejects expired link": {
+			method:         http.MethodPost,
+			target:         snoozeQuery("default", "latency", expired, signer.Sign("default", "latency", expired)),
+			expectedStatus: http.StatusGone,
+			expectedBody:   "The snooze link has expired.\n",
+			expectedCount:  0,
+		},
+		"rejects incomplete link": {
+			method:         http.MethodPost,
+			target:         "/api/v1/snooze?ns=default&name=latency",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "The snooze link is incomplete.\n",
+			expectedCount:  0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			router, registry := snoozeRouter(t, signer)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, http.NoBody))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedBody, recorder.Body.String())
+
+			snoozes, err := registry.List(time.Now())
+			require.NoError(t, err)
+			assert.Len(t, snoozes, tt.expectedCount)
+		})
+	}
+
+	t.Run("snoozes dashboard with parameters of confirmation form", func(t *testing.T) {
+		t.Parallel()
+
+		form := url.Values{}
+		form.Set("ns", "default")
+		form.Set("name", "latency")
+		form.Set("until", until.Format(time.RFC3339))
+		form.Set("sig", signer.Sign("default", "latency", until))
+
+		router, registry := snoozeRouter(t, signer)
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/snooze", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		snoozes, err := registry.List(time.Now())
+		require.NoError(t, err)
+		require.Len(t, snoozes, 1)
+		assert.Equal(t, "latency", snoozes[0].Name)
+		assert.Equal(t, until, snoozes[0].Until)
+	})
+}
+
+func TestSnoozes(t *testing.T) {
+	t.Parallel()
+
+	until := time.Date(2099, time.January, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	router, registry := snoozeRouter(t, snooze.NewSigner("a-signing-key-of-at-least-32-bytes"))
+	require.NoError(t, registry.Add(&snooze.Snooze{
+		Namespace: "default",
+		Name:      "latency",
+		Until:     until,
+		Created:   created,
+	}, created))
+
+	t.Run("requires API token", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/snoozes", http.NoBody)
+		req.Header.Set("Authorization", "Bearer wrong-token")
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("lists snoozes", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/snoozes", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+apiToken)
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(
+			t,
+			`[{"namespace":"default","name":"latency","until":"2099-01-01T00:00:00Z","created":"2026-10-01T00:00:00Z"}]`,
+			recorder.Body.String(),
+		)
+	})
+}
+
+func TestRevokeSnooze(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	router, registry := snoozeRouter(t, snooze.NewSigner("a-signing-key-of-at-least-32-bytes"))
+	require.NoError(t, registry.Add(&snooze.Snooze{
+		Namespace: "default",
+		Name:      "latency",
+		Until:     now.Add(time.Hour),
+		Created:   now,
+	}, now))
+
+	revoke := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/snoozes/default/latency", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+apiToken)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusNoContent, revoke().Code)
+
+	snoozes, err := registry.List(now)
+	require.NoError(t, err)
+	assert.Empty(t, snoozes)
+
+	recorder := revoke()
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "The dashboard is not snoozed.\n", recorder.Body.String())
+}
@@ -0,0 +1,253 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// tokenRefreshMargin is how long before its expiry an installation token is replaced by a new one. The margin
+	// ensures that a token does not expire while a request that uses it is in flight.
+	tokenRefreshMargin = 5 * time.Minute
+	// jwtLifetime is the lifetime of the JSON Web Tokens that authenticate as the GitHub App. GitHub rejects JSON Web
+	// Tokens that are valid for more than ten minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockDrift is subtracted from the issue time of JSON Web Tokens to allow for clock drift between Frigg and
+	// GitHub.
+	jwtClockDrift = time.Minute
+)
+
+// AppSecrets holds the credentials of a GitHub App installation.
+type AppSecrets struct {
+	// ID is the GitHub App's ID.
+	ID int64 `yaml:"id" json:"id" validate:"required"`
+	// InstallationID is the ID of the GitHub App's installation in the account that owns the backup repository.
+	InstallationID int64 `yaml:"installation_id" json:"installation_id" validate:"required"`
+	// PrivateKey is the PEM-encoded RSA private key of the GitHub App.
+	PrivateKey string `yaml:"private_key" json:"private_key" validate:"required"`
+}
+
+// Validate returns an error if the private key cannot be parsed.
+func (s *AppSecrets) Validate() error {
+	if _, err := parsePrivateKey([]byte(s.PrivateKey)); err != nil {
+		return errors.Wrap(err, "parsing GitHub App private key")
+	}
+
+	return nil
+}
+
+// AppTransport is an http.RoundTripper that authenticates requests as a GitHub App installation.
+//
+// AppTransport mints an installation token the first time it is used and mints a new installation token shortly
+// before the current one expires.
+type AppTransport struct {
+	base           http.RoundTripper
+	apps           *github.AppsService
+	installationID int64
+	logger         *slog.Logger
+
+	// refresh ensures that concurrent requests share a single minting of an installation token.
+	refresh singleflight.Group
+
+	// mu guards token and expiresAt.
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// AppTransportOptions contains options for creating an AppTransport.
+type AppTransportOptions struct {
+	// Base performs the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+	// APIURL is the URL of a GitHub Enterprise Server API. Defaults to the public GitHub API.
+	APIURL  string
+	Secrets *AppSecrets
+	Logger  *slog.Logger
+}
+
+// NewAppTransport creates a new AppTransport. NewAppTransport returns an error if the private key cannot be parsed.
+func NewAppTransport(opts *AppTransportOptions) (*AppTransport, error) {
+	key, err := parsePrivateKey([]byte(opts.Secrets.PrivateKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing GitHub App private key")
+	}
+
+	base := opts.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	client := github.NewClient(&http.Client{Transport: &jwtTransport{
+		base:  base,
+		appID: opts.Secrets.ID,
+		key:   key,
+	}})
+	if opts.APIURL != "" {
+		client, err = client.WithEnterpriseURLs(opts.APIURL, opts.APIURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "setting GitHub API URL")
+		}
+	}
+
+	return &AppTransport{
+		base:           base,
+		apps:           client.Apps,
+		installationID: opts.Secrets.InstallationID,
+		logger:         opts.Logger.With(slog.Int64("installation_id", opts.Secrets.InstallationID)),
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *AppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
+
+// installationToken returns an installation token that is valid for at least tokenRefreshMargin.
+//
+// Requests that need a new installation token wait for a single minting instead of each minting their own, and no
+// lock is held while the token is minted, so requests with a valid token are never blocked by a slow GitHub response.
+func (t *AppTransport) installationToken(req *http.Request) (string, error) {
+	if token, ok := t.validToken(); ok {
+		return token, nil
+	}
+
+	v, err, _ := t.refresh.Do("installation-token", func() (any, error) {
+		// Another request may have minted a token while this one waited to enter Do.
+		if token, ok := t.validToken(); ok {
+			return token, nil
+		}
+
+		token, _, err := t.apps.CreateInstallationToken(req.Context(), t.installationID, nil)
+		if err != nil {
+			return "", errors.Wrap(err, "creating GitHub App installation token")
+		}
+
+		expiresAt := token.GetExpiresAt().Time
+
+		t.mu.Lock()
+		t.token = token.GetToken()
+		t.expiresAt = expiresAt
+		t.mu.Unlock()
+
+		t.logger.Debug("Created GitHub App installation token", slog.Time("expires_at", expiresAt))
+
+		return token.GetToken(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("expected installation token to be a string but got %T", v)
+	}
+
+	return token, nil
+}
+
+// validToken returns the current installation token and whether it is valid for at least tokenRefreshMargin.
+func (t *AppTransport) validToken() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.token, t.token != "" && time.Until(t.expiresAt) > tokenRefreshMargin
+}
+
+// jwtTransport is an http.RoundTripper that authenticates requests as a GitHub App using a JSON Web Token. GitHub only
+// accepts JSON Web Tokens for a few endpoints, such as the endpoint that creates installation tokens.
+//
+// See [documentation].
+//
+// [documentation]: https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+type jwtTransport struct {
+	base  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+func (t *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.jwt(time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "signing JSON Web Token")
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
+
+// jwt returns a JSON Web Token signed with RS256 that identifies the GitHub App.
+func (t *jwtTransport) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-jwtClockDrift).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": t.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM-encoded RSA private key in either PKCS #1 or PKCS #8 form. GitHub issues GitHub App
+// private keys in PKCS #1 form.
+func parsePrivateKey(buf []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if pkcs1Key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return pkcs1Key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing private key")
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("expected RSA private key but got %T", key)
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,325 @@
+package github_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/github"
+)
+
+func TestAppTransport(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		tokenLifetime    time.Duration
+		expectedTokens   []string
+		expectedMintings int
+	}{
+		"reuses installation token until it is about to expire": {
+			tokenLifetime:    time.Hour,
+			expectedTokens:   []string{"Bearer ghs_token1", "Bearer ghs_token1"},
+			expectedMintings: 1,
+		},
+		"refreshes installation token that is about to expire": {
+			tokenLifetime:    time.Minute,
+			expectedTokens:   []string{"Bearer ghs_token1", "Bearer ghs_token2"},
+			expectedMintings: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			mintings := 0
+			var tokens []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /api/v3/app/installations/67890/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+				assertJWT(t, &key.PublicKey, r.Header.Get("Authorization"))
+
+				mu.Lock()
+				mintings++
+				token := fmt.Sprintf("ghs_token%d", mintings)
+				mu.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				expiresAt := time.Now().Add(tt.tokenLifetime).UTC().Format(time.RFC3339)
+				writeResponse(t, w, fmt.Appendf(nil, `{"token":%q,"expires_at":%q}`, token, expiresAt))
+			})
+			mux.HandleFunc("GET /api/v3/repos/octocat/hello-world", func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				tokens = append(tokens, r.Header.Get("Authorization"))
+				mu.Unlock()
+
+				w.WriteHeader(http.StatusOK)
+				writeResponse(t, w, []byte(`{}`))
+			})
+
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			logger, _ := testLogger()
+			transport, err := github.NewAppTransport(&github.AppTransportOptions{
+				APIURL: server.URL + "/api/v3/",
+				Secrets: &github.AppSecrets{
+					ID:             12345,
+					InstallationID: 67890,
+					PrivateKey:     string(pkcs1PEM(key)),
+				},
+				Logger: logger,
+			})
+			require.NoError(t, err)
+
+			client := &http.Client{Transport: transport}
+			for range 2 {
+				req, err := http.NewRequestWithContext(
+					t.Context(),
+					http.MethodGet,
+					server.URL+"/api/v3/repos/octocat/hello-world",
+					http.NoBody,
+				)
+				require.NoError(t, err)
+
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+
+			assert.Equal(t, tt.expectedTokens, tokens)
+			assert.Equal(t, tt.expectedMintings, mintings)
+		})
+	}
+
+	t.Run("returns error when installation token cannot be created", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeResponse(t, w, []byte(`{"message":"A JSON web token could not be decoded"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		logger, _ := testLogger()
+		transport, err := github.NewAppTransport(&github.AppTransportOptions{
+			APIURL: server.URL + "/api/v3/",
+			Secrets: &github.AppSecrets{
+				ID:             12345,
+				InstallationID: 67890,
+				PrivateKey:     string(pkcs1PEM(key)),
+			},
+			Logger: logger,
+		})
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, http.NoBody)
+		require.NoError(t, err)
+
+		_, err = transport.RoundTrip(req)
+		require.ErrorContains(t, err, "creating GitHub App installation token: ")
+		require.ErrorContains(t, err, "401 A JSON web token could not be decoded")
+	})
+
+	t.Run("mints a single installation token for concurrent requests", func(t *testing.T) {
+		t.Parallel()
+
+		var mintings atomic.Int32
+		release := make(chan struct{})
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/v3/app/installations/67890/access_tokens", func(w http.ResponseWriter, _ *http.Request) {
+			mintings.Add(1)
+			<-release
+
+			w.WriteHeader(http.StatusCreated)
+			expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			writeResponse(t, w, fmt.Appendf(nil, `{"token":"ghs_token","expires_at":%q}`, expiresAt))
+		})
+		mux.HandleFunc("GET /api/v3/repos/octocat/hello-world", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer ghs_token", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusOK)
+			writeResponse(t, w, []byte(`{}`))
+		})
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		logger, _ := testLogger()
+		transport, err := github.NewAppTransport(&github.AppTransportOptions{
+			APIURL: server.URL + "/api/v3/",
+			Secrets: &github.AppSecrets{
+				ID:             12345,
+				InstallationID: 67890,
+				PrivateKey:     string(pkcs1PEM(key)),
+			},
+			Logger: logger,
+		})
+		require.NoError(t, err)
+
+		client := &http.Client{Transport: transport}
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				req, err := http.NewRequestWithContext(
+					t.Context(),
+					http.MethodGet,
+					server.URL+"/api/v3/repos/octocat/hello-world",
+					http.NoBody,
+				)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				resp, err := client.Do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, resp.Body.Close())
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}()
+		}
+
+		// Give the requests time to queue up behind the minting before it completes.
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), mintings.Load())
+	})
+
+	t.Run("accepts PKCS #8 private key", func(t *testing.T) {
+		t.Parallel()
+
+		buf, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+
+		logger, _ := testLogger()
+		_, err = github.NewAppTransport(&github.AppTransportOptions{
+			Secrets: &github.AppSecrets{
+				ID:             12345,
+				InstallationID: 67890,
+				PrivateKey:     string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: buf})),
+			},
+			Logger: logger,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error for invalid private key", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := testLogger()
+		_, err := github.NewAppTransport(&github.AppTransportOptions{
+			Secrets: &github.AppSecrets{
+				ID:             12345,
+				InstallationID: 67890,
+				PrivateKey:     "not a private key",
+			},
+			Logger: logger,
+		})
+		require.EqualError(t, err, "parsing GitHub App private key: no PEM block found")
+	})
+}
+
+func TestAppSecrets_Validate(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		privateKey    string
+		expectedError string
+	}{
+		"valid private key": {
+			privateKey:    string(pkcs1PEM(key)),
+			expectedError: "",
+		},
+		"missing PEM block": {
+			privateKey:    "not a private key",
+			expectedError: "parsing GitHub App private key: no PEM block found",
+		},
+		"malformed private key": {
+			privateKey:    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("example")})),
+			expectedError: "parsing GitHub App private key: parsing private key: ",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			secrets := &github.AppSecrets{ID: 12345, InstallationID: 67890, PrivateKey: tt.privateKey}
+			err := secrets.Validate()
+
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func pkcs1PEM(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// assertJWT asserts that authorization holds a JSON Web Token that is signed by the private key of publicKey and that
+// identifies the GitHub App with ID 12345.
+func assertJWT(t *testing.T, publicKey *rsa.PublicKey, authorization string) {
+	t.Helper()
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	require.True(t, ok)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature))
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg":"RS256","typ":"JWT"}`, string(header))
+
+	buf, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims struct {
+		IssuedAt  int64 `json:"iat"`
+		ExpiresAt int64 `json:"exp"`
+		Issuer    int64 `json:"iss"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &claims))
+	assert.Equal(t, int64(12345), claims.Issuer)
+	assert.Equal(t, int64(10*time.Minute/time.Second), claims.ExpiresAt-claims.IssuedAt)
+	assert.WithinDuration(t, time.Now(), time.Unix(claims.IssuedAt, 0), 2*time.Minute)
+}
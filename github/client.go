@@ -2,21 +2,46 @@ package github
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v73/github"
 	"github.com/pkg/errors"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/state"
 )
 
 // Client handles GitHub operations for backing up dashboards.
 type Client struct {
-	client     *github.Client
-	repository Repository
-	branch     string
-	directory  string
-	logger     *slog.Logger
+	client        *github.Client
+	repository    Repository
+	branch        string
+	directory     string
+	mode          Mode
+	deferDeletion bool
+	verify        bool
+	metrics       *Metrics
+	templates     *Templates
+	retention     time.Duration
+	logger        *slog.Logger
+
+	// mu guards runs and the purge times in state.
+	mu sync.Mutex
+	// runs holds the prune runs that have backed up dashboards in ModePullRequest and whose pull request has not been
+	// opened yet.
+	runs map[runKey]*run
+	// state holds the time of the last successful purge of each namespace. See PurgeExpiredBackups.
+	state store
+}
+
+// store persists the purge times of Client between restarts of Frigg.
+type store interface {
+	Get(key string, target any) (bool, error)
+	Set(key string, value any) error
 }
 
 // ClientOptions contains options for creating a Client.
@@ -25,11 +50,47 @@ type ClientOptions struct {
 	Repository Repository
 	Branch     string
 	Directory  string
-	Logger     *slog.Logger
+	// Mode determines how backups are written to the repository. Defaults to ModeCommit.
+	Mode Mode
+	// DeferDeletion marks the pull requests opened in ModePullRequest as deletion proposals that must be merged before
+	// their dashboards are deleted. See Client.Proposals.
+	DeferDeletion bool
+	// Verify makes BackUpDashboard read each backup back from the repository and compare it to the dashboard. A backup
+	// that cannot be read back or that differs from the dashboard is reported as an error.
+	Verify bool
+	// Metrics is required if Verify is true.
+	Metrics *Metrics
+	// Templates render the path and commit message of each backup. Defaults to DefaultPathTemplate and
+	// DefaultMessageTemplate.
+	Templates *Templates
+	// Retention is how long backups are kept before PurgeExpiredBackups removes them. Backups are kept forever if
+	// Retention is zero.
+	Retention time.Duration
+	// State persists the time of the last purge of each namespace so that a restart of Frigg does not trigger a new
+	// purge. Defaults to a state.Store that only keeps state in memory.
+	State  store
+	Logger *slog.Logger
 }
 
 // NewClient creates a new Client with authentication.
 func NewClient(opts *ClientOptions) *Client {
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeCommit
+	}
+
+	templates := opts.Templates
+	if templates == nil {
+		// The default templates are known to be valid.
+		templates, _ = NewTemplates(DefaultPathTemplate, DefaultMessageTemplate)
+	}
+
+	st := opts.State
+	if st == nil {
+		// A state.Store without a path cannot fail to be created.
+		st, _ = state.NewStore("")
+	}
+
 	logger := opts.Logger.With(
 		slog.String("repository", opts.Repository.Name()),
 		slog.String("branch", opts.Branch),
@@ -37,68 +98,205 @@ func NewClient(opts *ClientOptions) *Client {
 	)
 
 	return &Client{
-		client:     opts.Client,
-		repository: opts.Repository,
-		branch:     opts.Branch,
-		directory:  opts.Directory,
-		logger:     logger,
+		client:        opts.Client,
+		repository:    opts.Repository,
+		branch:        opts.Branch,
+		directory:     opts.Directory,
+		mode:          mode,
+		deferDeletion: opts.DeferDeletion,
+		verify:        opts.Verify,
+		metrics:       opts.Metrics,
+		templates:     templates,
+		retention:     opts.Retention,
+		logger:        logger,
+		runs:          make(map[runKey]*run),
+		state:         st,
 	}
 }
 
 // BackUpDashboard backs up a dashboard to the GitHub repository.
-func (c *Client) BackUpDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error {
-	path := fmt.Sprintf("%s/%s/%s.json", c.directory, namespace, name)
-	message := fmt.Sprintf("Back up deleted Grafana dashboard %s/%s", namespace, name)
+//
+// In ModeCommit, the backup is committed directly to the configured branch. In ModePullRequest, the backup is committed
+// to a branch that is specific to the dashboard's prune run. See FinishRun.
+func (c *Client) BackUpDashboard(ctx context.Context, dashboard *backup.Dashboard) error {
+	relativePath, err := c.templates.Path(dashboard)
+	if err != nil {
+		return errors.Wrap(err, "rendering backup path")
+	}
+	path := c.directory + "/" + relativePath
+
+	message, err := c.templates.Message(dashboard)
+	if err != nil {
+		return errors.Wrap(err, "rendering commit message")
+	}
+
+	branch := c.branch
+	if c.mode == ModePullRequest {
+		branch, err = c.runBranch(ctx, dashboard.Namespace, dashboard.RunID)
+		if err != nil {
+			return errors.Wrap(err, "creating run branch")
+		}
+	}
 
 	c.logger.Info("Backing up dashboard to GitHub",
 		slog.String("path", path),
-		slog.String("namespace", namespace),
-		slog.String("name", name))
+		slog.String("namespace", dashboard.Namespace),
+		slog.String("name", dashboard.Name))
+
+	commit, err := c.writeFile(ctx, branch, path, message, dashboard.JSON)
+	if err != nil {
+		return err
+	}
+
+	if c.verify {
+		if err := c.verifyFile(ctx, branch, path, dashboard); err != nil {
+			return err
+		}
+	}
+
+	// The branch of a prune run is deleted once its pull request has been merged, so backups on it are located by the
+	// commit that wrote them, which outlives the branch.
+	ref := branch
+	if c.mode == ModePullRequest && commit != "" {
+		ref = commit
+	}
+	dashboard.Location = c.blobURL(ref, path)
+
+	if c.mode == ModePullRequest || c.retention > 0 {
+		c.recordBackup(dashboard, branch, path)
+	}
+
+	return nil
+}
 
+// blobURL returns the URL at which the file at path can be viewed on GitHub at ref, e.g., a branch or commit.
+func (c *Client) blobURL(ref, path string) string {
+	// The web interface of GitHub Enterprise Server is served from the host of its API, that of github.com is not.
+	base := &url.URL{Scheme: c.client.BaseURL.Scheme, Host: c.client.BaseURL.Host}
+	if base.Host == "api.github.com" {
+		base.Host = "github.com"
+	}
+
+	return base.JoinPath(c.repository.Owner(), c.repository.Repo(), "blob", ref, path).String()
+}
+
+// writeFile creates or updates the file at path on branch. writeFile returns the SHA of the commit that wrote the
+// file, which is empty if GitHub did not return it.
+func (c *Client) writeFile(ctx context.Context, branch, path, message string, content []byte) (string, error) {
 	fileContent, _, resp, err := c.client.Repositories.GetContents(
 		ctx, c.repository.Owner(), c.repository.Repo(), path, &github.RepositoryContentGetOptions{
-			Ref: c.branch,
+			Ref: branch,
 		},
 	)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return c.createFile(ctx, path, message, dashboardJSON)
+			return c.createFile(ctx, branch, path, message, content)
 		}
-		return errors.Wrap(err, "checking if file exists")
+		return "", errors.Wrap(err, "checking if file exists")
+	}
+
+	return c.updateFile(ctx, branch, path, message, content, fileContent.GetSHA())
+}
+
+// verifyFile reads the file at path on branch back from the repository and compares its hash to the hash of
+// dashboard. verifyFile returns an error if the file cannot be read or if the hashes differ.
+func (c *Client) verifyFile(ctx context.Context, branch, path string, dashboard *backup.Dashboard) error {
+	stored, err := c.readFile(ctx, branch, path)
+	if err != nil {
+		c.metrics.verificationFailures.WithLabelValues(dashboard.Namespace, verificationFailureError).Inc()
+		return errors.Wrapf(err, "reading back backup at path %s", path)
+	}
+
+	expected, actual := dashboard.Hash(), backup.Hash(stored)
+	if expected != actual {
+		c.metrics.verificationFailures.WithLabelValues(dashboard.Namespace, verificationFailureMismatch).Inc()
+		c.logger.Error(
+			"Dashboard backup verification failed",
+			slog.String("path", path),
+			slog.String("expected_hash", expected),
+			slog.String("actual_hash", actual),
+		)
+		return errors.Errorf("verifying backup at path %s: expected hash %s but got %s", path, expected, actual)
 	}
 
-	return c.updateFile(ctx, path, message, dashboardJSON, fileContent.GetSHA())
+	c.logger.Info("Verified dashboard backup", slog.String("path", path), slog.String("hash", actual))
+	return nil
 }
 
-func (c *Client) createFile(ctx context.Context, path, message string, content []byte) error {
+// readFile returns the content of the file at path on branch.
+func (c *Client) readFile(ctx context.Context, branch, path string) ([]byte, error) {
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+
+	file, _, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: branch,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting file")
+	}
+	if file == nil {
+		return nil, errors.Errorf("path %s is not a file", path)
+	}
+
+	// The contents API omits the content of files larger than 1 MB, but the content can still be read as a blob.
+	if file.GetEncoding() == "none" {
+		buf, _, err := c.client.Git.GetBlobRaw(ctx, owner, repo, file.GetSHA())
+		if err != nil {
+			return nil, errors.Wrap(err, "getting blob")
+		}
+		return buf, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding file content")
+	}
+
+	return []byte(content), nil
+}
+
+func (c *Client) createFile(ctx context.Context, branch, path, message string, content []byte) (string, error) {
 	opts := &github.RepositoryContentFileOptions{
 		Message: github.Ptr(message),
 		Content: content,
-		Branch:  github.Ptr(c.branch),
+		Branch:  github.Ptr(branch),
 	}
 
-	_, _, err := c.client.Repositories.CreateFile(ctx, c.repository.Owner(), c.repository.Repo(), path, opts)
+	resp, _, err := c.client.Repositories.CreateFile(ctx, c.repository.Owner(), c.repository.Repo(), path, opts)
 	if err != nil {
-		return errors.Wrap(err, "creating file")
+		return "", errors.Wrap(err, "creating file")
 	}
 
 	c.logger.Info("Created dashboard backup file", slog.String("path", path))
-	return nil
+	return commitSHA(resp), nil
 }
 
-func (c *Client) updateFile(ctx context.Context, path, message string, content []byte, sha string) error {
+func (c *Client) updateFile(
+	ctx context.Context,
+	branch, path, message string,
+	content []byte,
+	sha string,
+) (string, error) {
 	opts := &github.RepositoryContentFileOptions{
 		Message: github.Ptr(message),
 		Content: content,
-		Branch:  github.Ptr(c.branch),
+		Branch:  github.Ptr(branch),
 		SHA:     github.Ptr(sha),
 	}
 
-	_, _, err := c.client.Repositories.UpdateFile(ctx, c.repository.Owner(), c.repository.Repo(), path, opts)
+	resp, _, err := c.client.Repositories.UpdateFile(ctx, c.repository.Owner(), c.repository.Repo(), path, opts)
 	if err != nil {
-		return errors.Wrap(err, "updating file")
+		return "", errors.Wrap(err, "updating file")
 	}
 
 	c.logger.Info("Updated dashboard backup file", slog.String("path", path))
-	return nil
+	return commitSHA(resp), nil
+}
+
+// commitSHA returns the SHA of the commit that created or updated a file, or an empty string if resp has no commit.
+func commitSHA(resp *github.RepositoryContentResponse) string {
+	if resp == nil {
+		return ""
+	}
+
+	return resp.GetSHA()
 }
@@ -2,16 +2,22 @@ package github_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	gogithub "github.com/google/go-github/v73/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/LasseHels/frigg/backup"
 	"github.com/LasseHels/frigg/github"
 )
 
@@ -32,6 +38,7 @@ func TestClient_BackUpDashboard(t *testing.T) {
 	tests := map[string]struct {
 		setupMockHandler func(t *testing.T) http.HandlerFunc
 		wantErr          string
+		wantLocation     string
 	}{
 		"creates new file when file does not exist": {
 			setupMockHandler: func(t *testing.T) http.HandlerFunc {
@@ -52,6 +59,8 @@ func TestClient_BackUpDashboard(t *testing.T) {
 					writeResponse(t, w, []byte(`{"content":{}}`))
 				}
 			},
+			wantLocation: "https://github.com/test-owner/test-repo/blob/main/deleted-dashboards/test-namespace/" +
+				"test-dashboard.json",
 		},
 		"updates existing file when file exists": {
 			setupMockHandler: func(t *testing.T) http.HandlerFunc {
@@ -75,6 +84,8 @@ func TestClient_BackUpDashboard(t *testing.T) {
 					}
 				}
 			},
+			wantLocation: "https://github.com/test-owner/test-repo/blob/main/deleted-dashboards/test-namespace/" +
+				"test-dashboard.json",
 		},
 		"returns error when GetContents fails with non-404": {
 			setupMockHandler: func(t *testing.T) http.HandlerFunc {
@@ -139,7 +150,12 @@ func TestClient_BackUpDashboard(t *testing.T) {
 				Logger:     logger,
 			})
 
-			err := client.BackUpDashboard(t.Context(), namespace, dashboardName, dashboardJSON)
+			dashboard := &backup.Dashboard{
+				Namespace: namespace,
+				Name:      dashboardName,
+				JSON:      dashboardJSON,
+			}
+			err := client.BackUpDashboard(t.Context(), dashboard)
 
 			if tc.wantErr != "" {
 				require.Error(t, err)
@@ -147,10 +163,186 @@ func TestClient_BackUpDashboard(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 			}
+			assert.Equal(t, tc.wantLocation, dashboard.Location)
 		})
 	}
 }
 
+func TestClient_BackUpDashboard_Verify(t *testing.T) {
+	t.Parallel()
+
+	repository, err := github.NewRepository("test-owner", "test-repo")
+	require.NoError(t, err)
+
+	// The dashboard JSON and a different JSON, both base64-encoded as returned by the contents API.
+	const dashboardContent = "eyJkYXNoYm9hcmQiOiAidGVzdCJ9"
+	const otherContent = "eyJkYXNoYm9hcmQiOiAib3RoZXIifQ=="
+
+	tests := map[string]struct {
+		readBack        func(t *testing.T, w http.ResponseWriter)
+		blob            string
+		expectedErr     string
+		expectedMetrics string
+	}{
+		"verifies backup that matches dashboard": {
+			readBack: func(t *testing.T, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusOK)
+				writeResponse(t, w, []byte(`{"type":"file","encoding":"base64","content":"`+dashboardContent+`"}`))
+			},
+			expectedErr:     "",
+			expectedMetrics: "",
+		},
+		"verifies large backup through blob": {
+			readBack: func(t *testing.T, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusOK)
+				writeResponse(t, w, []byte(`{"type":"file","encoding":"none","sha":"blob-sha"}`))
+			},
+			blob:            `{"dashboard": "test"}`,
+			expectedErr:     "",
+			expectedMetrics: "",
+		},
+		"returns error when backup differs from dashboard": {
+			readBack: func(t *testing.T, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusOK)
+				writeResponse(t, w, []byte(`{"type":"file","encoding":"base64","content":"`+otherContent+`"}`))
+			},
+			expectedErr: "verifying backup at path deleted-dashboards/test-namespace/test-dashboard.json: " +
+				"expected hash 09c07e3f128669f6ee18ff956e35fc58c8301df7a2280df959bf3678a45d487b " +
+				"but got 5cd315628e950c4f8d5520927bf0a78538393470fe581714e806fff06c8a4cab",
+			expectedMetrics: `
+# HELP frigg_backup_verification_failures_total Total number of dashboard backups that failed verification.
+# TYPE frigg_backup_verification_failures_total counter
+frigg_backup_verification_failures_total{namespace="test-namespace",reason="mismatch"} 1
+`,
+		},
+		"returns error when backup cannot be read back": {
+			readBack: func(_ *testing.T, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectedErr: "reading back backup at path deleted-dashboards/test-namespace/test-dashboard.json: " +
+				"getting file: GET",
+			expectedMetrics: `
+# HELP frigg_backup_verification_failures_total Total number of dashboard backups that failed verification.
+# TYPE frigg_backup_verification_failures_total counter
+frigg_backup_verification_failures_total{namespace="test-namespace",reason="error"} 1
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			written := false
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						assert.Equal(t, "main", r.URL.Query().Get("ref"))
+						if !written {
+							w.WriteHeader(http.StatusNotFound)
+							return
+						}
+						tc.readBack(t, w)
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PutReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+						written = true
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"content":{}}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						assert.Equal(t, "/repos/test-owner/test-repo/git/blobs/blob-sha", r.URL.Path)
+						w.WriteHeader(http.StatusOK)
+						writeResponse(t, w, []byte(tc.blob))
+					}),
+				),
+			)
+
+			registry := prometheus.NewRegistry()
+			logger, _ := testLogger()
+			client := github.NewClient(&github.ClientOptions{
+				Client:     gogithub.NewClient(mockedHTTPClient).WithAuthToken("test-token"),
+				Repository: *repository,
+				Branch:     "main",
+				Directory:  "deleted-dashboards",
+				Verify:     true,
+				Metrics:    github.NewMetrics(registry),
+				Logger:     logger,
+			})
+
+			err := client.BackUpDashboard(t.Context(), &backup.Dashboard{
+				Namespace: "test-namespace",
+				Name:      "test-dashboard",
+				JSON:      []byte(`{"dashboard": "test"}`),
+			})
+
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = testutil.GatherAndCompare(
+				registry,
+				strings.NewReader(tc.expectedMetrics),
+				"frigg_backup_verification_failures_total",
+			)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_BackUpDashboard_EnterpriseLocation(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(
+			t,
+			"/api/v3/repos/test-owner/test-repo/contents/deleted-dashboards/test-namespace/test-dashboard.json",
+			r.URL.Path,
+		)
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeResponse(t, w, []byte(`{"content":{}}`))
+	}))
+	defer server.Close()
+
+	repository, err := github.NewRepository("test-owner", "test-repo")
+	require.NoError(t, err)
+
+	enterprise, err := gogithub.NewClient(nil).WithEnterpriseURLs(server.URL+"/api/v3/", server.URL+"/api/uploads/")
+	require.NoError(t, err)
+
+	logger, _ := testLogger()
+	client := github.NewClient(&github.ClientOptions{
+		Client:     enterprise,
+		Repository: *repository,
+		Branch:     "main",
+		Directory:  "deleted-dashboards",
+		Logger:     logger,
+	})
+
+	dashboard := &backup.Dashboard{Namespace: "test-namespace", Name: "test-dashboard", JSON: []byte(`{}`)}
+	require.NoError(t, client.BackUpDashboard(t.Context(), dashboard))
+
+	// The web interface of GitHub Enterprise Server is served from the host of its API.
+	assert.Equal(
+		t,
+		server.URL+"/test-owner/test-repo/blob/main/deleted-dashboards/test-namespace/test-dashboard.json",
+		dashboard.Location,
+	)
+}
+
 func testLogger() (*slog.Logger, *bytes.Buffer) {
 	buf := &bytes.Buffer{}
 	replaceTime := func(_ []string, a slog.Attr) slog.Attr {
@@ -181,3 +373,9 @@ func writeResponse(t *testing.T, w http.ResponseWriter, data []byte) {
 	_, err := w.Write(data)
 	assert.NoError(t, err)
 }
+
+func unmarshalBody(t *testing.T, r *http.Request, target any) {
+	t.Helper()
+	err := json.Unmarshal([]byte(readBody(t, r)), target)
+	require.NoError(t, err)
+}
@@ -0,0 +1,31 @@
+package github
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// verificationFailureMismatch is a verification failure where the stored backup differs from the dashboard.
+	verificationFailureMismatch = "mismatch"
+	// verificationFailureError is a verification failure where the stored backup could not be read.
+	verificationFailureError = "error"
+)
+
+// Metrics holds the Prometheus metrics of Client.
+type Metrics struct {
+	verificationFailures *prometheus.CounterVec
+}
+
+// NewMetrics creates Metrics and registers them with registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		verificationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "frigg_backup_verification_failures_total",
+			Help: "Total number of dashboard backups that failed verification.",
+		}, []string{"namespace", "reason"}),
+	}
+
+	registerer.MustRegister(m.verificationFailures)
+
+	return m
+}
@@ -0,0 +1,365 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/pkg/errors"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+// proposalLabel is added to pull requests that propose the deletion of dashboards. Client uses the label to find
+// proposals that have not been resolved yet. See Client.Proposals.
+const proposalLabel = "frigg-pending-deletion"
+
+// proposalMarker matches the machine-readable summary that Client embeds in the body of a proposal pull request.
+var proposalMarker = regexp.MustCompile(`<!-- frigg-proposal (.*?) -->`)
+
+type runKey struct {
+	namespace string
+	runID     string
+}
+
+// run is a prune run that has backed up at least one dashboard in ModePullRequest or while retention is enabled.
+type run struct {
+	branch     string
+	dashboards []backup.Dashboard
+	// paths holds the path of each backup in dashboards.
+	paths []string
+}
+
+// proposalSummary is embedded in the body of proposal pull requests.
+type proposalSummary struct {
+	Namespace  string                     `json:"namespace"`
+	Dashboards []backup.ProposedDashboard `json:"dashboards"`
+}
+
+// runBranch returns the name of the branch that holds the backups of the given prune run. runBranch creates the branch
+// from the head of the configured branch if it does not exist yet.
+func (c *Client) runBranch(ctx context.Context, namespace, runID string) (string, error) {
+	key := runKey{namespace: namespace, runID: runID}
+
+	c.mu.Lock()
+	r, ok := c.runs[key]
+	c.mu.Unlock()
+	if ok {
+		return r.branch, nil
+	}
+
+	branch := runBranchName(namespace, runID)
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+
+	base, _, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+c.branch)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting head of branch %s", c.branch)
+	}
+
+	ref := &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: base.GetObject().SHA},
+	}
+	if _, _, err := c.client.Git.CreateRef(ctx, owner, repo, ref); err != nil {
+		return "", errors.Wrapf(err, "creating branch %s", branch)
+	}
+
+	c.logger.Info("Created run branch", slog.String("run_branch", branch))
+
+	c.mu.Lock()
+	c.runs[key] = &run{branch: branch}
+	c.mu.Unlock()
+
+	return branch, nil
+}
+
+// runBranchName returns the name of the branch that holds the backups of the given prune run.
+func runBranchName(namespace, runID string) string {
+	return fmt.Sprintf("frigg/%s/%s", namespace, runID)
+}
+
+// recordBackup records that dashboard was backed up to path on branch so that FinishRun can summarise the run.
+func (c *Client) recordBackup(dashboard *backup.Dashboard, branch, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := runKey{namespace: dashboard.Namespace, runID: dashboard.RunID}
+	r, ok := c.runs[key]
+	if !ok {
+		r = &run{branch: branch}
+		c.runs[key] = r
+	}
+
+	r.dashboards = append(r.dashboards, *dashboard)
+	r.paths = append(r.paths, path)
+}
+
+// FinishRun is called once a prune run has backed up all of its dashboards.
+//
+// If retention is enabled, FinishRun writes a manifest of the run's backups that PurgeExpiredBackups later uses to find
+// expired backups. In ModePullRequest, FinishRun opens a pull request from the run's branch into the configured branch.
+// The pull request lists each dashboard that the run backed up along with its usage. FinishRun is a no-op if the run
+// did not back up any dashboards.
+func (c *Client) FinishRun(ctx context.Context, namespace, runID string) error {
+	if c.mode != ModePullRequest && c.retention == 0 {
+		return nil
+	}
+
+	key := runKey{namespace: namespace, runID: runID}
+
+	c.mu.Lock()
+	r, ok := c.runs[key]
+	delete(c.runs, key)
+	c.mu.Unlock()
+
+	if !ok || len(r.dashboards) == 0 {
+		return nil
+	}
+
+	if c.retention > 0 {
+		if err := c.writeManifest(ctx, namespace, runID, r); err != nil {
+			return errors.Wrap(err, "writing run manifest")
+		}
+	}
+
+	if c.mode != ModePullRequest {
+		return nil
+	}
+
+	body, err := c.pullRequestBody(namespace, runID, r)
+	if err != nil {
+		return errors.Wrap(err, "building pull request body")
+	}
+
+	title := fmt.Sprintf("Back up %d deleted Grafana dashboard(s) from namespace %s", len(r.dashboards), namespace)
+	if c.deferDeletion {
+		title = fmt.Sprintf("Delete %d unused Grafana dashboard(s) from namespace %s", len(r.dashboards), namespace)
+	}
+
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+	pr, _, err := c.client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.Ptr(title),
+		Head:  github.Ptr(r.branch),
+		Base:  github.Ptr(c.branch),
+		Body:  github.Ptr(body),
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating pull request")
+	}
+
+	if c.deferDeletion {
+		labels := []string{proposalLabel}
+		if _, _, err := c.client.Issues.AddLabelsToIssue(ctx, owner, repo, pr.GetNumber(), labels); err != nil {
+			return errors.Wrapf(err, "labelling pull request #%d", pr.GetNumber())
+		}
+	}
+
+	c.logger.Info(
+		"Opened pull request",
+		slog.String("url", pr.GetHTMLURL()),
+		slog.String("run_branch", r.branch),
+		slog.Int("dashboard_count", len(r.dashboards)),
+	)
+
+	return nil
+}
+
+func (c *Client) pullRequestBody(namespace, runID string, r *run) (string, error) {
+	dashboards := r.dashboards
+
+	var b strings.Builder
+
+	if c.deferDeletion {
+		_, _ = fmt.Fprintf(
+			&b,
+			"Frigg found the following unused Grafana dashboards in namespace `%s` in run `%s`.\n\n"+
+				"Merge this pull request to approve their deletion. Frigg deletes the dashboards in its first run after "+
+				"the merge. Dashboards that are viewed or changed in the meantime are not deleted. Close this pull "+
+				"request without merging it to reject the deletion.\n\n",
+			namespace,
+			runID,
+		)
+	} else {
+		_, _ = fmt.Fprintf(
+			&b,
+			"Frigg deleted the following unused Grafana dashboards from namespace `%s` in run `%s`.\n\n",
+			namespace,
+			runID,
+		)
+	}
+
+	b.WriteString("| Title | Name | UID | Created | Reads | Users |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for i := range dashboards {
+		d := &dashboards[i]
+		_, _ = fmt.Fprintf(
+			&b,
+			"| %s | `%s` | `%s` | %s | %d | %d |\n",
+			escapeTableCell(d.Title),
+			d.Name,
+			d.UID,
+			d.CreationTimestamp.UTC().Format("2006-01-02"),
+			d.Reads,
+			d.Users,
+		)
+	}
+
+	// All dashboards of a run share the same bots.
+	if bots := dashboards[0].Bots; len(bots) > 0 {
+		quoted := make([]string, 0, len(bots))
+		for _, bot := range bots {
+			quoted = append(quoted, "`"+bot+"`")
+		}
+		_, _ = fmt.Fprintf(
+			&b,
+			"\nReads by the following users were not counted as they read dashboards in bulk: %s.\n",
+			strings.Join(quoted, ", "),
+		)
+	}
+
+	if !c.deferDeletion {
+		return b.String(), nil
+	}
+
+	summary := proposalSummary{Namespace: namespace}
+	for i := range dashboards {
+		summary.Dashboards = append(summary.Dashboards, backup.ProposedDashboard{
+			Name: dashboards[i].Name,
+			Hash: dashboards[i].Hash(),
+			Path: r.paths[i],
+		})
+	}
+
+	buf, err := json.Marshal(summary)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding proposal summary")
+	}
+
+	_, _ = fmt.Fprintf(&b, "\n<!-- frigg-proposal %s -->\n", buf)
+
+	return b.String(), nil
+}
+
+// Proposals returns the unresolved deletion proposals of namespace. A proposal is a pull request opened by FinishRun
+// while DeferDeletion is set. An open pull request is pending, a merged pull request is approved and a pull request
+// that was closed without being merged is rejected.
+//
+// Proposals remain unresolved until ResolveProposal is called.
+func (c *Client) Proposals(ctx context.Context, namespace string) ([]backup.Proposal, error) {
+	var proposals []backup.Proposal
+
+	opts := &github.IssueListByRepoOptions{
+		State:       "all",
+		Labels:      []string{proposalLabel},
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, c.repository.Owner(), c.repository.Repo(), opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing pull requests")
+		}
+
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				continue
+			}
+
+			summary, ok := parseProposalSummary(issue.GetBody())
+			if !ok {
+				c.logger.Warn(
+					"Skipping labelled pull request without proposal summary",
+					slog.Int("number", issue.GetNumber()),
+				)
+				continue
+			}
+
+			if summary.Namespace != namespace {
+				continue
+			}
+
+			state := proposalState(issue)
+			if state == backup.ProposalApproved {
+				c.locateProposedBackups(summary.Dashboards)
+			}
+
+			proposals = append(proposals, backup.Proposal{
+				ID:         strconv.Itoa(issue.GetNumber()),
+				URL:        issue.GetHTMLURL(),
+				Namespace:  summary.Namespace,
+				State:      state,
+				Dashboards: summary.Dashboards,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.ListOptions.Page = resp.NextPage
+	}
+
+	return proposals, nil
+}
+
+// locateProposedBackups sets the location of each of dashboards whose path is known. The dashboards belong to a merged
+// proposal, whose backups are on the configured branch; the branch of the proposal's run has been deleted.
+func (c *Client) locateProposedBackups(dashboards []backup.ProposedDashboard) {
+	for i := range dashboards {
+		if dashboards[i].Path != "" {
+			dashboards[i].Location = c.blobURL(c.branch, dashboards[i].Path)
+		}
+	}
+}
+
+// ResolveProposal marks proposal as resolved so that it is no longer returned by Proposals.
+func (c *Client) ResolveProposal(ctx context.Context, proposal *backup.Proposal) error {
+	number, err := strconv.Atoi(proposal.ID)
+	if err != nil {
+		return errors.Wrapf(err, "parsing pull request number %q", proposal.ID)
+	}
+
+	_, err = c.client.Issues.RemoveLabelForIssue(ctx, c.repository.Owner(), c.repository.Repo(), number, proposalLabel)
+	if err != nil {
+		return errors.Wrapf(err, "removing label from pull request #%d", number)
+	}
+
+	return nil
+}
+
+func proposalState(issue *github.Issue) backup.ProposalState {
+	if issue.GetState() == "open" {
+		return backup.ProposalPending
+	}
+
+	if issue.GetPullRequestLinks().GetMergedAt().IsZero() {
+		return backup.ProposalRejected
+	}
+
+	return backup.ProposalApproved
+}
+
+func parseProposalSummary(body string) (proposalSummary, bool) {
+	matches := proposalMarker.FindStringSubmatch(body)
+	if len(matches) != 2 {
+		return proposalSummary{}, false
+	}
+
+	var summary proposalSummary
+	if err := json.Unmarshal([]byte(matches[1]), &summary); err != nil {
+		return proposalSummary{}, false
+	}
+
+	return summary, true
+}
+
+// escapeTableCell escapes characters that would otherwise break a Markdown table cell.
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
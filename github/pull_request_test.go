@@ -0,0 +1,348 @@
+package github_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/github"
+)
+
+func TestClient_PullRequestMode(t *testing.T) {
+	t.Parallel()
+
+	dashboard := &backup.Dashboard{
+		Namespace:         "default",
+		Name:              "dashboard1",
+		UID:               "uid1",
+		Title:             "Dashboard | 1",
+		CreationTimestamp: time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+		Reads:             2,
+		Users:             1,
+		RunID:             "20260102T030405Z",
+		JSON:              []byte(`{"title":"Dashboard | 1"}`),
+	}
+
+	tests := map[string]struct {
+		deferDeletion bool
+		bots          []string
+		expectedTitle string
+		expectedBody  string
+		expectLabel   bool
+	}{
+		"opens pull request after backing up": {
+			deferDeletion: false,
+			bots:          nil,
+			expectedTitle: "Back up 1 deleted Grafana dashboard(s) from namespace default",
+			expectedBody: "Frigg deleted the following unused Grafana dashboards from namespace `default` in run " +
+				"`20260102T030405Z`.\n\n" +
+				"| Title | Name | UID | Created | Reads | Users |\n" +
+				"| --- | --- | --- | --- | --- | --- |\n" +
+				"| Dashboard \\| 1 | `dashboard1` | `uid1` | 2025-03-14 | 2 | 1 |\n",
+			expectLabel: false,
+		},
+		"lists users detected as bots": {
+			deferDeletion: false,
+			bots:          []string{"crawler", "sa-export"},
+			expectedTitle: "Back up 1 deleted Grafana dashboard(s) from namespace default",
+			expectedBody: "Frigg deleted the following unused Grafana dashboards from namespace `default` in run " +
+				"`20260102T030405Z`.\n\n" +
+				"| Title | Name | UID | Created | Reads | Users |\n" +
+				"| --- | --- | --- | --- | --- | --- |\n" +
+				"| Dashboard \\| 1 | `dashboard1` | `uid1` | 2025-03-14 | 2 | 1 |\n" +
+				"\nReads by the following users were not counted as they read dashboards in bulk: `crawler`, " +
+				"`sa-export`.\n",
+			expectLabel: false,
+		},
+		"opens labelled proposal when deletion is deferred": {
+			deferDeletion: true,
+			bots:          nil,
+			expectedTitle: "Delete 1 unused Grafana dashboard(s) from namespace default",
+			expectedBody: "Frigg found the following unused Grafana dashboards in namespace `default` in run " +
+				"`20260102T030405Z`.\n\n" +
+				"Merge this pull request to approve their deletion. Frigg deletes the dashboards in its first run " +
+				"after the merge. Dashboards that are viewed or changed in the meantime are not deleted. Close this " +
+				"pull request without merging it to reject the deletion.\n\n" +
+				"| Title | Name | UID | Created | Reads | Users |\n" +
+				"| --- | --- | --- | --- | --- | --- |\n" +
+				"| Dashboard \\| 1 | `dashboard1` | `uid1` | 2025-03-14 | 2 | 1 |\n" +
+				"\n<!-- frigg-proposal {\"namespace\":\"default\",\"dashboards\":[{\"name\":\"dashboard1\"," +
+				"\"hash\":\"" + dashboard.Hash() + "\",\"path\":\"deleted-dashboards/default/dashboard1.json\"}]} -->\n",
+			expectLabel: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []string
+			record := func(call string) {
+				calls = append(calls, call)
+			}
+
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("get ref")
+						assert.Equal(t, "/repos/test-owner/test-repo/git/ref/heads/main", r.URL.Path)
+						writeResponse(t, w, []byte(`{"ref":"refs/heads/main","object":{"sha":"base-sha"}}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("create ref")
+						assert.JSONEq(
+							t,
+							`{"ref":"refs/heads/frigg/default/20260102T030405Z","sha":"base-sha"}`,
+							readBody(t, r),
+						)
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("get contents")
+						assert.Equal(t, "frigg/default/20260102T030405Z", r.URL.Query().Get("ref"))
+						w.WriteHeader(http.StatusNotFound)
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PutReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("put contents")
+						assert.Contains(t, readBody(t, r), `"branch":"frigg/default/20260102T030405Z"`)
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"content":{},"commit":{"sha":"commit-sha"}}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposPullsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("create pull request")
+						var pr gogithub.NewPullRequest
+						unmarshalBody(t, r, &pr)
+						assert.Equal(t, tc.expectedTitle, pr.GetTitle())
+						assert.Equal(t, tc.expectedBody, pr.GetBody())
+						assert.Equal(t, "frigg/default/20260102T030405Z", pr.GetHead())
+						assert.Equal(t, "main", pr.GetBase())
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"number":7,"html_url":"https://github.com/test-owner/test-repo/pull/7"}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("add label")
+						assert.Equal(t, "/repos/test-owner/test-repo/issues/7/labels", r.URL.Path)
+						assert.JSONEq(t, `["frigg-pending-deletion"]`, readBody(t, r))
+						writeResponse(t, w, []byte(`[]`))
+					}),
+				),
+			)
+
+			client := newPullRequestClient(t, mockedHTTPClient, tc.deferDeletion)
+
+			d := *dashboard
+			d.Bots = tc.bots
+
+			require.NoError(t, client.BackUpDashboard(t.Context(), &d))
+			// The run's branch is deleted once the pull request is merged, so the backup is located by its commit.
+			assert.Equal(
+				t,
+				"https://github.com/test-owner/test-repo/blob/commit-sha/deleted-dashboards/default/dashboard1.json",
+				d.Location,
+			)
+			require.NoError(t, client.FinishRun(t.Context(), "default", "20260102T030405Z"))
+			// The run has been finished, so a second call must not open another pull request.
+			require.NoError(t, client.FinishRun(t.Context(), "default", "20260102T030405Z"))
+
+			expectedCalls := []string{"get ref", "create ref", "get contents", "put contents", "create pull request"}
+			if tc.expectLabel {
+				expectedCalls = append(expectedCalls, "add label")
+			}
+			assert.Equal(t, expectedCalls, calls)
+		})
+	}
+
+	t.Run("does not open pull request for run without backups", func(t *testing.T) {
+		t.Parallel()
+
+		mockedHTTPClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.PostReposPullsByOwnerByRepo,
+				http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+					assert.Fail(t, "no pull request should be opened")
+				}),
+			),
+		)
+
+		client := newPullRequestClient(t, mockedHTTPClient, false)
+		require.NoError(t, client.FinishRun(t.Context(), "default", "20260102T030405Z"))
+	})
+
+	t.Run("finishing a run is a no-op in commit mode", func(t *testing.T) {
+		t.Parallel()
+
+		repository, err := github.NewRepository("test-owner", "test-repo")
+		require.NoError(t, err)
+		logger, _ := testLogger()
+
+		client := github.NewClient(&github.ClientOptions{
+			Client:     gogithub.NewClient(mock.NewMockedHTTPClient()),
+			Repository: *repository,
+			Branch:     "main",
+			Directory:  "deleted-dashboards",
+			Logger:     logger,
+		})
+
+		require.NoError(t, client.FinishRun(t.Context(), "default", "20260102T030405Z"))
+	})
+}
+
+func TestClient_Proposals(t *testing.T) {
+	t.Parallel()
+
+	issues := `[
+		{
+			"number": 1,
+			"state": "open",
+			"html_url": "https://github.com/test-owner/test-repo/pull/1",
+			"body": "<!-- frigg-proposal {\"namespace\":\"default\",\"dashboards\":[{\"name\":\"a\",\"hash\":\"h1\"}]} -->",
+			"pull_request": {}
+		},
+		{
+			"number": 2,
+			"state": "closed",
+			"html_url": "https://github.com/test-owner/test-repo/pull/2",
+			"body": "<!-- frigg-proposal {\"namespace\":\"default\",\"dashboards\":[{\"name\":\"b\",\"hash\":\"h2\",` +
+		`\"path\":\"deleted-dashboards/default/b.json\"}]} -->",
+			"pull_request": {"merged_at": "2026-01-02T03:04:05Z"}
+		},
+		{
+			"number": 3,
+			"state": "closed",
+			"html_url": "https://github.com/test-owner/test-repo/pull/3",
+			"body": "<!-- frigg-proposal {\"namespace\":\"default\",\"dashboards\":[{\"name\":\"c\",\"hash\":\"h3\"}]} -->",
+			"pull_request": {}
+		},
+		{
+			"number": 4,
+			"state": "open",
+			"body": "<!-- frigg-proposal {\"namespace\":\"org-2\",\"dashboards\":[{\"name\":\"d\",\"hash\":\"h4\"}]} -->",
+			"pull_request": {}
+		},
+		{
+			"number": 5,
+			"state": "open",
+			"body": "An issue that happens to carry the label.",
+			"pull_request": null
+		},
+		{
+			"number": 6,
+			"state": "open",
+			"body": "A pull request without a proposal summary.",
+			"pull_request": {}
+		}
+	]`
+
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "frigg-pending-deletion", r.URL.Query().Get("labels"))
+				assert.Equal(t, "all", r.URL.Query().Get("state"))
+				writeResponse(t, w, []byte(issues))
+			}),
+		),
+	)
+
+	client := newPullRequestClient(t, mockedHTTPClient, true)
+
+	proposals, err := client.Proposals(t.Context(), "default")
+	require.NoError(t, err)
+
+	expected := []backup.Proposal{
+		{
+			ID:         "1",
+			URL:        "https://github.com/test-owner/test-repo/pull/1",
+			Namespace:  "default",
+			State:      backup.ProposalPending,
+			Dashboards: []backup.ProposedDashboard{{Name: "a", Hash: "h1"}},
+		},
+		{
+			ID:        "2",
+			URL:       "https://github.com/test-owner/test-repo/pull/2",
+			Namespace: "default",
+			State:     backup.ProposalApproved,
+			Dashboards: []backup.ProposedDashboard{{
+				Name:     "b",
+				Hash:     "h2",
+				Path:     "deleted-dashboards/default/b.json",
+				Location: "https://github.com/test-owner/test-repo/blob/main/deleted-dashboards/default/b.json",
+			}},
+		},
+		{
+			ID:         "3",
+			URL:        "https://github.com/test-owner/test-repo/pull/3",
+			Namespace:  "default",
+			State:      backup.ProposalRejected,
+			Dashboards: []backup.ProposedDashboard{{Name: "c", Hash: "h3"}},
+		},
+	}
+	assert.Equal(t, expected, proposals)
+}
+
+func TestClient_ResolveProposal(t *testing.T) {
+	t.Parallel()
+
+	removed := false
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				removed = true
+				assert.Equal(t, "/repos/test-owner/test-repo/issues/2/labels/frigg-pending-deletion", r.URL.Path)
+				writeResponse(t, w, []byte(`[]`))
+			}),
+		),
+	)
+
+	client := newPullRequestClient(t, mockedHTTPClient, true)
+
+	err := client.ResolveProposal(t.Context(), &backup.Proposal{ID: "2"})
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	err = client.ResolveProposal(t.Context(), &backup.Proposal{ID: "not-a-number"})
+	require.EqualError(t, err, `parsing pull request number "not-a-number": strconv.Atoi: parsing "not-a-number": `+
+		`invalid syntax`)
+}
+
+func newPullRequestClient(t *testing.T, httpClient *http.Client, deferDeletion bool) *github.Client {
+	t.Helper()
+
+	repository, err := github.NewRepository("test-owner", "test-repo")
+	require.NoError(t, err)
+	logger, _ := testLogger()
+
+	return github.NewClient(&github.ClientOptions{
+		Client:        gogithub.NewClient(httpClient),
+		Repository:    *repository,
+		Branch:        "main",
+		Directory:     "deleted-dashboards",
+		Mode:          github.ModePullRequest,
+		DeferDeletion: deferDeletion,
+		Logger:        logger,
+	})
+}
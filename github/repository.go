@@ -6,15 +6,48 @@ import (
 	"strings"
 )
 
+// Secrets authenticate Frigg with GitHub. Exactly one of Token and App must be set.
 type Secrets struct {
-	Token string `yaml:"token" json:"token" validate:"required"`
+	Token string      `yaml:"token" json:"token" validate:"required_without=App,excluded_with=App"`
+	App   *AppSecrets `yaml:"app" json:"app" validate:"required_without=Token,excluded_with=Token"`
 }
 
 type Config struct {
-	Repository Repository `yaml:"repository" validate:"required"`
-	Branch     string     `yaml:"branch" validate:"required"`
-	Directory  string     `yaml:"directory" validate:"required"`
-	APIURL     string     `yaml:"api_url" validate:"omitempty,url"`
+	Repository  Repository         `yaml:"repository" validate:"required"`
+	Branch      string             `yaml:"branch" validate:"required"`
+	Directory   string             `yaml:"directory" validate:"required"`
+	APIURL      string             `yaml:"api_url" validate:"omitempty,url"`
+	Mode        Mode               `yaml:"mode" validate:"required,oneof=commit pull_request"`
+	PullRequest *PullRequestConfig `yaml:"pull_request" validate:"excluded_unless=Mode pull_request"`
+	// Verify makes Frigg read each backup back from the repository before it deletes the dashboard.
+	Verify bool `yaml:"verify"`
+	// PathTemplate is the Go template of the path, relative to Directory, that a dashboard is backed up to. See
+	// TemplateData for the available fields.
+	PathTemplate string `yaml:"path_template" validate:"required"`
+	// MessageTemplate is the Go template of the commit message that backs up a dashboard. See TemplateData for the
+	// available fields.
+	MessageTemplate string `yaml:"message_template" validate:"required"`
+}
+
+// Templates parses PathTemplate and MessageTemplate. See NewTemplates.
+func (c *Config) Templates() (*Templates, error) {
+	return NewTemplates(c.PathTemplate, c.MessageTemplate)
+}
+
+// Mode determines how Client writes backups to the repository.
+type Mode string
+
+const (
+	// ModeCommit commits backups directly to the configured branch.
+	ModeCommit Mode = "commit"
+	// ModePullRequest commits the backups of each prune run to a new branch and opens a pull request from that branch
+	// into the configured branch.
+	ModePullRequest Mode = "pull_request"
+)
+
+type PullRequestConfig struct {
+	// DeferDeletion makes Frigg delete dashboards only once the pull request that backs them up has been merged.
+	DeferDeletion bool `yaml:"defer_deletion"`
 }
 
 // Repository represents a GitHub repository in "owner/repo" format.
@@ -0,0 +1,396 @@
+package github
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	// manifestDirectory is the directory, relative to the backup directory, that holds run manifests.
+	manifestDirectory = ".frigg/runs"
+	// manifestTimeLayout is the time layout of manifest file names.
+	manifestTimeLayout = "20060102T150405Z"
+	// purgeInterval is the minimum time between two purges of the same namespace. Finding expired backups reads the
+	// entire repository tree, which is too expensive to do after every prune run.
+	purgeInterval = 24 * time.Hour
+	// purgesStateKey is the key of the time of the last purge of each namespace in the state store.
+	purgesStateKey = "backup_purges"
+)
+
+// manifest records the backups of a single prune run. PurgeExpiredBackups uses manifests to find expired backups.
+type manifest struct {
+	Namespace string `json:"namespace"`
+	RunID     string `json:"run_id"`
+	// DeletedAt is the start time of the prune run that deleted the dashboards.
+	DeletedAt time.Time        `json:"deleted_at"`
+	Backups   []manifestBackup `json:"backups"`
+}
+
+type manifestBackup struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+	// SHA is the Git blob SHA of the backup. A backup is only purged if its content is unchanged since the run, as a
+	// later run may have overwritten it with a newer backup.
+	SHA string `json:"sha"`
+}
+
+// writeManifest writes the manifest of run r to the run's branch.
+func (c *Client) writeManifest(ctx context.Context, namespace, runID string, r *run) error {
+	m := manifest{
+		Namespace: namespace,
+		RunID:     runID,
+		DeletedAt: r.dashboards[0].RunStart.UTC(),
+	}
+	for i := range r.dashboards {
+		m.Backups = append(m.Backups, manifestBackup{
+			Path: r.paths[i],
+			Name: r.dashboards[i].Name,
+			SHA:  blobSHA(r.dashboards[i].JSON),
+		})
+	}
+
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding manifest")
+	}
+
+	p := c.manifestPath(namespace, m.DeletedAt)
+	message := fmt.Sprintf("Record backups of run %s in namespace %s", runID, namespace)
+
+	_, err = c.writeFile(ctx, r.branch, p, message, buf)
+	return err
+}
+
+func (c *Client) manifestPath(namespace string, deletedAt time.Time) string {
+	return path.Join(c.directory, manifestDirectory, namespace, deletedAt.UTC().Format(manifestTimeLayout)+".json")
+}
+
+// PurgeExpiredBackups removes the backups of namespace that were deleted more than the retention before now.
+// PurgeExpiredBackups is a no-op if retention is disabled.
+//
+// Expired backups are found through the manifests written by FinishRun and are removed in a single commit along with
+// their manifests. In ModeCommit, the commit is pushed to the configured branch. In ModePullRequest, the commit is
+// pushed to a purge branch and a pull request is opened, unless a purge pull request for namespace is already open.
+//
+// Backups are purged at most once per purgeInterval per namespace. Calls within purgeInterval of the last successful
+// purge are no-ops. The time of the last purge is kept in the state store of the Client so that it survives restarts
+// when the store persists state.
+//
+// Backups that are not recorded in a manifest, such as backups written before retention was enabled, are never purged.
+//
+// If dry is true, PurgeExpiredBackups only logs the backups that would be removed.
+func (c *Client) PurgeExpiredBackups(ctx context.Context, namespace string, now time.Time, dry bool) error {
+	if c.retention == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	purges, err := c.purges()
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if last, purged := purges[namespace]; purged && now.Sub(last) < purgeInterval {
+		return nil
+	}
+
+	if err := c.purgeExpiredBackups(ctx, namespace, now, dry); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Other namespaces may have been purged in the meantime.
+	purges, err = c.purges()
+	if err != nil {
+		return err
+	}
+	purges[namespace] = now
+
+	if err := c.state.Set(purgesStateKey, purges); err != nil {
+		return errors.Wrap(err, "saving purge times")
+	}
+
+	return nil
+}
+
+// purges returns the time of the last successful purge of each namespace. purges must be called with mu held.
+func (c *Client) purges() (map[string]time.Time, error) {
+	purges := make(map[string]time.Time)
+	if _, err := c.state.Get(purgesStateKey, &purges); err != nil {
+		return nil, errors.Wrap(err, "getting purge times")
+	}
+
+	return purges, nil
+}
+
+func (c *Client) purgeExpiredBackups(ctx context.Context, namespace string, now time.Time, dry bool) error {
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+
+	ref, _, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+c.branch)
+	if err != nil {
+		return errors.Wrapf(err, "getting head of branch %s", c.branch)
+	}
+
+	head, _, err := c.client.Git.GetCommit(ctx, owner, repo, ref.GetObject().GetSHA())
+	if err != nil {
+		return errors.Wrap(err, "getting head commit")
+	}
+
+	tree, _, err := c.client.Git.GetTree(ctx, owner, repo, head.GetTree().GetSHA(), true)
+	if err != nil {
+		return errors.Wrap(err, "getting tree")
+	}
+	if tree.GetTruncated() {
+		c.logger.Warn("Repository tree is truncated, some expired backups may not be purged")
+	}
+
+	files := make(map[string]string, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			files[entry.GetPath()] = entry.GetSHA()
+		}
+	}
+
+	expired, err := c.expiredBackups(ctx, namespace, files, now.Add(-c.retention))
+	if err != nil {
+		return err
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	logger := c.logger.With(
+		slog.String("namespace", namespace),
+		slog.Int("purged_count", len(expired)),
+		slog.String("purged_paths", strings.Join(expired, ", ")),
+	)
+
+	if dry {
+		logger.Info("Would purge expired dashboard backups")
+		return nil
+	}
+
+	if c.mode == ModePullRequest {
+		openURL, err := c.openPurgePullRequest(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		if openURL != "" {
+			logger.Info(
+				"Skipping purge of expired dashboard backups while previous purge pull request is open",
+				slog.String("url", openURL),
+			)
+			return nil
+		}
+	}
+
+	prURL, err := c.commitPurge(ctx, namespace, head, expired, now)
+	if err != nil {
+		return err
+	}
+
+	if prURL != "" {
+		logger = logger.With(slog.String("url", prURL))
+	}
+	logger.Info("Purged expired dashboard backups")
+
+	return nil
+}
+
+// openPurgePullRequest returns the URL of the open purge pull request of namespace, or an empty string if there is
+// none.
+func (c *Client) openPurgePullRequest(ctx context.Context, namespace string) (string, error) {
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+
+	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + purgeBranch(namespace),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "listing purge pull requests")
+	}
+
+	if len(prs) == 0 {
+		return "", nil
+	}
+
+	return prs[0].GetHTMLURL(), nil
+}
+
+// expiredBackups returns the paths of the backups and manifests of namespace that were deleted before cutoff. files
+// maps each path in the repository to its blob SHA.
+func (c *Client) expiredBackups(
+	ctx context.Context,
+	namespace string,
+	files map[string]string,
+	cutoff time.Time,
+) ([]string, error) {
+	prefix := path.Join(c.directory, manifestDirectory, namespace) + "/"
+
+	var manifests []string
+	for p := range files {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+
+		deletedAt, err := time.Parse(manifestTimeLayout, strings.TrimSuffix(path.Base(p), ".json"))
+		if err != nil {
+			c.logger.Warn("Skipping manifest with unexpected name", slog.String("path", p))
+			continue
+		}
+
+		if deletedAt.Before(cutoff) {
+			manifests = append(manifests, p)
+		}
+	}
+	sort.Strings(manifests)
+
+	var expired []string
+	for _, p := range manifests {
+		buf, _, err := c.client.Git.GetBlobRaw(ctx, c.repository.Owner(), c.repository.Repo(), files[p])
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading manifest %s", p)
+		}
+
+		var m manifest
+		if err := json.Unmarshal(buf, &m); err != nil {
+			return nil, errors.Wrapf(err, "decoding manifest %s", p)
+		}
+
+		for _, b := range m.Backups {
+			if files[b.Path] == b.SHA {
+				expired = append(expired, b.Path)
+			}
+		}
+
+		expired = append(expired, p)
+	}
+
+	return expired, nil
+}
+
+// commitPurge commits the removal of paths on top of head. commitPurge returns the URL of the purge pull request in
+// ModePullRequest.
+func (c *Client) commitPurge(
+	ctx context.Context,
+	namespace string,
+	head *github.Commit,
+	paths []string,
+	now time.Time,
+) (string, error) {
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+
+	entries := make([]*github.TreeEntry, 0, len(paths))
+	for _, p := range paths {
+		// A tree entry without SHA and content removes the file.
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(p),
+			Mode: github.Ptr("100644"),
+			Type: github.Ptr("blob"),
+		})
+	}
+
+	tree, _, err := c.client.Git.CreateTree(ctx, owner, repo, head.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", errors.Wrap(err, "creating tree")
+	}
+
+	message := fmt.Sprintf("Purge %d expired dashboard backup file(s) from namespace %s", len(paths), namespace)
+	commit, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.Ptr(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: head.SHA}},
+	}, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "creating commit")
+	}
+
+	if c.mode != ModePullRequest {
+		ref := &github.Reference{Ref: github.Ptr("refs/heads/" + c.branch), Object: &github.GitObject{SHA: commit.SHA}}
+		if _, _, err := c.client.Git.UpdateRef(ctx, owner, repo, ref, false); err != nil {
+			return "", errors.Wrapf(err, "updating branch %s", c.branch)
+		}
+		return "", nil
+	}
+
+	branch := purgeBranch(namespace)
+	if err := c.pointBranch(ctx, branch, commit.GetSHA()); err != nil {
+		return "", err
+	}
+
+	var body strings.Builder
+	_, _ = fmt.Fprintf(
+		&body,
+		"Frigg purges the following dashboard backups from namespace `%s` that were deleted before %s.\n\n",
+		namespace,
+		now.Add(-c.retention).UTC().Format(time.RFC3339),
+	)
+	for _, p := range paths {
+		_, _ = fmt.Fprintf(&body, "- `%s`\n", p)
+	}
+
+	pr, _, err := c.client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.Ptr(message),
+		Head:  github.Ptr(branch),
+		Base:  github.Ptr(c.branch),
+		Body:  github.Ptr(body.String()),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "creating pull request")
+	}
+
+	return pr.GetHTMLURL(), nil
+}
+
+// pointBranch points branch at sha. pointBranch creates branch if it does not exist and otherwise force-updates it, as
+// the branch may be left over from an earlier purge whose pull request has been closed.
+func (c *Client) pointBranch(ctx context.Context, branch, sha string) error {
+	owner, repo := c.repository.Owner(), c.repository.Repo()
+	ref := &github.Reference{Ref: github.Ptr("refs/heads/" + branch), Object: &github.GitObject{SHA: github.Ptr(sha)}}
+
+	_, resp, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	if err == nil {
+		if _, _, err := c.client.Git.UpdateRef(ctx, owner, repo, ref, true); err != nil {
+			return errors.Wrapf(err, "updating branch %s", branch)
+		}
+		return nil
+	}
+
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return errors.Wrapf(err, "getting branch %s", branch)
+	}
+
+	if _, _, err := c.client.Git.CreateRef(ctx, owner, repo, ref); err != nil {
+		return errors.Wrapf(err, "creating branch %s", branch)
+	}
+
+	return nil
+}
+
+func purgeBranch(namespace string) string {
+	return fmt.Sprintf("frigg/%s/purge", namespace)
+}
+
+// blobSHA returns the SHA that Git uses to identify a blob with content.
+func blobSHA(content []byte) string {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "blob %d\x00", len(content))
+	_, _ = h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,378 @@
+package github_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/github"
+	"github.com/LasseHels/frigg/state"
+)
+
+func TestClient_PurgeExpiredBackups(t *testing.T) {
+	t.Parallel()
+
+	repository, err := github.NewRepository("test-owner", "test-repo")
+	require.NoError(t, err)
+
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	// The first manifest is older than the retention and the second is not. The manifest of namespace other is never
+	// considered when purging namespace default. dashboard2 has been overwritten by a later run since the first
+	// manifest was written, so its backup is kept.
+	tree := `{
+		"sha": "tree-sha",
+		"truncated": false,
+		"tree": [
+			{"path": "deleted-dashboards/.frigg/runs/default/20250101T000000Z.json", "type": "blob", "sha": "manifest1"},
+			{"path": "deleted-dashboards/.frigg/runs/default/20260101T000000Z.json", "type": "blob", "sha": "manifest2"},
+			{"path": "deleted-dashboards/.frigg/runs/other/20240101T000000Z.json", "type": "blob", "sha": "manifest3"},
+			{"path": "deleted-dashboards/default", "type": "tree", "sha": "directory"},
+			{"path": "deleted-dashboards/default/dashboard1.json", "type": "blob", "sha": "sha1"},
+			{"path": "deleted-dashboards/default/dashboard2.json", "type": "blob", "sha": "sha2-overwritten"}
+		]
+	}`
+	manifest := `{
+		"namespace": "default",
+		"run_id": "20250101T000000Z",
+		"deleted_at": "2025-01-01T00:00:00Z",
+		"backups": [
+			{"path": "deleted-dashboards/default/dashboard1.json", "name": "dashboard1", "sha": "sha1"},
+			{"path": "deleted-dashboards/default/dashboard2.json", "name": "dashboard2", "sha": "sha2"}
+		]
+	}`
+	purged := []string{
+		"deleted-dashboards/default/dashboard1.json",
+		"deleted-dashboards/.frigg/runs/default/20250101T000000Z.json",
+	}
+
+	tests := map[string]struct {
+		mode          github.Mode
+		dry           bool
+		openPulls     string
+		expectedCalls []string
+		expectedLog   string
+	}{
+		"logs expired backups in dry run": {
+			mode:          github.ModeCommit,
+			dry:           true,
+			openPulls:     `[]`,
+			expectedCalls: []string{"get ref heads/main", "get commit", "get tree", "get blob manifest1"},
+			expectedLog:   `"msg":"Would purge expired dashboard backups"`,
+		},
+		"purges expired backups from branch": {
+			mode:      github.ModeCommit,
+			dry:       false,
+			openPulls: `[]`,
+			expectedCalls: []string{
+				"get ref heads/main",
+				"get commit",
+				"get tree",
+				"get blob manifest1",
+				"create tree",
+				"create commit",
+				"update ref heads/main",
+			},
+			expectedLog: `"msg":"Purged expired dashboard backups"`,
+		},
+		"opens pull request that purges expired backups": {
+			mode:      github.ModePullRequest,
+			dry:       false,
+			openPulls: `[]`,
+			expectedCalls: []string{
+				"get ref heads/main",
+				"get commit",
+				"get tree",
+				"get blob manifest1",
+				"list pull requests",
+				"create tree",
+				"create commit",
+				"get ref heads/frigg/default/purge",
+				"create ref refs/heads/frigg/default/purge",
+				"create pull request",
+			},
+			expectedLog: `"url":"https://github.com/test-owner/test-repo/pull/9"`,
+		},
+		"skips purge while purge pull request is open": {
+			mode:      github.ModePullRequest,
+			dry:       false,
+			openPulls: `[{"number":8,"html_url":"https://github.com/test-owner/test-repo/pull/8"}]`,
+			expectedCalls: []string{
+				"get ref heads/main",
+				"get commit",
+				"get tree",
+				"get blob manifest1",
+				"list pull requests",
+			},
+			expectedLog: `"msg":"Skipping purge of expired dashboard backups while previous purge pull request is open"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []string
+			record := func(call string) {
+				calls = append(calls, call)
+			}
+
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						ref := strings.TrimPrefix(r.URL.Path, "/repos/test-owner/test-repo/git/ref/")
+						record("get ref " + ref)
+						if ref != "heads/main" {
+							w.WriteHeader(http.StatusNotFound)
+							return
+						}
+						writeResponse(t, w, []byte(`{"ref":"refs/heads/main","object":{"sha":"head-sha"}}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("get commit")
+						assert.Equal(t, "/repos/test-owner/test-repo/git/commits/head-sha", r.URL.Path)
+						writeResponse(t, w, []byte(`{"sha":"head-sha","tree":{"sha":"tree-sha"}}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("get tree")
+						assert.Equal(t, "/repos/test-owner/test-repo/git/trees/tree-sha", r.URL.Path)
+						assert.Equal(t, "1", r.URL.Query().Get("recursive"))
+						writeResponse(t, w, []byte(tree))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						sha := strings.TrimPrefix(r.URL.Path, "/repos/test-owner/test-repo/git/blobs/")
+						record("get blob " + sha)
+						writeResponse(t, w, []byte(manifest))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposPullsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("list pull requests")
+						assert.Equal(t, "test-owner:frigg/default/purge", r.URL.Query().Get("head"))
+						assert.Equal(t, "open", r.URL.Query().Get("state"))
+						writeResponse(t, w, []byte(tc.openPulls))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTreesByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("create tree")
+						var body struct {
+							BaseTree string `json:"base_tree"`
+							Tree     []struct {
+								Path string  `json:"path"`
+								SHA  *string `json:"sha"`
+							} `json:"tree"`
+						}
+						unmarshalBody(t, r, &body)
+						assert.Equal(t, "tree-sha", body.BaseTree)
+						var paths []string
+						for _, entry := range body.Tree {
+							paths = append(paths, entry.Path)
+							assert.Nil(t, entry.SHA)
+						}
+						assert.Equal(t, purged, paths)
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"sha":"new-tree-sha"}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitCommitsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("create commit")
+						assert.JSONEq(
+							t,
+							`{
+								"message": "Purge 2 expired dashboard backup file(s) from namespace default",
+								"tree": "new-tree-sha",
+								"parents": ["head-sha"]
+							}`,
+							readBody(t, r),
+						)
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"sha":"purge-sha"}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("update ref " + strings.TrimPrefix(r.URL.Path, "/repos/test-owner/test-repo/git/refs/"))
+						assert.JSONEq(t, `{"sha":"purge-sha","force":false}`, readBody(t, r))
+						writeResponse(t, w, []byte(`{}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						var ref struct {
+							Ref string `json:"ref"`
+							SHA string `json:"sha"`
+						}
+						unmarshalBody(t, r, &ref)
+						record("create ref " + ref.Ref)
+						assert.Equal(t, "purge-sha", ref.SHA)
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{}`))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposPullsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						record("create pull request")
+						var pr gogithub.NewPullRequest
+						unmarshalBody(t, r, &pr)
+						assert.Equal(t, "Purge 2 expired dashboard backup file(s) from namespace default", pr.GetTitle())
+						assert.Equal(
+							t,
+							"Frigg purges the following dashboard backups from namespace `default` that were deleted "+
+								"before 2025-10-18T00:00:00Z.\n\n"+
+								"- `deleted-dashboards/default/dashboard1.json`\n"+
+								"- `deleted-dashboards/.frigg/runs/default/20250101T000000Z.json`\n",
+							pr.GetBody(),
+						)
+						assert.Equal(t, "frigg/default/purge", pr.GetHead())
+						assert.Equal(t, "main", pr.GetBase())
+						w.WriteHeader(http.StatusCreated)
+						writeResponse(t, w, []byte(`{"number":9,"html_url":"https://github.com/test-owner/test-repo/pull/9"}`))
+					}),
+				),
+			)
+
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			store, err := state.NewStore(statePath)
+			require.NoError(t, err)
+
+			logger, logs := testLogger()
+			opts := &github.ClientOptions{
+				Client:     gogithub.NewClient(mockedHTTPClient),
+				Repository: *repository,
+				Branch:     "main",
+				Directory:  "deleted-dashboards",
+				Mode:       tc.mode,
+				Retention:  365 * 24 * time.Hour,
+				State:      store,
+				Logger:     logger,
+			}
+			client := github.NewClient(opts)
+
+			require.NoError(t, client.PurgeExpiredBackups(t.Context(), "default", now, tc.dry))
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Contains(t, logs.String(), tc.expectedLog)
+			assert.Contains(
+				t,
+				logs.String(),
+				`"namespace":"default","purged_count":2,"purged_paths":"deleted-dashboards/default/dashboard1.json, `+
+					`deleted-dashboards/.frigg/runs/default/20250101T000000Z.json"`,
+			)
+			// The namespace is not purged again until the purge interval has passed.
+			calls = nil
+			require.NoError(t, client.PurgeExpiredBackups(t.Context(), "default", now.Add(23*time.Hour), tc.dry))
+			assert.Empty(t, calls)
+
+			// The time of the purge survives a restart of Frigg.
+			restarted, err := state.NewStore(statePath)
+			require.NoError(t, err)
+			opts.State = restarted
+			require.NoError(t, github.NewClient(opts).PurgeExpiredBackups(t.Context(), "default", now.Add(time.Hour), tc.dry))
+			assert.Empty(t, calls)
+		})
+	}
+
+	t.Run("is a no-op without retention", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := testLogger()
+		client := github.NewClient(&github.ClientOptions{
+			Client:     gogithub.NewClient(mock.NewMockedHTTPClient()),
+			Repository: *repository,
+			Branch:     "main",
+			Directory:  "deleted-dashboards",
+			Logger:     logger,
+		})
+
+		require.NoError(t, client.PurgeExpiredBackups(t.Context(), "default", now, false))
+	})
+}
+
+func TestClient_FinishRun_Manifest(t *testing.T) {
+	t.Parallel()
+
+	repository, err := github.NewRepository("test-owner", "test-repo")
+	require.NoError(t, err)
+
+	var written []gogithub.RepositoryContentFileOptions
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var file gogithub.RepositoryContentFileOptions
+				unmarshalBody(t, r, &file)
+				written = append(written, file)
+				w.WriteHeader(http.StatusCreated)
+				writeResponse(t, w, []byte(`{"content":{}}`))
+			}),
+		),
+	)
+
+	logger, _ := testLogger()
+	client := github.NewClient(&github.ClientOptions{
+		Client:     gogithub.NewClient(mockedHTTPClient),
+		Repository: *repository,
+		Branch:     "main",
+		Directory:  "deleted-dashboards",
+		Retention:  24 * time.Hour,
+		Logger:     logger,
+	})
+
+	err = client.BackUpDashboard(t.Context(), &backup.Dashboard{
+		Namespace: "default",
+		Name:      "dashboard1",
+		RunID:     "20260102T030405Z",
+		RunStart:  time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC),
+		JSON:      []byte(`{"dashboard": "test"}`),
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishRun(t.Context(), "default", "20260102T030405Z"))
+
+	require.Len(t, written, 2)
+	assert.Equal(t, "Record backups of run 20260102T030405Z in namespace default", written[1].GetMessage())
+	assert.Equal(t, "main", written[1].GetBranch())
+	// The SHA is the Git blob SHA of the backup, i.e., the output of git hash-object.
+	assert.JSONEq(t, `{
+		"namespace": "default",
+		"run_id": "20260102T030405Z",
+		"deleted_at": "2026-01-02T03:04:05Z",
+		"backups": [
+			{
+				"path": "deleted-dashboards/default/dashboard1.json",
+				"name": "dashboard1",
+				"sha": "90e7e00dcb2856edfaa4d5b26c9b4c25d06f5588"
+			}
+		]
+	}`, string(written[1].Content))
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+const (
+	// DefaultPathTemplate is the default template of the path, relative to the backup directory, that a dashboard is
+	// backed up to.
+	DefaultPathTemplate = "{{ .Namespace }}/{{ .Name }}.json"
+	// DefaultMessageTemplate is the default template of the commit message that backs up a dashboard.
+	DefaultMessageTemplate = "Back up deleted Grafana dashboard {{ .Namespace }}/{{ .Name }}"
+)
+
+// nonSlugCharacters matches runs of characters that slug replaces with a hyphen.
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// templateFuncs are available to path and message templates in addition to Go's predefined template functions.
+var templateFuncs = template.FuncMap{
+	"slug": slug,
+	"join": strings.Join,
+}
+
+// TemplateData is the data that path and message templates are executed with.
+type TemplateData struct {
+	Namespace   string
+	Name        string
+	UID         string
+	Title       string
+	FolderUID   string
+	FolderTitle string
+	Tags        []string
+	// Date is the date of the dashboard's prune run in YYYY-MM-DD format.
+	Date string
+	// Time is the start time of the dashboard's prune run. Time can be formatted with a custom layout, e.g.,
+	// {{ .Time.Format "2006/01" }}.
+	Time  time.Time
+	RunID string
+}
+
+// Templates render the path and commit message of dashboard backups.
+type Templates struct {
+	path    *template.Template
+	message *template.Template
+}
+
+// NewTemplates parses the path and message templates. An empty template is replaced by its default.
+//
+// NewTemplates executes both templates with example data so that templates that refer to unknown fields or produce
+// invalid paths are rejected up front rather than when a dashboard is backed up.
+func NewTemplates(pathTemplate, messageTemplate string) (*Templates, error) {
+	if pathTemplate == "" {
+		pathTemplate = DefaultPathTemplate
+	}
+	if messageTemplate == "" {
+		messageTemplate = DefaultMessageTemplate
+	}
+
+	p, err := template.New("path").Option("missingkey=error").Funcs(templateFuncs).Parse(pathTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing path template")
+	}
+
+	m, err := template.New("message").Option("missingkey=error").Funcs(templateFuncs).Parse(messageTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing message template")
+	}
+
+	t := &Templates{path: p, message: m}
+
+	example := &backup.Dashboard{
+		Namespace:   "default",
+		Name:        "example",
+		UID:         "example-uid",
+		Title:       "Example",
+		FolderUID:   "example-folder",
+		FolderTitle: "Example Folder",
+		Tags:        []string{"example"},
+		RunID:       "20060102T150405Z",
+		RunStart:    time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+	}
+	if _, err := t.Path(example); err != nil {
+		return nil, err
+	}
+	if _, err := t.Message(example); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Path returns the path, relative to the backup directory, that dashboard is backed up to. Path returns an error if the
+// path is empty or points outside the backup directory.
+func (t *Templates) Path(dashboard *backup.Dashboard) (string, error) {
+	rendered, err := execute(t.path, dashboard)
+	if err != nil {
+		return "", errors.Wrap(err, "executing path template")
+	}
+
+	cleaned := path.Clean(rendered)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") || path.IsAbs(cleaned) {
+		return "", errors.Errorf("path template produced invalid path %q", rendered)
+	}
+
+	return cleaned, nil
+}
+
+// Message returns the commit message that backs up dashboard.
+func (t *Templates) Message(dashboard *backup.Dashboard) (string, error) {
+	rendered, err := execute(t.message, dashboard)
+	if err != nil {
+		return "", errors.Wrap(err, "executing message template")
+	}
+
+	if strings.TrimSpace(rendered) == "" {
+		return "", errors.New("message template produced empty message")
+	}
+
+	return rendered, nil
+}
+
+func execute(t *template.Template, dashboard *backup.Dashboard) (string, error) {
+	data := TemplateData{
+		Namespace:   dashboard.Namespace,
+		Name:        dashboard.Name,
+		UID:         dashboard.UID,
+		Title:       dashboard.Title,
+		FolderUID:   dashboard.FolderUID,
+		FolderTitle: dashboard.FolderTitle,
+		Tags:        dashboard.Tags,
+		Date:        dashboard.RunStart.UTC().Format(time.DateOnly),
+		Time:        dashboard.RunStart.UTC(),
+		RunID:       dashboard.RunID,
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// slug converts s to lower case and replaces each run of characters other than letters and digits with a hyphen, e.g.,
+// "CPU / Memory" becomes "cpu-memory".
+func slug(s string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
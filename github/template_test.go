@@ -0,0 +1,112 @@
+package github_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/github"
+)
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	dashboard := &backup.Dashboard{
+		Namespace:   "org-2",
+		Name:        "ad4f1c2e",
+		UID:         "a1b2c3",
+		Title:       "CPU / Memory",
+		FolderUID:   "platform",
+		FolderTitle: "Platform Team",
+		Tags:        []string{"team-a", "ops"},
+		RunID:       "20251018T093000Z",
+		RunStart:    time.Date(2025, time.October, 18, 9, 30, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		pathTemplate    string
+		messageTemplate string
+		expectedPath    string
+		expectedMessage string
+	}{
+		"defaults": {
+			pathTemplate:    "",
+			messageTemplate: "",
+			expectedPath:    "org-2/ad4f1c2e.json",
+			expectedMessage: "Back up deleted Grafana dashboard org-2/ad4f1c2e",
+		},
+		"organised by date and folder": {
+			pathTemplate:    `{{ .Time.Format "2006/01" }}/{{ .FolderTitle | slug }}/{{ .Title | slug }}-{{ .UID }}.json`,
+			messageTemplate: "OPS-123: Back up {{ .Title }} ({{ join .Tags \", \" }}) on {{ .Date }} in run {{ .RunID }}",
+			expectedPath:    "2025/10/platform-team/cpu-memory-a1b2c3.json",
+			expectedMessage: "OPS-123: Back up CPU / Memory (team-a, ops) on 2025-10-18 in run 20251018T093000Z",
+		},
+		"cleans path": {
+			pathTemplate:    "./{{ .Namespace }}//{{ .FolderUID }}/{{ .Name }}.json",
+			messageTemplate: "{{ .Namespace }}/{{ .Name }}",
+			expectedPath:    "org-2/platform/ad4f1c2e.json",
+			expectedMessage: "org-2/ad4f1c2e",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			templates, err := github.NewTemplates(tt.pathTemplate, tt.messageTemplate)
+			require.NoError(t, err)
+
+			path, err := templates.Path(dashboard)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, path)
+
+			message, err := templates.Message(dashboard)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}
+
+func TestNewTemplates_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pathTemplate    string
+		messageTemplate string
+		expectedErr     string
+	}{
+		"path outside directory": {
+			pathTemplate: "../../{{ .Name }}.json",
+			expectedErr:  `path template produced invalid path "../../example.json"`,
+		},
+		"absolute path": {
+			pathTemplate: "/{{ .Name }}.json",
+			expectedErr:  `path template produced invalid path "/example.json"`,
+		},
+		"empty path": {
+			pathTemplate: "{{ if false }}{{ .Name }}{{ end }}",
+			expectedErr:  `path template produced invalid path ""`,
+		},
+		"empty message": {
+			messageTemplate: "{{ if false }}{{ .Name }}{{ end }} ",
+			expectedErr:     "message template produced empty message",
+		},
+		"unknown function": {
+			messageTemplate: "{{ .Name | upper }}",
+			expectedErr:     `parsing message template: template: message:1: function "upper" not defined`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			templates, err := github.NewTemplates(tt.pathTemplate, tt.messageTemplate)
+			require.EqualError(t, err, tt.expectedErr)
+			assert.Nil(t, templates)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package grafana
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// dashboardUIDAnnotation is the annotation of an alert rule that holds the UID of the dashboard that the rule links to.
+const dashboardUIDAnnotation = "__dashboardUid__"
+
+// AlertRule is a Grafana-managed alert rule.
+type AlertRule struct {
+	UID         string            `json:"uid"`
+	Title       string            `json:"title"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// DashboardUID returns the UID of the dashboard that r links to. DashboardUID returns an empty string if r does not
+// link to a dashboard.
+func (r *AlertRule) DashboardUID() string {
+	return r.Annotations[dashboardUIDAnnotation]
+}
+
+// AlertRules returns all Grafana-managed alert rules of the organisation that namespace belongs to.
+//
+// AlertRules uses the Grafana HTTP API endpoint GET /api/v1/provisioning/alert-rules.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/alerting_provisioning/#get-all-alert-rules.
+//
+//nolint:lll
+func (c *Client) AlertRules(ctx context.Context, namespace string) ([]AlertRule, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AlertRule
+	u := c.endpoint.JoinPath("api", "v1", "provisioning", "alert-rules")
+	if err := c.getJSON(ctx, u, id, &rules); err != nil {
+		return nil, errors.Wrap(err, "getting alert rules")
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,195 @@
+package grafana
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// defaultAnomalyDetectionRuns is the default number of previous runs that a run is compared with.
+const defaultAnomalyDetectionRuns = 10
+
+const (
+	// abortReasonEmptyChunk is an abort reason where a chunk of the usage range contained no logs.
+	abortReasonEmptyChunk = "empty_chunk"
+	// abortReasonLogCountDrop is an abort reason where the log count dropped compared to previous runs.
+	abortReasonLogCountDrop = "log_count_drop"
+	// abortReasonUsedCountDrop is an abort reason where the used dashboard count dropped compared to previous runs.
+	abortReasonUsedCountDrop = "used_count_drop"
+	// abortReasonChunkLogCountDrop is an abort reason where the lowest chunk log count dropped compared to previous
+	// runs.
+	abortReasonChunkLogCountDrop = "chunk_log_count_drop"
+)
+
+// history stores the statistics of previous prune runs.
+type history interface {
+	Get(key string, target any) (bool, error)
+	Set(key string, value any) error
+}
+
+// runStats are the statistics of a prune run that later runs are compared with.
+type runStats struct {
+	Time           time.Time `json:"time"`
+	LogCount       int       `json:"log_count"`
+	UsedCount      int       `json:"used_count"`
+	ChunkLogCounts []int     `json:"chunk_log_counts"`
+}
+
+// anomaly describes why the dashboard usage of a run cannot be trusted.
+type anomaly struct {
+	reason  string
+	message string
+}
+
+// checkUsage returns an error if usage is anomalous compared to the usage found by previous runs. checkUsage returns
+// the statistics of the run and whether they must be recorded, which they must not if anomaly detection is disabled or
+// the usage is anomalous. Previous runs that started before AnomalyDetectionConfig.IgnoreRunsBefore are discarded.
+//
+// checkUsage does not record the statistics. See recordUsage.
+func (d *DashboardPruner) checkUsage(usage *Usage, start time.Time) (runStats, bool, error) {
+	if d.safeguards.anomalyDetection == nil {
+		return runStats{}, false, nil
+	}
+
+	previous, err := d.previousRuns()
+	if err != nil {
+		return runStats{}, false, err
+	}
+
+	current := runStats{
+		Time:           start,
+		LogCount:       usage.LogCount,
+		UsedCount:      len(usage.Dashboards),
+		ChunkLogCounts: usage.ChunkLogCounts,
+	}
+
+	if a := d.detectAnomaly(previous, &current); a != nil {
+		if d.dry {
+			d.logger.Warn(
+				"Dashboard usage is anomalous, run would be aborted if not dry",
+				slog.String("reason", a.reason),
+				slog.String("detail", a.message),
+			)
+			return runStats{}, false, nil
+		}
+
+		d.metrics.abortedRuns.WithLabelValues(d.namespace, a.reason).Inc()
+		return runStats{}, false, fmt.Errorf("aborting run as %s", a.message)
+	}
+
+	return current, true, nil
+}
+
+// recordUsage records the statistics returned by checkUsage so that later runs are compared with them. recordUsage is
+// called once a run has passed every safeguard and finished, as a run that is aborted or that fails may have found
+// usage that later runs must not be compared with. Anomalous runs are never recorded either, as a run with lost logs
+// would otherwise lower the bar for later runs.
+func (d *DashboardPruner) recordUsage(stats *runStats) error {
+	previous, err := d.previousRuns()
+	if err != nil {
+		return err
+	}
+
+	runs := d.safeguards.anomalyDetection.Runs
+	if runs == 0 {
+		runs = defaultAnomalyDetectionRuns
+	}
+
+	previous = append(previous, *stats)
+	if len(previous) > runs {
+		previous = previous[len(previous)-runs:]
+	}
+
+	if err := d.safeguards.history.Set(d.historyKey(), previous); err != nil {
+		return fmt.Errorf("recording statistics of run: %w", err)
+	}
+
+	return nil
+}
+
+// previousRuns returns the statistics of previous runs that started after AnomalyDetectionConfig.IgnoreRunsBefore.
+func (d *DashboardPruner) previousRuns() ([]runStats, error) {
+	var previous []runStats
+	if _, err := d.safeguards.history.Get(d.historyKey(), &previous); err != nil {
+		return nil, fmt.Errorf("getting statistics of previous runs: %w", err)
+	}
+
+	return slices.DeleteFunc(previous, func(stats runStats) bool {
+		return stats.Time.Before(d.safeguards.anomalyDetection.IgnoreRunsBefore)
+	}), nil
+}
+
+// historyKey returns the key of the statistics of the runs of the namespace of d.
+func (d *DashboardPruner) historyKey() string {
+	return "runs/" + d.namespace
+}
+
+// detectAnomaly returns the first anomaly of current compared to previous, or nil if current is not anomalous.
+func (d *DashboardPruner) detectAnomaly(previous []runStats, current *runStats) *anomaly {
+	for i, count := range current.ChunkLogCounts {
+		if count == 0 {
+			return &anomaly{
+				reason:  abortReasonEmptyChunk,
+				message: fmt.Sprintf("chunk %d of %d contained no logs", i+1, len(current.ChunkLogCounts)),
+			}
+		}
+	}
+
+	if len(previous) == 0 {
+		return nil
+	}
+
+	checks := []struct {
+		reason string
+		name   string
+		value  func(stats *runStats) int
+	}{
+		{
+			reason: abortReasonLogCountDrop,
+			name:   "log count",
+			value:  func(stats *runStats) int { return stats.LogCount },
+		},
+		{
+			reason: abortReasonUsedCountDrop,
+			name:   "used dashboard count",
+			value:  func(stats *runStats) int { return stats.UsedCount },
+		},
+		{
+			reason: abortReasonChunkLogCountDrop,
+			name:   "lowest chunk log count",
+			value: func(stats *runStats) int {
+				if len(stats.ChunkLogCounts) == 0 {
+					return 0
+				}
+				return slices.Min(stats.ChunkLogCounts)
+			},
+		},
+	}
+
+	for _, check := range checks {
+		sum := 0
+		for i := range previous {
+			sum += check.value(&previous[i])
+		}
+		average := float64(sum) / float64(len(previous))
+
+		value := check.value(current)
+		if float64(value) < average*(1-d.safeguards.anomalyDetection.MaxDrop) {
+			return &anomaly{
+				reason: check.reason,
+				message: fmt.Sprintf(
+					"%s dropped from an average of %.1f in the previous %d run(s) to %d, which exceeds the maximum drop "+
+						"of %.0f%%",
+					check.name,
+					average,
+					len(previous),
+					value,
+					d.safeguards.anomalyDetection.MaxDrop*100,
+				),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/state"
+)
+
+func TestDashboardPruner_AnomalyDetection(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	previous := []runStats{
+		{Time: start.Add(-2 * time.Hour), LogCount: 90, UsedCount: 8, ChunkLogCounts: []int{40, 50}},
+		{Time: start.Add(-time.Hour), LogCount: 110, UsedCount: 12, ChunkLogCounts: []int{60, 50}},
+	}
+
+	tests := map[string]struct {
+		previous        []runStats
+		dry             bool
+		logCount        int
+		usedCount       int
+		chunkLogCounts  []int
+		expectedErr     string
+		expectedDeleted []string
+		expectedLog     string
+		expectedRuns    int
+		expectedMetrics string
+	}{
+		"records first run": {
+			previous:        nil,
+			dry:             false,
+			logCount:        100,
+			usedCount:       10,
+			chunkLogCounts:  []int{50, 50},
+			expectedErr:     "",
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedRuns:    1,
+			expectedMetrics: "",
+		},
+		"records run similar to previous runs": {
+			previous:        previous,
+			dry:             false,
+			logCount:        100,
+			usedCount:       10,
+			chunkLogCounts:  []int{50, 50},
+			expectedErr:     "",
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedRuns:    3,
+			expectedMetrics: "",
+		},
+		"aborts run with empty chunk": {
+			previous:        nil,
+			dry:             false,
+			logCount:        100,
+			usedCount:       10,
+			chunkLogCounts:  []int{50, 0, 50},
+			expectedErr:     "aborting run as chunk 2 of 3 contained no logs",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedRuns:    0,
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="empty_chunk"} 1
+`,
+		},
+		"aborts run when log count drops": {
+			previous:       previous,
+			dry:            false,
+			logCount:       40,
+			usedCount:      10,
+			chunkLogCounts: []int{40, 40},
+			expectedErr: "aborting run as log count dropped from an average of 100.0 in the previous 2 run(s) to 40, " +
+				"which exceeds the maximum drop of 50%",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedRuns:    2,
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="log_count_drop"} 1
+`,
+		},
+		"aborts run when used dashboard count drops": {
+			previous:       previous,
+			dry:            false,
+			logCount:       100,
+			usedCount:      4,
+			chunkLogCounts: []int{50, 50},
+			expectedErr: "aborting run as used dashboard count dropped from an average of 10.0 in the previous 2 " +
+				"run(s) to 4, which exceeds the maximum drop of 50%",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedRuns:    2,
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="used_count_drop"} 1
+`,
+		},
+		"aborts run when lowest chunk log count drops": {
+			previous:       previous,
+			dry:            false,
+			logCount:       100,
+			usedCount:      10,
+			chunkLogCounts: []int{90, 10},
+			expectedErr: "aborting run as lowest chunk log count dropped from an average of 45.0 in the previous 2 " +
+				"run(s) to 10, which exceeds the maximum drop of 50%",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedRuns:    2,
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="chunk_log_count_drop"} 1
+`,
+		},
+		"logs warning instead of aborting dry run": {
+			previous:        previous,
+			dry:             true,
+			logCount:        40,
+			usedCount:       10,
+			chunkLogCounts:  []int{40, 40},
+			expectedErr:     "",
+			expectedDeleted: nil,
+			//nolint:lll
+			expectedLog:     `{"level":"WARN","msg":"Dashboard usage is anomalous, run would be aborted if not dry","dry":true,"namespace":"default","reason":"log_count_drop","detail":"log count dropped from an average of 100.0 in the previous 2 run(s) to 40, which exceeds the maximum drop of 50%"}`,
+			expectedRuns:    2,
+			expectedMetrics: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := state.NewStore("")
+			require.NoError(t, err)
+			if tt.previous != nil {
+				require.NoError(t, store.Set("runs/default", tt.previous))
+			}
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{{UID: "uid1", Name: "unused", Namespace: "default"}}, nil
+				},
+				usage: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) (*Usage, error) {
+					dashboards := make([]DashboardReads, 0, tt.usedCount)
+					for i := range tt.usedCount {
+						dashboards = append(dashboards, newMockDashboardReads(fmt.Sprintf("used%d", i), 1, 1))
+					}
+					return &Usage{Dashboards: dashboards, LogCount: tt.logCount, ChunkLogCounts: tt.chunkLogCounts}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+			registry := prometheus.NewRegistry()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:          mockClient,
+				Logger:           l,
+				Namespace:        "default",
+				Interval:         time.Hour,
+				Period:           24 * time.Hour,
+				Labels:           map[string]string{"app": "grafana"},
+				Dry:              tt.dry,
+				AnomalyDetection: &AnomalyDetectionConfig{MaxDrop: 0.5},
+				History:          store,
+				Metrics:          NewMetrics(registry),
+			})
+
+			err = pruner.pruneRun(t.Context(), start)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+
+			var runs []runStats
+			_, err = store.Get("runs/default", &runs)
+			require.NoError(t, err)
+			assert.Len(t, runs, tt.expectedRuns)
+
+			err = testutil.GatherAndCompare(registry, strings.NewReader(tt.expectedMetrics), "frigg_prune_aborted_total")
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("does not record runs that fail after the check", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]struct {
+			maxDeletionPercentage float64
+			deleteErr             error
+			expectedErr           string
+		}{
+			"run aborted by later safeguard": {
+				maxDeletionPercentage: 10,
+				deleteErr:             nil,
+				expectedErr: "aborting run as 1 of 1 non-provisioned dashboards (100.0%) are unused, which exceeds " +
+					"the maximum deletion percentage of 10.0%",
+			},
+			"run that fails to delete dashboard": {
+				maxDeletionPercentage: 100,
+				deleteErr:             errors.New("connection refused"),
+				expectedErr:           "connection refused",
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				store, err := state.NewStore("")
+				require.NoError(t, err)
+
+				mockClient := &mockGrafanaClient{
+					allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+						return []Dashboard{{UID: "uid1", Name: "unused", Namespace: "default"}}, nil
+					},
+					usage: func(
+						_ context.Context,
+						_ map[string]string,
+						_ time.Duration,
+						_ UsedDashboardsOptions,
+					) (*Usage, error) {
+						return &Usage{LogCount: 100, ChunkLogCounts: []int{50, 50}}, nil
+					},
+					deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
+						return tc.deleteErr
+					},
+				}
+
+				l, _ := logger()
+
+				pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+					Grafana:               mockClient,
+					Logger:                l,
+					Namespace:             "default",
+					Interval:              time.Hour,
+					Period:                24 * time.Hour,
+					Labels:                map[string]string{"app": "grafana"},
+					MaxDeletionPercentage: &tc.maxDeletionPercentage,
+					AnomalyDetection:      &AnomalyDetectionConfig{MaxDrop: 0.5},
+					History:               store,
+					Metrics:               NewMetrics(prometheus.NewRegistry()),
+				})
+
+				require.ErrorContains(t, pruner.pruneRun(t.Context(), start), tc.expectedErr)
+
+				found, err := store.Get("runs/default", &[]runStats{})
+				require.NoError(t, err)
+				assert.False(t, found)
+			})
+		}
+	})
+
+	t.Run("keeps statistics of configured number of runs", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		require.NoError(t, store.Set("runs/default", previous))
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return nil, nil
+			},
+			usage: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) (*Usage, error) {
+				dashboards := []DashboardReads{newMockDashboardReads("used", 100, 1)}
+				return &Usage{Dashboards: dashboards, LogCount: 100, ChunkLogCounts: []int{50, 50}}, nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:          mockClient,
+			Logger:           l,
+			Namespace:        "default",
+			Interval:         time.Hour,
+			Period:           24 * time.Hour,
+			Labels:           map[string]string{"app": "grafana"},
+			Dry:              true,
+			AnomalyDetection: &AnomalyDetectionConfig{MaxDrop: 0.9, Runs: 2},
+			History:          store,
+			Metrics:          NewMetrics(prometheus.NewRegistry()),
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+		var runs []runStats
+		_, err = store.Get("runs/default", &runs)
+		require.NoError(t, err)
+		assert.Equal(t, []runStats{
+			previous[1],
+			{Time: start, LogCount: 100, UsedCount: 1, ChunkLogCounts: []int{50, 50}},
+		}, runs)
+	})
+
+	t.Run("ignores runs before the configured time", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		require.NoError(t, store.Set("runs/default", previous))
+
+		var deleted []string
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{{UID: "uid1", Name: "unused", Namespace: "default"}}, nil
+			},
+			usage: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) (*Usage, error) {
+				dashboards := []DashboardReads{newMockDashboardReads("used", 10, 1)}
+				return &Usage{Dashboards: dashboards, LogCount: 10, ChunkLogCounts: []int{5, 5}}, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deleted = append(deleted, dashboard.Name)
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			AnomalyDetection: &AnomalyDetectionConfig{
+				MaxDrop:          0.5,
+				IgnoreRunsBefore: start.Add(-time.Minute),
+			},
+			History: store,
+			Metrics: NewMetrics(prometheus.NewRegistry()),
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+		assert.Equal(t, []string{"unused"}, deleted)
+
+		var runs []runStats
+		_, err = store.Get("runs/default", &runs)
+		require.NoError(t, err)
+		assert.Equal(t, []runStats{{Time: start, LogCount: 10, UsedCount: 1, ChunkLogCounts: []int{5, 5}}}, runs)
+	})
+}
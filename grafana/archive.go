@@ -0,0 +1,190 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// archivedAtAnnotation is the annotation that holds the time at which Frigg moved a dashboard to the archive folder.
+	archivedAtAnnotation = "frigg-archived-at"
+	// archivedFromAnnotation is the annotation that holds the UID of the folder from which Frigg moved a dashboard to
+	// the archive folder. The annotation is empty for dashboards that were archived from the root.
+	archivedFromAnnotation = "frigg-archived-from"
+)
+
+type folderResource struct {
+	Metadata folderResourceMetadata `json:"metadata"`
+	Spec     folderSpec             `json:"spec"`
+}
+
+type folderResourceMetadata struct {
+	Name string `json:"name"`
+}
+
+type folderPermissions struct {
+	Items []folderPermission `json:"items"`
+}
+
+type folderPermission struct {
+	Role       string `json:"role,omitempty"`
+	Permission int    `json:"permission"`
+}
+
+// CreateFolder creates a folder at the root of namespace with name as its UID and with title.
+//
+// CreateFolder uses the Grafana HTTP API endpoint POST /apis/folder.grafana.app/v1beta1/namespaces/:namespace/folders.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/folder/.
+func (c *Client) CreateFolder(ctx context.Context, namespace, name, title string) error {
+	u := c.endpoint.JoinPath("apis", "folder.grafana.app", "v1beta1", "namespaces", namespace, "folders")
+	folder := folderResource{
+		Metadata: folderResourceMetadata{Name: name},
+		Spec:     folderSpec{Title: title},
+	}
+
+	if err := c.sendJSON(ctx, http.MethodPost, u, 0, folder, nil); err != nil {
+		return errors.Wrapf(err, "creating folder %s", name)
+	}
+
+	return nil
+}
+
+// RestrictFolder removes all permissions of the folder with uid in namespace so that only organisation admins can
+// access the folder and its dashboards.
+//
+// RestrictFolder uses the Grafana HTTP API endpoint POST /api/folders/:uid/permissions, which replaces the permissions
+// of the folder. See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/folder_permissions/.
+//
+//nolint:lll
+func (c *Client) RestrictFolder(ctx context.Context, namespace, uid string) error {
+	id, err := orgID(namespace)
+	if err != nil {
+		return err
+	}
+
+	u := c.endpoint.JoinPath("api", "folders", uid, "permissions")
+	if err := c.sendJSON(ctx, http.MethodPost, u, id, folderPermissions{Items: []folderPermission{}}, nil); err != nil {
+		return errors.Wrapf(err, "restricting permissions of folder %s", uid)
+	}
+
+	return nil
+}
+
+// ArchiveDashboard moves the dashboard with name in namespace to folder. ArchiveDashboard records archivedAt in the
+// dashboard's frigg-archived-at annotation and the folder that the dashboard was moved from in its frigg-archived-from
+// annotation.
+//
+// ArchiveDashboard reads the dashboard with the Grafana HTTP API endpoint GET
+// /apis/dashboard.grafana.app/v1beta1/namespaces/:namespace/dashboards/:uid and writes it back with PUT to the same
+// endpoint. The dashboard is written back as it was read, apart from its folder and annotation, so that Grafana
+// rejects the update if the dashboard was changed in the meantime.
+func (c *Client) ArchiveDashboard(ctx context.Context, namespace, name, folder string, archivedAt time.Time) error {
+	if name == "" {
+		return errors.New("dashboard name must not be empty")
+	}
+
+	u := c.endpoint.JoinPath("apis", "dashboard.grafana.app", "v1beta1", "namespaces", namespace, "dashboards", name)
+
+	var dashboard map[string]any
+	if err := c.getJSON(ctx, u, 0, &dashboard); err != nil {
+		return errors.Wrap(err, "getting dashboard")
+	}
+
+	metadata, ok := dashboard["metadata"].(map[string]any)
+	if !ok {
+		return errors.New("dashboard has no metadata")
+	}
+
+	annotations, ok := metadata["annotations"].(map[string]any)
+	if !ok {
+		annotations = make(map[string]any)
+		metadata["annotations"] = annotations
+	}
+	// Dashboards at the root have no folder annotation.
+	annotations[archivedFromAnnotation] = ""
+	if from, isString := annotations[folderAnnotation].(string); isString {
+		annotations[archivedFromAnnotation] = from
+	}
+	annotations[folderAnnotation] = folder
+	annotations[archivedAtAnnotation] = archivedAt.UTC().Format(time.RFC3339)
+
+	if err := c.sendJSON(ctx, http.MethodPut, u, 0, dashboard, nil); err != nil {
+		return errors.Wrap(err, "updating dashboard")
+	}
+
+	return nil
+}
+
+// archivedAt returns the time at which dashboard was archived. The returned bool is false if dashboard is not in the
+// archive folder or if dashboards are not archived.
+//
+// A dashboard in the archive folder without a valid frigg-archived-at annotation, e.g., because a user moved it there,
+// is considered archived when it was last modified.
+func (d *DashboardPruner) archivedAt(dashboard *Dashboard) (time.Time, bool) {
+	if d.archive == nil || dashboard.Folder != d.archive.Folder {
+		return time.Time{}, false
+	}
+
+	if archivedAt, err := time.Parse(time.RFC3339, dashboard.Annotations[archivedAtAnnotation]); err == nil {
+		return archivedAt, true
+	}
+
+	return dashboard.LastModified(), true
+}
+
+// originalFolder returns the UID of the folder that dashboard was in before it was archived, or the UID of its current
+// folder if it was not archived by Frigg. originalFolder returns an empty string for dashboards at the root.
+func (d *DashboardPruner) originalFolder(dashboard *Dashboard) string {
+	if _, archived := d.archivedAt(dashboard); !archived {
+		return dashboard.Folder
+	}
+
+	if from, ok := dashboard.Annotations[archivedFromAnnotation]; ok {
+		return from
+	}
+
+	return dashboard.Folder
+}
+
+// archivedUntil returns the time until which dashboard is kept in the archive folder. The returned bool is false if
+// dashboard is not archived or if its archive period has ended.
+func (d *DashboardPruner) archivedUntil(dashboard *Dashboard, start time.Time) (time.Time, bool) {
+	archivedAt, archived := d.archivedAt(dashboard)
+	if !archived {
+		return time.Time{}, false
+	}
+
+	until := archivedAt.Add(d.archive.Period)
+
+	return until, start.Before(until)
+}
+
+// ensureArchiveFolder creates the archive folder with restricted permissions unless it is among folders, in which case
+// its permissions are left as they are. ensureArchiveFolder adds a folder that it creates to folders.
+func (d *DashboardPruner) ensureArchiveFolder(ctx context.Context, folders map[string]Folder) error {
+	if _, ok := folders[d.archive.Folder]; ok {
+		return nil
+	}
+
+	if err := d.grafana.CreateFolder(ctx, d.namespace, d.archive.Folder, d.archive.Title); err != nil {
+		return fmt.Errorf("creating archive folder: %w", err)
+	}
+
+	if err := d.grafana.RestrictFolder(ctx, d.namespace, d.archive.Folder); err != nil {
+		return fmt.Errorf("restricting archive folder: %w", err)
+	}
+
+	d.logger.Info(
+		"Created archive folder",
+		slog.String("folder", d.archive.Folder),
+		slog.String("title", d.archive.Title),
+	)
+	folders[d.archive.Folder] = Folder{Name: d.archive.Folder, Title: d.archive.Title}
+
+	return nil
+}
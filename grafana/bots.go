@@ -0,0 +1,128 @@
+package grafana
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Bot is a user whose reads do not count towards dashboard usage as the user reads dashboards in bulk, e.g., a script
+// or crawler that iterates over every dashboard.
+type Bot struct {
+	User string
+	// Reason why the user was detected as a bot, including the numbers that exceeded the configured maximum.
+	Reason string
+}
+
+// dashboardRead is a single read of a dashboard. user is empty if the read cannot be attributed to a user. query is
+// true if the read is a datasource query sent by the dashboard rather than a view of the dashboard.
+type dashboardRead struct {
+	key   DashboardKey
+	user  string
+	time  time.Time
+	query bool
+}
+
+// detectBots returns the users of reads that read dashboards in bulk according to config, sorted by user. Reads without
+// a user name, reads by ignored users and datasource queries are never attributed to a bot, as a single view of a
+// dashboard sends many queries.
+//
+// The percentage of dashboards that a user reads is relative to the number of distinct dashboards read by any user,
+// including ignored users, as Frigg only sees dashboards that are read.
+func detectBots(reads []dashboardRead, ignoredUsers *userFilter, config *BotDetectionConfig) []Bot {
+	if config == nil {
+		return nil
+	}
+
+	dashboards := make(map[DashboardKey]struct{})
+	readsByUser := make(map[string][]dashboardRead)
+	for _, read := range reads {
+		if read.query {
+			continue
+		}
+		dashboards[read.key] = struct{}{}
+
+		if read.user == "" || ignoredUsers.ignored(read.user) {
+			continue
+		}
+
+		readsByUser[read.user] = append(readsByUser[read.user], read)
+	}
+
+	var bots []Bot
+	for user, userReads := range readsByUser {
+		if reason, ok := config.detect(userReads, len(dashboards)); ok {
+			bots = append(bots, Bot{User: user, Reason: reason})
+		}
+	}
+
+	sort.Slice(bots, func(i, j int) bool {
+		return bots[i].User < bots[j].User
+	})
+
+	return bots
+}
+
+// detect returns the reason why the user of reads is a bot. total is the number of distinct dashboards read by any
+// user. The returned bool is false if the user is not a bot.
+func (c *BotDetectionConfig) detect(reads []dashboardRead, total int) (string, bool) {
+	if c.MaxDashboardPercentage != nil && total > 0 {
+		distinct := make(map[DashboardKey]struct{})
+		for _, read := range reads {
+			distinct[read.key] = struct{}{}
+		}
+
+		percentage := float64(len(distinct)) / float64(total) * 100
+		if percentage > *c.MaxDashboardPercentage {
+			return fmt.Sprintf(
+				"read %d of %d dashboards (%.1f%%), which exceeds the maximum of %.1f%%",
+				len(distinct),
+				total,
+				percentage,
+				*c.MaxDashboardPercentage,
+			), true
+		}
+	}
+
+	if c.MaxDashboards > 0 {
+		if most := mostDashboardsInWindow(reads, c.Window); most > c.MaxDashboards {
+			return fmt.Sprintf(
+				"read %d distinct dashboards within %s, which exceeds the maximum of %d",
+				most,
+				c.Window,
+				c.MaxDashboards,
+			), true
+		}
+	}
+
+	return "", false
+}
+
+// mostDashboardsInWindow returns the highest number of distinct dashboards in reads that were read within window of
+// each other.
+func mostDashboardsInWindow(reads []dashboardRead, window time.Duration) int {
+	sorted := make([]dashboardRead, len(reads))
+	copy(sorted, reads)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].time.Before(sorted[j].time)
+	})
+
+	// counts holds the number of reads of each dashboard between sorted[first] and the current read.
+	counts := make(map[DashboardKey]int)
+	most, first := 0, 0
+	for _, read := range sorted {
+		counts[read.key]++
+
+		for read.time.Sub(sorted[first].time) > window {
+			counts[sorted[first].key]--
+			if counts[sorted[first].key] == 0 {
+				delete(counts, sorted[first].key)
+			}
+			first++
+		}
+
+		most = max(most, len(counts))
+	}
+
+	return most
+}
@@ -0,0 +1,97 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectBots(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	read := func(name, user string, offset time.Duration) dashboardRead {
+		return dashboardRead{
+			key:  DashboardKey{name: name, namespace: "default"},
+			user: user,
+			time: start.Add(offset),
+		}
+	}
+	percentage := func(p float64) *float64 {
+		return &p
+	}
+
+	reads := []dashboardRead{
+		// crawler reads four dashboards within a minute.
+		read("a", "crawler", 0),
+		read("b", "crawler", 20*time.Second),
+		read("c", "crawler", 40*time.Second),
+		read("d", "crawler", time.Minute),
+		// browser reads four dashboards, but never more than two within a minute.
+		read("a", "browser", time.Hour),
+		read("b", "browser", time.Hour+30*time.Second),
+		read("c", "browser", 2*time.Hour),
+		read("d", "browser", 2*time.Hour+30*time.Second),
+		// reader reads one dashboard many times.
+		read("a", "reader", 0),
+		read("a", "reader", time.Second),
+		read("a", "reader", 2*time.Second),
+		read("e", "", 0),
+		read("a", "ignored", 0),
+		read("b", "ignored", 0),
+		read("c", "ignored", 0),
+	}
+
+	tests := map[string]struct {
+		config       *BotDetectionConfig
+		ignoredUsers *userFilter
+		expectedBots []Bot
+	}{
+		"detects nothing without config": {
+			config:       nil,
+			ignoredUsers: newUserFilter(nil, nil),
+			expectedBots: nil,
+		},
+		"detects users that read too many dashboards within window": {
+			config:       &BotDetectionConfig{MaxDashboards: 2, Window: time.Minute},
+			ignoredUsers: newUserFilter([]string{"ignored"}, nil),
+			expectedBots: []Bot{
+				{User: "crawler", Reason: "read 4 distinct dashboards within 1m0s, which exceeds the maximum of 2"},
+			},
+		},
+		"detects ignored users if they are not ignored": {
+			config:       &BotDetectionConfig{MaxDashboards: 2, Window: time.Minute},
+			ignoredUsers: newUserFilter(nil, nil),
+			expectedBots: []Bot{
+				{User: "crawler", Reason: "read 4 distinct dashboards within 1m0s, which exceeds the maximum of 2"},
+				{User: "ignored", Reason: "read 3 distinct dashboards within 1m0s, which exceeds the maximum of 2"},
+			},
+		},
+		"detects users that read too large a share of dashboards": {
+			config:       &BotDetectionConfig{MaxDashboardPercentage: percentage(75)},
+			ignoredUsers: newUserFilter([]string{"ignored"}, nil),
+			expectedBots: []Bot{
+				{User: "browser", Reason: "read 4 of 5 dashboards (80.0%), which exceeds the maximum of 75.0%"},
+				{User: "crawler", Reason: "read 4 of 5 dashboards (80.0%), which exceeds the maximum of 75.0%"},
+			},
+		},
+		"detects nothing below the maximums": {
+			config: &BotDetectionConfig{
+				MaxDashboards:          4,
+				Window:                 time.Minute,
+				MaxDashboardPercentage: percentage(80),
+			},
+			ignoredUsers: newUserFilter([]string{"ignored"}, nil),
+			expectedBots: nil,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expectedBots, detectBots(reads, tt.ignoredUsers, tt.config))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/LasseHels/frigg/approvals"
+	"github.com/LasseHels/frigg/backup"
+)
+
+// approvalRuns holds the deletion candidates of prune runs along with their approvals.
+type approvalRuns interface {
+	Open(namespace string, now time.Time) ([]approvals.Run, error)
+	Propose(run *approvals.Run) error
+	Complete(id string, deleted, dropped []string, now time.Time) error
+	Record(id string, deleted []string) error
+}
+
+// candidateReview indexes the open approval runs of a namespace by dashboard name and collects the candidates of the
+// current prune run.
+type candidateReview struct {
+	runs     []approvals.Run
+	pending  map[string]*approvals.Run
+	approved map[string]*approvals.Run
+	// deleted holds the names of the deleted dashboards of each approved run, including those that an earlier prune run
+	// deleted before it failed.
+	deleted map[string][]string
+	// candidates are the new candidates of the current prune run.
+	candidates []approvals.Candidate
+}
+
+func newCandidateReview(runs []approvals.Run) *candidateReview {
+	r := &candidateReview{
+		runs:     runs,
+		pending:  make(map[string]*approvals.Run),
+		approved: make(map[string]*approvals.Run),
+		deleted:  make(map[string][]string),
+	}
+
+	for i := range runs {
+		run := &runs[i]
+		if run.Status == approvals.StatusApproved && len(run.Deleted) > 0 {
+			r.deleted[run.ID] = slices.Clone(run.Deleted)
+		}
+		for _, candidate := range run.Candidates {
+			if run.Status == approvals.StatusPending {
+				r.pending[candidate.Name] = run
+			} else {
+				r.approved[candidate.Name] = run
+			}
+		}
+	}
+
+	return r
+}
+
+// reviewed returns true if the dashboard is a candidate of a pending or approved run, including candidates that the
+// approver excluded.
+func (r *candidateReview) reviewed(name string) bool {
+	_, pending := r.pending[name]
+	_, approved := r.approved[name]
+
+	return pending || approved
+}
+
+// propose adds dashboard to the candidates of the current prune run.
+func (r *candidateReview) propose(dashboard *backup.Dashboard) {
+	r.candidates = append(r.candidates, approvals.Candidate{
+		Name:       dashboard.Name,
+		UID:        dashboard.UID,
+		Title:      dashboard.Title,
+		Folder:     dashboard.FolderTitle,
+		Owner:      dashboard.Owner,
+		LastViewed: dashboard.LastViewed,
+		Hash:       dashboard.Hash(),
+	})
+}
+
+// reviewCandidates returns the review of the open approval runs of the namespace at the start of the prune run. Runs
+// that have awaited approval for too long expire.
+func (d *DashboardPruner) reviewCandidates(start time.Time) (*candidateReview, error) {
+	if d.approvalRuns == nil || d.dry {
+		return newCandidateReview(nil), nil
+	}
+
+	runs, err := d.approvalRuns.Open(d.namespace, start)
+	if err != nil {
+		return nil, fmt.Errorf("fetching approval runs: %w", err)
+	}
+
+	return newCandidateReview(runs), nil
+}
+
+// deleteApprovedCandidate deletes the dashboard if it is a candidate of an approved run. deleteApprovedCandidate
+// returns true if the dashboard was deleted.
+//
+// A candidate that was viewed or changed after its run was created is dropped instead, as the approver approved the
+// deletion of an unused dashboard in the state it had then.
+func (d *DashboardPruner) deleteApprovedCandidate(
+	ctx context.Context,
+	logger *slog.Logger,
+	dashboard *backup.Dashboard,
+	review *candidateReview,
+) (bool, error) {
+	if run, ok := review.pending[dashboard.Name]; ok {
+		logger.Info("Skipping dashboard whose deletion awaits approval", slog.String("run", run.ID))
+		return false, nil
+	}
+
+	run, ok := review.approved[dashboard.Name]
+	if !ok {
+		return false, nil
+	}
+
+	candidate, approved := run.Approved(dashboard.Name)
+	if !approved {
+		logger.Info("Skipping dashboard excluded from approved deletion", slog.String("run", run.ID))
+		return false, nil
+	}
+
+	if dashboard.LastViewed.After(run.Created) {
+		logger.Info(
+			"Dropping dashboard that was viewed after it became a deletion candidate",
+			slog.String("run", run.ID),
+			slog.String("last_viewed", dashboard.LastViewed.UTC().Format(time.RFC3339)),
+		)
+		return false, nil
+	}
+
+	if candidate.Hash != dashboard.Hash() {
+		logger.Info("Dropping dashboard that changed after it became a deletion candidate", slog.String("run", run.ID))
+		return false, nil
+	}
+
+	logger.Info("Deleting dashboard whose deletion was approved", slog.String("run", run.ID))
+	if err := d.grafana.DeleteDashboard(ctx, dashboard); err != nil {
+		return false, fmt.Errorf("deleting approved dashboard %s: %w", dashboard.UID, err)
+	}
+	logger.Info("Deleted dashboard whose deletion was approved", slog.String("run", run.ID))
+	review.deleted[run.ID] = append(review.deleted[run.ID], dashboard.Name)
+
+	return true, nil
+}
+
+// finishCandidateReview records the candidates of the prune run that started at start and completes the approved
+// runs. Approved candidates that were not deleted, e.g., as they were used, are recorded as dropped.
+func (d *DashboardPruner) finishCandidateReview(review *candidateReview, start time.Time) error {
+	if d.approvalRuns == nil || d.dry {
+		return nil
+	}
+
+	if len(review.candidates) > 0 {
+		run := &approvals.Run{
+			ID:         approvals.RunID(d.namespace, start.Format(runIDLayout)),
+			Namespace:  d.namespace,
+			Created:    start,
+			Candidates: review.candidates,
+		}
+		if err := d.approvalRuns.Propose(run); err != nil {
+			return fmt.Errorf("recording deletion candidates: %w", err)
+		}
+		d.logger.Info(
+			"Recorded deletion candidates awaiting approval",
+			slog.String("run", run.ID),
+			slog.Int("candidate_count", len(run.Candidates)),
+		)
+	}
+
+	for i := range review.runs {
+		run := &review.runs[i]
+		if run.Status != approvals.StatusApproved {
+			continue
+		}
+
+		deleted := review.deleted[run.ID]
+		var dropped []string
+		for _, candidate := range run.Candidates {
+			if _, approved := run.Approved(candidate.Name); approved && !slices.Contains(deleted, candidate.Name) {
+				dropped = append(dropped, candidate.Name)
+			}
+		}
+
+		if err := d.approvalRuns.Complete(run.ID, deleted, dropped, start); err != nil {
+			return fmt.Errorf("completing approval run %s: %w", run.ID, err)
+		}
+		d.logger.Info(
+			"Completed approved deletion run",
+			slog.String("run", run.ID),
+			slog.Int("deleted_count", len(deleted)),
+			slog.Int("dropped_count", len(dropped)),
+		)
+	}
+
+	return nil
+}
+
+// abandonCandidateReview records the approved candidates that a failed prune run deleted without completing their runs.
+// The runs are completed by a later prune run, which would otherwise report the deleted candidates as dropped.
+func (d *DashboardPruner) abandonCandidateReview(review *candidateReview) {
+	for i := range review.runs {
+		run := &review.runs[i]
+		deleted := review.deleted[run.ID]
+		if run.Status != approvals.StatusApproved || len(deleted) == 0 {
+			continue
+		}
+
+		if err := d.approvalRuns.Record(run.ID, deleted); err != nil {
+			d.logger.Error(
+				"Failed to record deleted dashboards of approval run",
+				slog.String("run", run.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package grafana
 
-import "time"
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 type Config struct {
 	Endpoint string `yaml:"endpoint" validate:"required,url"`
@@ -10,18 +16,199 @@ type PruneConfig struct {
 	Dry            bool              `yaml:"dry"`
 	Interval       time.Duration     `yaml:"interval"`
 	IgnoredUsers   []string          `yaml:"ignored_users"`
+	Ignore         *IgnoreConfig     `yaml:"ignore"`
 	Period         time.Duration     `yaml:"period" validate:"required"`
 	Labels         map[string]string `yaml:"labels" validate:"required"`
 	LowerThreshold int               `yaml:"lower_threshold" validate:"min=0"`
 	Skip           *SkipConfig       `yaml:"skip"`
+	Include        *IncludeConfig    `yaml:"include"`
 	MaxDeletions   *int              `yaml:"max_deletions" validate:"omitempty,min=1"`
+	// MaxDeletionPercentage must be greater than 0 and at most 100.
+	MaxDeletionPercentage *float64 `yaml:"max_deletion_percentage" validate:"omitempty,gt=0,lte=100"`
+	// MinReads is the minimum number of reads in the period for a dashboard to be used. If zero, a single read makes a
+	// dashboard used.
+	MinReads int `yaml:"min_reads" validate:"min=0"`
+	// MinUsers is the minimum number of distinct users that must have read a dashboard in the period for it to be
+	// used. If zero, the number of users is not considered.
+	MinUsers int `yaml:"min_users" validate:"min=0"`
+	// QueryReads makes Frigg count the datasource queries that dashboards send as reads.
+	QueryReads bool `yaml:"query_reads"`
+	// EditWindow is the duration after saving or creating a dashboard in which the dashboard is considered used.
+	EditWindow time.Duration `yaml:"edit_window" validate:"min=0"`
 	// ChunkSize has a minimum value of 10 minutes (600000000000 nanoseconds).
 	// 10 minutes was chosen to avoid overwhelming the Loki API with a flurry of requests.
-	ChunkSize time.Duration `yaml:"chunk_size" validate:"omitempty,min=600000000000"`
+	ChunkSize        time.Duration           `yaml:"chunk_size" validate:"omitempty,min=600000000000"`
+	AnomalyDetection *AnomalyDetectionConfig `yaml:"anomaly_detection"`
+	Safety           *SafetyConfig           `yaml:"safety"`
+	Policy           *PolicyConfig           `yaml:"policy"`
+	BotDetection     *BotDetectionConfig     `yaml:"bot_detection"`
+	Protect          *ProtectConfig          `yaml:"protect"`
+	Approval         *ApprovalConfig         `yaml:"approval"`
+	// Action determines what happens to unused dashboards. Archive must be set if Action is ActionArchive.
+	Action  Action         `yaml:"action" validate:"required,oneof=delete archive"`
+	Archive *ArchiveConfig `yaml:"archive" validate:"required_if=Action archive,excluded_unless=Action archive"`
+	// Tombstone makes Frigg replace deleted dashboards with tombstones.
+	Tombstone *TombstoneConfig `yaml:"tombstone"`
+}
+
+// Action is what happens to unused dashboards.
+type Action string
+
+const (
+	// ActionDelete deletes unused dashboards.
+	ActionDelete Action = "delete"
+	// ActionArchive moves unused dashboards to an archive folder and deletes them once they have been archived for
+	// ArchiveConfig.Period.
+	ActionArchive Action = "archive"
+)
+
+const (
+	// DefaultArchiveFolder is the default ArchiveConfig.Folder.
+	DefaultArchiveFolder = "frigg-archive"
+	// DefaultArchiveTitle is the default ArchiveConfig.Title.
+	DefaultArchiveTitle = "Frigg Archive"
+)
+
+// ArchiveConfig configures the archive folder that unused dashboards are moved to.
+type ArchiveConfig struct {
+	// Folder is the UID of the archive folder. If the folder does not exist, Frigg creates it at the root with Title
+	// and restricts its permissions to organisation admins. Defaults to DefaultArchiveFolder.
+	Folder string `yaml:"folder"`
+	// Title of the archive folder. Defaults to DefaultArchiveTitle.
+	Title string `yaml:"title"`
+	// Period is how long a dashboard stays in the archive folder before it is backed up and deleted. Period has a
+	// minimum value of 1 hour (3600000000000 nanoseconds).
+	Period time.Duration `yaml:"period" validate:"required,min=3600000000000"`
+}
+
+// TombstoneConfig configures the tombstones that Frigg creates in place of deleted dashboards.
+type TombstoneConfig struct {
+	// Period is how long a tombstone is kept before Frigg deletes it. Period has a minimum value of 1 hour
+	// (3600000000000 nanoseconds).
+	Period time.Duration `yaml:"period" validate:"required,min=3600000000000"`
+}
+
+// IgnoreConfig holds rules that ignore the reads of users in addition to PruneConfig.IgnoredUsers.
+type IgnoreConfig struct {
+	// UserPatterns are regular expressions that are matched against user names, e.g., "^sa-".
+	UserPatterns []string `yaml:"user_patterns" validate:"dive,required"`
+	// ServiceAccounts ignores the reads of all service accounts.
+	ServiceAccounts bool `yaml:"service_accounts"`
+	// Teams whose members' reads are ignored.
+	Teams []string `yaml:"teams" validate:"dive,required"`
+	// Roles whose users' reads are ignored. Each role is one of "Admin", "Editor", "Viewer" and "None".
+	Roles []string `yaml:"roles" validate:"dive,oneof=Admin Editor Viewer None"`
+}
+
+// CompileUserPatterns compiles the user patterns of c.
+func (c *IgnoreConfig) CompileUserPatterns() ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(c.UserPatterns))
+	for _, pattern := range c.UserPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compiling user pattern %q", pattern)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return patterns, nil
+}
+
+// BotDetectionConfig makes Frigg detect users that read dashboards in bulk, e.g., scripts and crawlers, and exclude
+// their reads from dashboard usage. At least one of MaxDashboards and MaxDashboardPercentage must be set.
+type BotDetectionConfig struct {
+	// MaxDashboards is the maximum number of distinct dashboards that a user may read within Window.
+	MaxDashboards int `yaml:"max_dashboards" validate:"required_with=Window,omitempty,min=1"`
+	// Window is required if MaxDashboards is set.
+	Window time.Duration `yaml:"window" validate:"required_with=MaxDashboards,omitempty,gt=0"`
+	// MaxDashboardPercentage is the maximum percentage of the dashboards read in the period that a user may read. It
+	// must be greater than 0 and at most 100.
+	MaxDashboardPercentage *float64 `yaml:"max_dashboard_percentage" validate:"required_without=MaxDashboards,omitempty,gt=0,lte=100"` //nolint:lll
+}
+
+// ProtectConfig protects unused dashboards that are still referenced from being deleted.
+type ProtectConfig struct {
+	// References protects dashboards that are linked from used dashboards, included in a playlist or set as a home
+	// dashboard.
+	References bool `yaml:"references"`
+	// ReferenceDepth is how many links away from a used or otherwise referenced dashboard a dashboard is protected by
+	// References. Defaults to 1, which only protects dashboards that such dashboards link to directly.
+	ReferenceDepth int `yaml:"reference_depth" validate:"omitempty,min=1"`
+	// AlertRules protects dashboards that alert rules link to.
+	AlertRules bool `yaml:"alert_rules"`
+	// Starred protects dashboards that any user has starred.
+	Starred bool `yaml:"starred"`
+	// PublicDashboards protects dashboards that are published as enabled public dashboards.
+	PublicDashboards bool `yaml:"public_dashboards"`
+	// Snapshots protects dashboards that are shared as snapshots that have not expired.
+	Snapshots bool `yaml:"snapshots"`
+}
+
+// ApprovalConfig makes the deletion of unused dashboards in some namespaces subject to approval.
+type ApprovalConfig struct {
+	// Namespaces in which deletions must be approved.
+	Namespaces []string `yaml:"namespaces" validate:"required,min=1,dive,required"`
+	// Expiry is how long a run may await approval before it expires. The candidates of an expired run are not deleted.
+	// Expiry has a minimum value of 1 hour (3600000000000 nanoseconds).
+	Expiry time.Duration `yaml:"expiry" validate:"omitempty,min=3600000000000"`
+}
+
+// Requires reports whether deletions in namespace must be approved.
+func (c *ApprovalConfig) Requires(namespace string) bool {
+	return slices.Contains(c.Namespaces, namespace)
+}
+
+type AnomalyDetectionConfig struct {
+	// MaxDrop is the fraction by which the log count, used dashboard count or lowest chunk log count of a run may drop
+	// compared to the average of previous runs. MaxDrop must be greater than 0 and less than 1.
+	MaxDrop float64 `yaml:"max_drop" validate:"gt=0,lt=1"`
+	// Runs is the number of previous runs that a run is compared with. Defaults to 10.
+	Runs int `yaml:"runs" validate:"omitempty,min=1"`
+	// IgnoreRunsBefore excludes runs that started before it from the comparison, so that a genuine and permanent drop
+	// in usage can be accepted without editing the state file. Runs are not ignored if IgnoreRunsBefore is zero.
+	IgnoreRunsBefore time.Time `yaml:"ignore_runs_before"`
+}
+
+type SafetyConfig struct {
+	Canaries  []CanaryConfig   `yaml:"canaries" validate:"dive"`
+	Retention *RetentionConfig `yaml:"retention"`
+}
+
+const (
+	// RetentionActionAbort aborts runs whose period exceeds the range that Loki holds logs for.
+	RetentionActionAbort = "abort"
+	// RetentionActionClamp clamps the period of runs to the range that Loki holds logs for.
+	RetentionActionClamp = "clamp"
+)
+
+// RetentionConfig makes Frigg check that Loki holds Grafana logs for the entire period before analysing dashboard
+// usage.
+type RetentionConfig struct {
+	// Action to take if Loki does not hold Grafana logs for the entire period. Either "abort" or "clamp".
+	Action string `yaml:"action" validate:"required,oneof=abort clamp"`
+	// Tolerance by which the oldest log may be younger than the start of the period. Defaults to one hour.
+	Tolerance time.Duration `yaml:"tolerance" validate:"omitempty,min=0"`
+}
+
+// CanaryConfig identifies a dashboard that is read regularly, e.g., by a synthetic monitor, and must therefore always
+// be used.
+type CanaryConfig struct {
+	Namespace string `yaml:"namespace" validate:"required"`
+	Name      string `yaml:"name" validate:"required"`
 }
 
 type SkipConfig struct {
-	Tags *SkipTagsConfig `yaml:"tags" validate:"required"`
+	Tags *SkipTagsConfig `yaml:"tags" validate:"required_without=Folders"`
+	// Folders whose dashboards, including the dashboards of nested subfolders, are never deleted. Each folder is
+	// either a folder UID or a title path, e.g., "Shared/Team A".
+	Folders []string `yaml:"folders" validate:"omitempty,min=1,dive,required"`
+}
+
+// IncludeConfig limits pruning to a subset of dashboards.
+type IncludeConfig struct {
+	// Folders outside which dashboards are never deleted. Dashboards of nested subfolders are included. Each folder is
+	// either a folder UID or a title path, e.g., "Sandbox/Experiments".
+	Folders []string `yaml:"folders" validate:"required,min=1,dive,required"`
 }
 
 type SkipTagsConfig struct {
@@ -4,19 +4,80 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/notify"
 )
 
+// runIDLayout is the time layout used to generate the ID of a prune run.
+const runIDLayout = "20060102T150405Z"
+
+// defaultRetentionTolerance is the default tolerance by which the oldest Grafana log may be younger than the start of
+// the period.
+const defaultRetentionTolerance = time.Hour
+
 type grafanaClient interface {
 	UsedDashboards(
 		ctx context.Context,
 		labels map[string]string,
 		r time.Duration,
 		opts UsedDashboardsOptions,
-	) ([]DashboardReads, error)
+	) (*Usage, error)
+	OldestLogTime(
+		ctx context.Context,
+		labels map[string]string,
+		r time.Duration,
+		chunkSize time.Duration,
+	) (time.Time, bool, error)
 	AllDashboards(ctx context.Context, namespace string) ([]Dashboard, error)
-	DeleteDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error
+	AllFolders(ctx context.Context, namespace string) ([]Folder, error)
+	OrgUsers(ctx context.Context, namespace string) ([]OrgUser, error)
+	ServiceAccounts(ctx context.Context, namespace string) ([]string, error)
+	TeamMembers(ctx context.Context, namespace, name string) ([]string, bool, error)
+	Playlists(ctx context.Context, namespace string) ([]Playlist, error)
+	HomeDashboards(ctx context.Context, namespace string) ([]HomeDashboard, error)
+	AlertRules(ctx context.Context, namespace string) ([]AlertRule, error)
+	StarredDashboards(ctx context.Context, namespace string) ([]StarredDashboard, error)
+	PublicDashboards(ctx context.Context, namespace string) ([]PublicDashboard, error)
+	Snapshots(ctx context.Context, namespace string) ([]Snapshot, error)
+	DeleteDashboard(ctx context.Context, dashboard *backup.Dashboard) error
+	DeleteBackedUpDashboard(ctx context.Context, namespace, name string) error
+	CreateFolder(ctx context.Context, namespace, name, title string) error
+	RestrictFolder(ctx context.Context, namespace, uid string) error
+	ArchiveDashboard(ctx context.Context, namespace, name, folder string, archivedAt time.Time) error
+	CreateDashboard(
+		ctx context.Context,
+		namespace, name, folder string,
+		annotations map[string]string,
+		spec any,
+	) error
+	ImportURL(namespace string) (string, error)
+}
+
+// notifier notifies the owners of dashboards about the deletion of their dashboards.
+type notifier interface {
+	Notify(ctx context.Context, event notify.Event, dashboards []backup.Dashboard) error
+}
+
+// snoozes holds the dashboards that their owners have snoozed, i.e., exempted from deletion for a while.
+type snoozes interface {
+	Snoozed(namespace string, now time.Time) (map[string]time.Time, error)
+}
+
+// backups is the storage backend that holds the backups of deleted dashboards.
+type backups interface {
+	BackUpDashboard(ctx context.Context, dashboard *backup.Dashboard) error
+	FinishRun(ctx context.Context, namespace, runID string) error
+	Proposals(ctx context.Context, namespace string) ([]backup.Proposal, error)
+	ResolveProposal(ctx context.Context, proposal *backup.Proposal) error
+	PurgeExpiredBackups(ctx context.Context, namespace string, now time.Time, dry bool) error
 }
 
 type DashboardPruner struct {
@@ -24,16 +85,62 @@ type DashboardPruner struct {
 	logger         *slog.Logger
 	namespace      string
 	interval       time.Duration
-	ignoredUsers   []string
 	period         time.Duration
 	labels         map[string]string
 	dry            bool
-	lowerThreshold int
+	reads          readOptions
+	minReads       int
+	minUsers       int
 	skipTags       []string
+	skipFolders    []string
+	includeFolders []string
 	maxDeletions   *int
 	chunkSize      time.Duration
+	backups        backups
+	deferDeletion  bool
+	safeguards     safeguardOptions
+	policy         *Policy
+	editWindow     time.Duration
+	protect        protectOptions
+	notifier       notifier
+	snoozes        snoozes
+	approvalRuns   approvalRuns
+	archive        *ArchiveConfig
+	tombstone      *TombstoneConfig
+	ownerFolders   map[string]string
+	// ownerFolderPatterns holds the folders of ownerFolders in a stable order.
+	ownerFolderPatterns []string
+	defaultOwner        string
+	metrics             *Metrics
+}
+
+// readOptions select the reads of dashboards that count as usage.
+type readOptions struct {
+	ignoredUsers        []string
+	ignoredUserPatterns []*regexp.Regexp
+	// ignoreServiceAccounts, ignoredTeams and ignoredRoles are resolved to users at the start of each run.
+	ignoreServiceAccounts bool
+	ignoredTeams          []string
+	ignoredRoles          []string
+	lowerThreshold        int
+	botDetection          *BotDetectionConfig
+	queryReads            bool
+}
+
+// safeguardOptions configure the checks that abort a prune run before it deletes any dashboard.
+type safeguardOptions struct {
+	// maxDeletionPercentage is nil if the percentage of dashboards that a run may delete is not limited.
+	maxDeletionPercentage *float64
+	anomalyDetection      *AnomalyDetectionConfig
+	history               history
+	canaries              []CanaryConfig
+	retention             *RetentionConfig
 }
 
+// NewDashboardPrunerOptions contains options for creating a DashboardPruner.
+//
+// MaxDeletionPercentage, AnomalyDetection, Canaries and Retention abort runs that would otherwise delete dashboards
+// based on incomplete usage. Dry runs are never aborted. Instead, DashboardPruner logs a warning.
 type NewDashboardPrunerOptions struct {
 	Grafana grafanaClient
 	Logger  *slog.Logger
@@ -57,14 +164,123 @@ type NewDashboardPrunerOptions struct {
 	Dry bool
 	// See UsedDashboardsOptions.LowerThreshold.
 	LowerThreshold int
+	// MinReads is the minimum number of reads in Period for a dashboard to be considered used. A dashboard with fewer
+	// reads is considered unused even though it has been read. If zero, a single read makes a dashboard used.
+	MinReads int
+	// MinUsers is the minimum number of distinct users that must have read a dashboard in Period for it to be
+	// considered used. Reads without a user name do not count toward MinUsers. If zero, the number of users is not
+	// considered.
+	MinUsers int
 	// SkipTags is a list of dashboard tags that cause dashboards to be skipped during pruning. If a dashboard has any
 	// of these tags, it will be skipped. If this slice is empty or nil, no dashboards will be skipped based on tags.
 	SkipTags []string
+	// SkipFolders are folders whose dashboards, including the dashboards of nested subfolders, are never deleted. Each
+	// folder is either a folder UID or a title path, e.g., "Shared/Team A".
+	SkipFolders []string
+	// IncludeFolders limits pruning to the dashboards of these folders and their nested subfolders. Each folder is either
+	// a folder UID or a title path. If empty, dashboards in all folders are pruned.
+	IncludeFolders []string
 	// MaxDeletions is the maximum number of dashboards to delete per pruning run. If nil, there is no limit.
 	MaxDeletions *int
 	// ChunkSize is the size of time chunks when querying Loki for dashboard usage logs.
 	// See also UsedDashboardsOptions.ChunkSize.
 	ChunkSize time.Duration
+	// Backups is the storage backend that dashboards are backed up to. DashboardPruner tells Backups when a prune run
+	// has finished so that Backups can group the run's backups, and purges expired backups after each run. Backups is
+	// required if DeferDeletion is true.
+	Backups backups
+	// DeferDeletion makes DashboardPruner propose the deletion of unused dashboards instead of deleting them. A
+	// dashboard is only deleted once its deletion proposal has been approved. See backups.Proposals.
+	//
+	// MaxDeletions limits the number of dashboards proposed per pruning run. Approved deletions are not limited.
+	DeferDeletion bool
+	// MaxDeletionPercentage is the maximum percentage of non-provisioned dashboards in Namespace that may be unused. If
+	// more dashboards are unused, DashboardPruner aborts the run before deleting any dashboard. A sudden increase in
+	// unused dashboards usually means that reads are no longer found in Loki, e.g., because a Grafana upgrade changed
+	// the format of its logs. If nil, there is no limit.
+	MaxDeletionPercentage *float64
+	// AnomalyDetection makes DashboardPruner compare the dashboard usage that each run finds with the usage found by
+	// previous runs. DashboardPruner aborts a run before deleting any dashboard if a chunk of the usage range contains
+	// no logs, which indicates a gap in log ingestion, or if the log count, used dashboard count or lowest chunk log
+	// count has dropped by more than AnomalyDetection.MaxDrop. If nil, runs are not compared.
+	AnomalyDetection *AnomalyDetectionConfig
+	// History stores the statistics of previous runs. History is required if AnomalyDetection is set.
+	History history
+	// Canaries are dashboards that are read regularly, e.g., by a synthetic monitor. DashboardPruner aborts a run before
+	// deleting any dashboard if a canary is not among the used dashboards, as that means that reads are not found in
+	// Loki, e.g., because the format of Grafana's logs or the labels of its log streams changed.
+	Canaries []CanaryConfig
+	// Retention makes DashboardPruner check that Loki holds Grafana logs for the entire Period before analysing
+	// dashboard usage. Otherwise, a dashboard that was only read before Loki's retention would wrongly appear unused.
+	// Depending on Retention.Action, DashboardPruner either aborts the run or clamps the period to the range that Loki
+	// holds logs for. If nil, the period is not checked.
+	Retention *RetentionConfig
+	// Policy decides whether to skip or force the deletion of dashboards. See PolicyConfig. If nil, no dashboards are
+	// skipped or forced by policy.
+	Policy *Policy
+	// BotDetection excludes the reads of users that read dashboards in bulk from dashboard usage. DashboardPruner logs
+	// each detected user, and the backups of the run list them. See UsedDashboardsOptions.BotDetection.
+	BotDetection *BotDetectionConfig
+	// IgnoredUserPatterns are regular expressions that ignore the reads of every user whose name matches any of them.
+	// See also UsedDashboardsOptions.IgnoredUserPatterns.
+	IgnoredUserPatterns []*regexp.Regexp
+	// IgnoreServiceAccounts ignores the reads of all service accounts of the organisation that Namespace belongs to.
+	IgnoreServiceAccounts bool
+	// IgnoredTeams are the names of teams whose members' reads are ignored. A team that does not exist is ignored
+	// with a warning.
+	IgnoredTeams []string
+	// IgnoredRoles are organisation roles, e.g., "Admin", whose users' reads are ignored.
+	IgnoredRoles []string
+	// QueryReads counts the datasource queries that dashboards send as usage. See UsedDashboardsOptions.QueryReads.
+	// Datasource queries count towards MinReads.
+	QueryReads bool
+	// EditWindow makes DashboardPruner consider dashboards that were saved or created within EditWindow before the start
+	// of a run as used, regardless of their reads. EditWindow is independent of Period. If zero, edits are not
+	// considered.
+	EditWindow time.Duration
+	// ProtectReferences makes DashboardPruner skip unused dashboards that are linked from used dashboards, included in
+	// a playlist or set as the home dashboard of the organisation or a team, as deleting them would break navigation.
+	ProtectReferences bool
+	// ReferenceDepth is how many links are followed from used dashboards, playlists and home dashboards when
+	// ProtectReferences is set. Defaults to 1, which only protects the dashboards that they link to directly.
+	ReferenceDepth int
+	// ProtectAlertRules makes DashboardPruner skip dashboards that alert rules link to through their __dashboardUid__
+	// annotation, even if the dashboards are unused, so that responders do not find a dead link during an incident. If
+	// ProtectReferences is also set, dashboards linked from these dashboards are protected as well.
+	ProtectAlertRules bool
+	// ProtectStarred makes DashboardPruner skip dashboards that any user has starred, even if the dashboards are unused.
+	ProtectStarred bool
+	// ProtectPublic makes DashboardPruner skip dashboards that are published as enabled public dashboards.
+	// Views of public dashboards are anonymous and do not produce the logs that Frigg counts as reads.
+	ProtectPublic bool
+	// ProtectSnapshots makes DashboardPruner skip dashboards that are shared as snapshots that have not expired.
+	ProtectSnapshots bool
+	// Notifier notifies the owners of dashboards when DashboardPruner proposes the deletion of their dashboards and
+	// after it has archived or deleted them. Owners are not notified if Notifier is nil.
+	Notifier notifier
+	// Snoozes makes DashboardPruner skip dashboards that their owners have snoozed, even if the dashboards are unused
+	// or their TTL has expired. Snoozes are not considered if Snoozes is nil.
+	Snoozes snoozes
+	// Approvals makes DashboardPruner record unused dashboards as deletion candidates instead of deleting them. The
+	// candidates of a run are deleted in the first run after the run has been approved, unless they were viewed or
+	// changed in the meantime. Approvals cannot be combined with DeferDeletion.
+	Approvals approvalRuns
+	// Archive makes DashboardPruner move unused dashboards to the archive folder instead of deleting them. Archived
+	// dashboards are backed up and deleted once they have been archived for Archive.Period, unless they are used by
+	// then. MaxDeletions limits the number of dashboards that are archived and deleted per run. Archive cannot be
+	// combined with DeferDeletion or Approvals. Dashboards are deleted right away if Archive is nil.
+	Archive *ArchiveConfig
+	// Tombstone makes DashboardPruner create a tombstone under the UID of each dashboard that it deletes. A tombstone
+	// is a dashboard with a single text panel that explains when and why the dashboard was deleted and links to its
+	// backup. Tombstones are never pruned and are deleted once they have been kept for Tombstone.Period. No tombstones
+	// are created if Tombstone is nil.
+	Tombstone *TombstoneConfig
+	// OwnerFolders maps folders to the owner of the dashboards in them. See DashboardPruner.ownerOf.
+	OwnerFolders map[string]string
+	// DefaultOwner is the owner of dashboards whose owner cannot be resolved otherwise.
+	DefaultOwner string
+	// Metrics is required if MaxDeletionPercentage, AnomalyDetection, Canaries or Retention is set.
+	Metrics *Metrics
 }
 
 func NewDashboardPruner(opts *NewDashboardPrunerOptions) *DashboardPruner {
@@ -73,19 +289,64 @@ func NewDashboardPruner(opts *NewDashboardPrunerOptions) *DashboardPruner {
 		slog.String("namespace", opts.Namespace),
 	)
 
+	referenceDepth := opts.ReferenceDepth
+	if referenceDepth == 0 {
+		referenceDepth = defaultReferenceDepth
+	}
+
 	return &DashboardPruner{
-		grafana:        opts.Grafana,
-		logger:         logger,
-		namespace:      opts.Namespace,
-		interval:       opts.Interval,
-		ignoredUsers:   opts.IgnoredUsers,
-		period:         opts.Period,
-		labels:         opts.Labels,
-		dry:            opts.Dry,
-		lowerThreshold: opts.LowerThreshold,
+		grafana:   opts.Grafana,
+		logger:    logger,
+		namespace: opts.Namespace,
+		interval:  opts.Interval,
+		period:    opts.Period,
+		labels:    opts.Labels,
+		dry:       opts.Dry,
+		reads: readOptions{
+			ignoredUsers:          opts.IgnoredUsers,
+			ignoredUserPatterns:   opts.IgnoredUserPatterns,
+			ignoreServiceAccounts: opts.IgnoreServiceAccounts,
+			ignoredTeams:          opts.IgnoredTeams,
+			ignoredRoles:          opts.IgnoredRoles,
+			lowerThreshold:        opts.LowerThreshold,
+			botDetection:          opts.BotDetection,
+			queryReads:            opts.QueryReads,
+		},
+		minReads:       opts.MinReads,
+		minUsers:       opts.MinUsers,
 		skipTags:       opts.SkipTags,
+		skipFolders:    opts.SkipFolders,
+		includeFolders: opts.IncludeFolders,
 		maxDeletions:   opts.MaxDeletions,
 		chunkSize:      opts.ChunkSize,
+		backups:        opts.Backups,
+		deferDeletion:  opts.DeferDeletion,
+		safeguards: safeguardOptions{
+			maxDeletionPercentage: opts.MaxDeletionPercentage,
+			anomalyDetection:      opts.AnomalyDetection,
+			history:               opts.History,
+			canaries:              opts.Canaries,
+			retention:             opts.Retention,
+		},
+		policy:     opts.Policy,
+		editWindow: opts.EditWindow,
+		protect: protectOptions{
+			references:     opts.ProtectReferences,
+			referenceDepth: referenceDepth,
+			alertRules:     opts.ProtectAlertRules,
+			starred:        opts.ProtectStarred,
+			public:         opts.ProtectPublic,
+			snapshots:      opts.ProtectSnapshots,
+		},
+		notifier:            opts.Notifier,
+		snoozes:             opts.Snoozes,
+		approvalRuns:        opts.Approvals,
+		archive:             opts.Archive,
+		tombstone:           opts.Tombstone,
+		ownerFolders:        opts.OwnerFolders,
+		ownerFolderPatterns: slices.Sorted(maps.Keys(opts.OwnerFolders)),
+		defaultOwner:        opts.DefaultOwner,
+		metrics:             opts.Metrics,
 	}
 }
 
@@ -116,6 +377,32 @@ func (d *DashboardPruner) tick(ctx context.Context) {
 }
 
 func (d *DashboardPruner) prune(ctx context.Context) error {
+	start := time.Now().UTC()
+	runID := start.Format(runIDLayout)
+
+	err := d.pruneRun(ctx, start)
+
+	if d.backups == nil {
+		return err
+	}
+
+	// The run is finished even if it failed so that the backups of dashboards that were deleted before the failure are
+	// not left behind.
+	if finishErr := d.backups.FinishRun(ctx, d.namespace, runID); finishErr != nil {
+		err = multierr.Append(err, fmt.Errorf("finishing run %s: %w", runID, finishErr))
+	}
+
+	if purgeErr := d.backups.PurgeExpiredBackups(ctx, d.namespace, start, d.dry); purgeErr != nil {
+		err = multierr.Append(err, fmt.Errorf("purging expired backups: %w", purgeErr))
+	}
+
+	return err
+}
+
+// pruneRun prunes the dashboards of the namespace once. pruneRun gathers the usage, policy decisions and protections of
+// the dashboards and aborts if a safeguard fails. It then decides for each dashboard whether it is prunable, see
+// prunable, and prunes it, see pruneDashboard.
+func (d *DashboardPruner) pruneRun(ctx context.Context, start time.Time) error {
 	d.logger.Info("Pruning Grafana dashboards")
 
 	all, err := d.grafana.AllDashboards(ctx, d.namespace)
@@ -125,20 +412,125 @@ func (d *DashboardPruner) prune(ctx context.Context) error {
 
 	d.logger.Info("Found all Grafana dashboards", slog.Int("count", len(all)))
 
+	folders, err := d.folders(ctx)
+	if err != nil {
+		return err
+	}
+
+	period, err := d.checkRetention(ctx, start)
+	if err != nil {
+		return err
+	}
+
+	ignoredUsers, err := d.resolveIgnoredUsers(ctx)
+	if err != nil {
+		return err
+	}
+
 	opts := UsedDashboardsOptions{
-		IgnoredUsers:   d.ignoredUsers,
-		LowerThreshold: d.lowerThreshold,
-		ChunkSize:      d.chunkSize,
+		IgnoredUsers:        ignoredUsers,
+		IgnoredUserPatterns: d.reads.ignoredUserPatterns,
+		LowerThreshold:      d.reads.lowerThreshold,
+		ChunkSize:           d.chunkSize,
+		BotDetection:        d.reads.botDetection,
+		QueryReads:          d.reads.queryReads,
+		Namespace:           d.namespace,
 	}
-	used, err := d.grafana.UsedDashboards(ctx, d.labels, d.period, opts)
+	usage, err := d.grafana.UsedDashboards(ctx, d.labels, period, opts)
 	if err != nil {
 		return fmt.Errorf("fetching used Grafana dashboards: %w", err)
 	}
 
-	d.logger.Info("Found used Grafana dashboards", slog.Int("count", len(used)))
-	usedDashboards := d.usedMap(used)
-	var deleted []string
-	var skippedDueToLimit int
+	d.logger.Info("Found used Grafana dashboards", slog.Int("count", len(usage.Dashboards)))
+
+	bots := make([]string, 0, len(usage.Bots))
+	for _, bot := range usage.Bots {
+		d.logger.Info(
+			"Excluding reads of user detected as bot",
+			slog.String("user", bot.User),
+			slog.String("reason", bot.Reason),
+		)
+		bots = append(bots, bot.User)
+	}
+
+	usedDashboards := d.usedMap(usage.Dashboards)
+
+	if err := d.checkCanaries(usage); err != nil {
+		return err
+	}
+
+	stats, record, err := d.checkUsage(usage, start)
+	if err != nil {
+		return err
+	}
+
+	logins, err := d.creatorLogins(ctx)
+	if err != nil {
+		return err
+	}
+
+	decisions := d.evaluatePolicy(all, usedDashboards, folders, logins, start)
+
+	protections, err := d.gatherProtections(ctx, all, usedDashboards, start)
+	if err != nil {
+		return err
+	}
+
+	if err := d.checkDeletionPercentage(all, usedDashboards, folders, decisions, protections.all(), start); err != nil {
+		return err
+	}
+
+	review := newProposalReview(nil)
+	if d.deferDeletion && !d.dry {
+		proposals, err := d.backups.Proposals(ctx, d.namespace)
+		if err != nil {
+			return fmt.Errorf("fetching deletion proposals: %w", err)
+		}
+		review = newProposalReview(proposals)
+	}
+
+	candidates, err := d.reviewCandidates(start)
+	if err != nil {
+		return err
+	}
+
+	// The approved candidates that were deleted are recorded if the run fails before it completes their runs.
+	reviewed := false
+	defer func() {
+		if !reviewed {
+			d.abandonCandidateReview(candidates)
+		}
+	}()
+
+	creators := make(map[string]string)
+	if d.notifier != nil && !d.dry {
+		creators, err = d.creators(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	run := &runState{
+		start:       start,
+		period:      period,
+		used:        usedDashboards,
+		bots:        bots,
+		folders:     folders,
+		decisions:   decisions,
+		protections: protections,
+		creators:    creators,
+		review:      review,
+		candidates:  candidates,
+	}
+	result := &runResult{}
+
+	// Owners are notified even if the run fails so that the dashboards that were deleted before the failure are
+	// announced.
+	defer func() {
+		d.notifyOwners(ctx, notify.EventProposed, result.proposed)
+		d.notifyOwners(ctx, notify.EventArchived, result.archived)
+		d.notifyOwners(ctx, notify.EventDeleted, result.deleted)
+	}()
 
 	for i := range all {
 		dashboard := &all[i]
@@ -147,29 +539,14 @@ func (d *DashboardPruner) prune(ctx context.Context) error {
 			slog.String("name", dashboard.Name),
 			slog.String("title", dashboard.Title),
 		)
-		usage, isUsed := usedDashboards[dashboard.Key()]
-		if isUsed {
-			dashboardLogger.Debug(
-				"Skipping used dashboard",
-				slog.Int("reads", usage.Reads()),
-				slog.Int("users", usage.Users()),
-				slog.String("range", d.period.String()),
-			)
+		if dashboard.Tombstone() {
+			if err := d.removeTombstone(ctx, dashboardLogger, dashboard, start, result); err != nil {
+				return err
+			}
 			continue
 		}
 
-		if dashboard.Provisioned() {
-			dashboardLogger.Debug("Skipping provisioned dashboard", slog.String("managed_by", *dashboard.ManagedBy))
-			continue
-		}
-
-		skip, matchedTag := d.hasSkipTag(dashboard)
-		if skip {
-			dashboardLogger.Info(
-				"Skipping dashboard with skip tag",
-				slog.String("tag", matchedTag),
-				slog.Any("dashboard_tags", dashboard.Tags),
-			)
+		if !d.prunable(dashboardLogger, dashboard, run) {
 			continue
 		}
 
@@ -178,37 +555,614 @@ func (d *DashboardPruner) prune(ctx context.Context) error {
 			continue
 		}
 
-		limitExceeded := d.maxDeletions != nil && len(deleted) >= *d.maxDeletions
-		if limitExceeded {
-			skippedDueToLimit++
-			continue
-		}
-
-		dashboardLogger.Info("Deleting unused dashboard", slog.String("raw_json", string(dashboard.Spec)))
-		if err := d.grafana.DeleteDashboard(ctx, dashboard.Namespace, dashboard.Name, dashboard.Spec); err != nil {
-			return fmt.Errorf("deleting unused dashboard %s: %w", dashboard.UID, err)
+		if err := d.pruneDashboard(ctx, dashboardLogger, dashboard, run, result); err != nil {
+			return err
 		}
-		dashboardLogger.Info("Deleted unused dashboard", slog.String("raw_json", string(dashboard.Spec)))
-		deleted = append(deleted, fmt.Sprintf("%s/%s", dashboard.Namespace, dashboard.Name))
 	}
 
-	if skippedDueToLimit > 0 {
+	if result.skippedDueToLimit > 0 {
 		d.logger.Info(
 			"Reached maximum deletion limit",
 			slog.Int("max_deletions", *d.maxDeletions),
-			slog.Int("remaining_unused_dashboards", skippedDueToLimit),
+			slog.Int("remaining_unused_dashboards", result.skippedDueToLimit),
+		)
+	}
+
+	if err := d.resolveProposals(ctx, review); err != nil {
+		return err
+	}
+
+	if err := d.finishCandidateReview(candidates, start); err != nil {
+		return err
+	}
+	reviewed = true
+
+	if record {
+		if err := d.recordUsage(&stats); err != nil {
+			return err
+		}
+	}
+
+	if (d.deferDeletion || d.approvalRuns != nil) && !d.dry {
+		d.logger.Info(
+			"Proposed deletion of unused Grafana dashboards",
+			slog.Int("proposed_count", len(result.proposed)),
+			slog.String("proposed_dashboards", qualifiedNames(result.proposed)),
+		)
+	}
+
+	if d.archive != nil && !d.dry {
+		d.logger.Info(
+			"Archived unused Grafana dashboards",
+			slog.Int("archived_count", len(result.archived)),
+			slog.String("archived_dashboards", qualifiedNames(result.archived)),
 		)
 	}
 
 	d.logger.Info(
 		"Finished pruning Grafana dashboards",
-		slog.Int("deleted_count", len(deleted)),
-		slog.String("deleted_dashboards", strings.Join(deleted, ", ")),
+		slog.Int("deleted_count", len(result.deleted)),
+		slog.String("deleted_dashboards", qualifiedNames(result.deleted)),
 	)
 
 	return nil
 }
 
+// runResult collects the backups of the dashboards that a prune run deleted, proposed for deletion and archived.
+type runResult struct {
+	deleted  []backup.Dashboard
+	proposed []backup.Dashboard
+	archived []backup.Dashboard
+	// removedTombstones is the number of expired tombstones that were deleted.
+	removedTombstones int
+	// skippedDueToLimit is the number of prunable dashboards and expired tombstones that were left as the maximum
+	// deletions was reached.
+	skippedDueToLimit int
+}
+
+// deletions returns the number of dashboards that were deleted or archived, including tombstones.
+func (r *runResult) deletions() int {
+	return len(r.deleted) + len(r.archived) + r.removedTombstones
+}
+
+// qualifiedNames returns the comma-separated namespace/name of each of dashboards.
+func qualifiedNames(dashboards []backup.Dashboard) string {
+	names := make([]string, 0, len(dashboards))
+	for i := range dashboards {
+		names = append(names, fmt.Sprintf("%s/%s", dashboards[i].Namespace, dashboards[i].Name))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// pruneDashboard deletes, archives or proposes the deletion of dashboard, which prunable has found to be prunable, and
+// records the outcome in result.
+func (d *DashboardPruner) pruneDashboard(
+	ctx context.Context,
+	logger *slog.Logger,
+	dashboard *Dashboard,
+	run *runState,
+	result *runResult,
+) error {
+	usage := run.used[dashboard.Key()]
+	decision := run.decisions[dashboard.Key()]
+	owner := d.ownerOf(dashboard, run.folders, run.creators)
+	b := d.backupOf(dashboard, &usage, run.bots, run.folders, owner, run.start)
+	reason := deletionReason(dashboard, decision, run.start, run.period)
+
+	if d.approvalRuns != nil {
+		wasDeleted, err := d.deleteApprovedCandidate(ctx, logger, b, run.candidates)
+		if err != nil {
+			return err
+		}
+		if wasDeleted {
+			result.deleted = append(result.deleted, *b)
+			d.createTombstone(ctx, logger, b, reason, run.start)
+		}
+		if run.candidates.reviewed(dashboard.Name) {
+			return nil
+		}
+
+		if d.maxDeletions != nil && len(result.proposed) >= *d.maxDeletions {
+			result.skippedDueToLimit++
+			return nil
+		}
+
+		run.candidates.propose(b)
+		logger.Info("Recorded unused dashboard as deletion candidate")
+		result.proposed = append(result.proposed, *b)
+		return nil
+	}
+
+	if d.deferDeletion {
+		wasDeleted, err := d.deleteApproved(ctx, logger, b, run.review)
+		if err != nil {
+			return err
+		}
+		if wasDeleted {
+			result.deleted = append(result.deleted, *b)
+			d.createTombstone(ctx, logger, b, reason, run.start)
+		}
+		if run.review.reviewed(dashboard.Name) {
+			return nil
+		}
+	}
+
+	limited := result.deletions()
+	if d.deferDeletion {
+		limited = len(result.proposed)
+	}
+	if d.maxDeletions != nil && limited >= *d.maxDeletions {
+		result.skippedDueToLimit++
+		return nil
+	}
+
+	if d.deferDeletion {
+		if err := d.backups.BackUpDashboard(ctx, b); err != nil {
+			return fmt.Errorf("proposing deletion of unused dashboard %s: %w", dashboard.UID, err)
+		}
+		logger.Info("Proposed deletion of unused dashboard")
+		result.proposed = append(result.proposed, *b)
+		return nil
+	}
+
+	if d.archive != nil {
+		archivedAt, isArchived := d.archivedAt(dashboard)
+		if !isArchived {
+			if err := d.ensureArchiveFolder(ctx, run.folders); err != nil {
+				return err
+			}
+			logger.Info("Archiving unused dashboard", slog.String("folder", d.archive.Folder))
+			err := d.grafana.ArchiveDashboard(ctx, dashboard.Namespace, dashboard.Name, d.archive.Folder, run.start)
+			if err != nil {
+				return fmt.Errorf("archiving unused dashboard %s: %w", dashboard.UID, err)
+			}
+			logger.Info("Archived unused dashboard", slog.String("folder", d.archive.Folder))
+			result.archived = append(result.archived, *b)
+			return nil
+		}
+		logger.Info(
+			"Found archived dashboard whose archive period has ended",
+			slog.String("archived_at", archivedAt.UTC().Format(time.RFC3339)),
+		)
+	}
+
+	logger.Info("Deleting unused dashboard", slog.String("raw_json", string(dashboard.Spec)))
+	if err := d.grafana.DeleteDashboard(ctx, b); err != nil {
+		return fmt.Errorf("deleting unused dashboard %s: %w", dashboard.UID, err)
+	}
+	logger.Info("Deleted unused dashboard", slog.String("raw_json", string(dashboard.Spec)))
+	result.deleted = append(result.deleted, *b)
+	d.createTombstone(ctx, logger, b, reason, run.start)
+
+	return nil
+}
+
+// backupOf returns the backup of dashboard. usage is the zero value if the dashboard has not been read. bots are the
+// users detected as bots in the prune run. owner is the owner of dashboard. start is the start time of the prune run.
+func (d *DashboardPruner) backupOf(
+	dashboard *Dashboard,
+	usage *DashboardReads,
+	bots []string,
+	folders map[string]Folder,
+	owner string,
+	start time.Time,
+) *backup.Dashboard {
+	// Archived dashboards are backed up with the folder that they were archived from so that they are restored there.
+	folder := d.originalFolder(dashboard)
+
+	return &backup.Dashboard{
+		Namespace:         dashboard.Namespace,
+		Name:              dashboard.Name,
+		UID:               dashboard.UID,
+		Title:             dashboard.Title,
+		FolderUID:         folder,
+		FolderTitle:       folders[folder].Title,
+		Tags:              dashboard.Tags,
+		CreationTimestamp: dashboard.CreationTimestamp,
+		Reads:             usage.Reads(),
+		Users:             usage.Users(),
+		LastViewed:        usage.LastRead(),
+		Owner:             owner,
+		Bots:              bots,
+		RunID:             start.Format(runIDLayout),
+		RunStart:          start,
+		JSON:              dashboard.Spec,
+	}
+}
+
+// notifyOwners notifies the owners of dashboards about event. notifyOwners logs failures instead of returning them as
+// the dashboards have already been deleted or proposed for deletion.
+func (d *DashboardPruner) notifyOwners(ctx context.Context, event notify.Event, dashboards []backup.Dashboard) {
+	if d.notifier == nil || len(dashboards) == 0 {
+		return
+	}
+
+	if err := d.notifier.Notify(ctx, event, dashboards); err != nil {
+		d.logger.Error(
+			"Failed to notify dashboard owners",
+			slog.String("event", string(event)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// deleteApproved deletes the dashboard if its deletion has been approved. deleteApproved returns true if the dashboard
+// was deleted.
+//
+// The backup of an approved dashboard is already stored, so the dashboard is deleted without being backed up again. A
+// dashboard that has changed since its deletion was proposed is not deleted as its stored backup would be outdated.
+func (d *DashboardPruner) deleteApproved(
+	ctx context.Context,
+	logger *slog.Logger,
+	dashboard *backup.Dashboard,
+	review *proposalReview,
+) (bool, error) {
+	if proposal, ok := review.pending[dashboard.Name]; ok {
+		logger.Info("Skipping dashboard whose deletion awaits approval", slog.String("proposal", proposal.URL))
+		return false, nil
+	}
+
+	approved, ok := review.approved[dashboard.Name]
+	if !ok {
+		return false, nil
+	}
+
+	if approved.hash != dashboard.Hash() {
+		logger.Info(
+			"Skipping dashboard that changed after its deletion was approved",
+			slog.String("proposal", approved.proposal.URL),
+		)
+		return false, nil
+	}
+
+	logger.Info("Deleting dashboard whose deletion was approved", slog.String("proposal", approved.proposal.URL))
+	if err := d.grafana.DeleteBackedUpDashboard(ctx, dashboard.Namespace, dashboard.Name); err != nil {
+		return false, fmt.Errorf("deleting approved dashboard %s: %w", dashboard.UID, err)
+	}
+	logger.Info("Deleted dashboard whose deletion was approved", slog.String("proposal", approved.proposal.URL))
+
+	// The backup was stored when the deletion was proposed. Dashboards of proposals that do not record the location of
+	// their backups are located by the proposal instead.
+	dashboard.Location = approved.location
+	if dashboard.Location == "" {
+		dashboard.Location = approved.proposal.URL
+	}
+
+	return true, nil
+}
+
+// resolveProposals resolves all approved and rejected proposals. resolveProposals must only be called once all
+// approved dashboards have been handled.
+func (d *DashboardPruner) resolveProposals(ctx context.Context, review *proposalReview) error {
+	for i := range review.proposals {
+		proposal := &review.proposals[i]
+		if proposal.State == backup.ProposalPending {
+			continue
+		}
+
+		if err := d.backups.ResolveProposal(ctx, proposal); err != nil {
+			return fmt.Errorf("resolving deletion proposal %s: %w", proposal.ID, err)
+		}
+
+		d.logger.Info(
+			"Resolved deletion proposal",
+			slog.String("proposal", proposal.URL),
+			slog.String("state", string(proposal.State)),
+		)
+	}
+
+	return nil
+}
+
+// resolveIgnoredUsers returns the configured ignored users along with the service accounts, team members and users with
+// a role whose reads are ignored. resolveIgnoredUsers only queries Grafana for the rules that are configured.
+func (d *DashboardPruner) resolveIgnoredUsers(ctx context.Context) ([]string, error) {
+	if !d.reads.ignoreServiceAccounts && len(d.reads.ignoredTeams) == 0 && len(d.reads.ignoredRoles) == 0 {
+		return d.reads.ignoredUsers, nil
+	}
+
+	users := slices.Clone(d.reads.ignoredUsers)
+
+	if d.reads.ignoreServiceAccounts {
+		accounts, err := d.grafana.ServiceAccounts(ctx, d.namespace)
+		if err != nil {
+			return nil, fmt.Errorf("fetching Grafana service accounts: %w", err)
+		}
+		users = append(users, accounts...)
+	}
+
+	for _, team := range d.reads.ignoredTeams {
+		members, found, err := d.grafana.TeamMembers(ctx, d.namespace, team)
+		if err != nil {
+			return nil, fmt.Errorf("fetching members of Grafana team: %w", err)
+		}
+		if !found {
+			d.logger.Warn("Ignored team does not exist", slog.String("team", team))
+			continue
+		}
+		users = append(users, members...)
+	}
+
+	if len(d.reads.ignoredRoles) > 0 {
+		orgUsers, err := d.grafana.OrgUsers(ctx, d.namespace)
+		if err != nil {
+			return nil, fmt.Errorf("fetching Grafana organisation users: %w", err)
+		}
+		for _, user := range orgUsers {
+			if slices.Contains(d.reads.ignoredRoles, user.Role) {
+				users = append(users, user.Login)
+			}
+		}
+	}
+
+	d.logger.Info("Resolved ignored users", slog.Int("count", len(users)))
+
+	return users, nil
+}
+
+// folders returns all folders of the namespace keyed by their name. folders returns an empty map without sending any
+// request to Grafana unless a feature needs folders, see needsFolders.
+func (d *DashboardPruner) folders(ctx context.Context) (map[string]Folder, error) {
+	if !d.needsFolders() {
+		return map[string]Folder{}, nil
+	}
+
+	folders, err := d.grafana.AllFolders(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching all Grafana folders: %w", err)
+	}
+
+	m := make(map[string]Folder, len(folders))
+	for _, folder := range folders {
+		m[folder.Name] = folder
+	}
+
+	return m, nil
+}
+
+// needsFolders returns true if any configured feature matches folders or reads the titles of folders. The backups,
+// notifications and approval candidates of deleted dashboards include the title of their folder.
+func (d *DashboardPruner) needsFolders() bool {
+	return len(d.skipFolders) > 0 ||
+		len(d.includeFolders) > 0 ||
+		len(d.ownerFolders) > 0 ||
+		d.archive != nil ||
+		(d.policy != nil && d.policy.reads("folder")) ||
+		d.backups != nil ||
+		d.notifier != nil ||
+		d.approvalRuns != nil
+}
+
+// policyDecision is the outcome of evaluating the policy against a dashboard. skip and force hold the expressions that
+// matched the dashboard and are empty if no expression matched.
+type policyDecision struct {
+	skip  string
+	force string
+}
+
+// evaluatePolicy evaluates the policy against all non-provisioned dashboards. A dashboard for which a skip expression
+// cannot be evaluated is skipped, and a dashboard for which a force expression cannot be evaluated is not forced.
+// logins maps user identities to logins, see creatorLogins.
+func (d *DashboardPruner) evaluatePolicy(
+	all []Dashboard,
+	used map[DashboardKey]DashboardReads,
+	folders map[string]Folder,
+	logins map[string]string,
+	start time.Time,
+) map[DashboardKey]policyDecision {
+	decisions := make(map[DashboardKey]policyDecision)
+	if d.policy == nil {
+		return decisions
+	}
+
+	for i := range all {
+		dashboard := &all[i]
+		if dashboard.Provisioned() {
+			continue
+		}
+
+		usage := used[dashboard.Key()]
+		pd := &policyDashboard{
+			Name:        dashboard.Name,
+			Title:       dashboard.Title,
+			Tags:        dashboard.Tags,
+			Folder:      folders[dashboard.Folder].Title,
+			FolderUID:   dashboard.Folder,
+			Annotations: dashboard.Annotations,
+			Created:     dashboard.CreationTimestamp,
+			Modified:    dashboard.LastModified(),
+			Reads:       usage.Reads(),
+			QueryReads:  usage.QueryReads(),
+			Users:       usage.UserNames(),
+			LastViewed:  usage.LastRead(),
+			Creator:     logins[dashboard.Annotations[createdByAnnotation]],
+		}
+		logger := d.logger.With(slog.String("uid", dashboard.UID), slog.String("name", dashboard.Name))
+
+		var decision policyDecision
+		skip, matched, err := d.policy.skip(pd, start)
+		if err != nil {
+			logger.Warn("Failed to evaluate skip policy, skipping dashboard", slog.String("error", err.Error()))
+			matched = true
+		}
+		if matched {
+			decision.skip = skip
+		}
+
+		force, matched, err := d.policy.force(pd, start)
+		if err != nil {
+			logger.Warn("Failed to evaluate force policy, not forcing deletion", slog.String("error", err.Error()))
+		}
+		if matched {
+			decision.force = force
+		}
+
+		decisions[dashboard.Key()] = decision
+	}
+
+	return decisions
+}
+
+// checkRetention returns the period in which to analyse dashboard usage. If Loki does not hold Grafana logs for the
+// entire period, checkRetention either returns an error or clamps the period to the range that Loki holds logs for.
+// start is the start time of the prune run.
+func (d *DashboardPruner) checkRetention(ctx context.Context, start time.Time) (time.Duration, error) {
+	if d.safeguards.retention == nil {
+		return d.period, nil
+	}
+
+	oldest, found, err := d.grafana.OldestLogTime(ctx, d.labels, d.period, d.chunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("finding oldest Grafana log: %w", err)
+	}
+
+	// If there are no logs at all, UsedDashboards finds fewer logs than the lower threshold and fails the run.
+	if !found {
+		return d.period, nil
+	}
+
+	tolerance := d.safeguards.retention.Tolerance
+	if tolerance == 0 {
+		tolerance = defaultRetentionTolerance
+	}
+
+	available := start.Sub(oldest).Truncate(time.Minute)
+	if available+tolerance >= d.period {
+		return d.period, nil
+	}
+
+	logger := d.logger.With(
+		slog.String("period", d.period.String()),
+		slog.String("available", available.String()),
+		slog.String("oldest_log", oldest.UTC().Format(time.RFC3339)),
+	)
+
+	if d.safeguards.retention.Action == RetentionActionClamp {
+		logger.Warn("Loki does not hold Grafana logs for the entire period, clamping period to the available range")
+		return available, nil
+	}
+
+	if d.dry {
+		logger.Warn("Loki does not hold Grafana logs for the entire period, run would be aborted if not dry")
+		return d.period, nil
+	}
+
+	d.metrics.abortedRuns.WithLabelValues(d.namespace, abortReasonPeriodExceedsRetention).Inc()
+
+	return 0, fmt.Errorf(
+		"aborting run as Loki only holds Grafana logs from the last %s, which is shorter than the period of %s",
+		available,
+		d.period,
+	)
+}
+
+// checkCanaries returns an error if any canary dashboard has not been read. Canaries are checked against the raw reads
+// of usage, as the synthetic monitors that read canaries are often ignored users or detected as bots.
+func (d *DashboardPruner) checkCanaries(usage *Usage) error {
+	var missing []string
+	for _, canary := range d.safeguards.canaries {
+		if usage.RawReads(canary.Namespace, canary.Name) == 0 {
+			missing = append(missing, fmt.Sprintf("%s/%s", canary.Namespace, canary.Name))
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if d.dry {
+		d.logger.Warn(
+			"Canary dashboards are unused, run would be aborted if not dry",
+			slog.String("canaries", strings.Join(missing, ", ")),
+		)
+		return nil
+	}
+
+	d.metrics.abortedRuns.WithLabelValues(d.namespace, abortReasonMissingCanary).Inc()
+
+	return fmt.Errorf("aborting run as canary dashboard(s) %s were not read in the period", strings.Join(missing, ", "))
+}
+
+// checkDeletionPercentage returns an error if the percentage of non-provisioned dashboards that are unused exceeds the
+// maximum deletion percentage. Dashboards outside the included folders do not count at all. Dashboards with a skip tag,
+// in a skipped folder, that are skipped by policy, that were edited recently, that are protected, e.g., by references,
+// alert rules or stars, or that are kept until after start do not count as unused. Dashboards that are forced by
+// policy or whose TTL has expired count as unused even if they are used, as they are deleted either way.
+func (d *DashboardPruner) checkDeletionPercentage(
+	all []Dashboard,
+	used map[DashboardKey]DashboardReads,
+	folders map[string]Folder,
+	decisions map[DashboardKey]policyDecision,
+	protected map[DashboardKey]string,
+	start time.Time,
+) error {
+	if d.safeguards.maxDeletionPercentage == nil {
+		return nil
+	}
+
+	total, unused := 0, 0
+	for i := range all {
+		dashboard := &all[i]
+		if dashboard.Provisioned() || dashboard.Tombstone() || !d.included(dashboard, folders) {
+			continue
+		}
+		total++
+
+		// Forced dashboards and dashboards whose TTL has expired are deleted even if they are used.
+		forced := decisions[dashboard.Key()].force != "" || dashboard.Expired(start)
+		if !forced && d.used(dashboard, used, start) {
+			continue
+		}
+		if skip, _ := d.hasSkipTag(dashboard); skip {
+			continue
+		}
+		if _, skip := matchFolder(dashboard, folders, d.skipFolders); skip {
+			continue
+		}
+		if decisions[dashboard.Key()].skip != "" || dashboard.Kept(start) {
+			continue
+		}
+		if _, archived := d.archivedUntil(dashboard, start); archived {
+			continue
+		}
+		if _, ok := protected[dashboard.Key()]; ok {
+			continue
+		}
+		unused++
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	percentage := float64(unused) / float64(total) * 100
+	if percentage <= *d.safeguards.maxDeletionPercentage {
+		return nil
+	}
+
+	if d.dry {
+		d.logger.Warn(
+			"Unused dashboards exceed maximum deletion percentage, run would be aborted if not dry",
+			slog.Int("unused_count", unused),
+			slog.Int("total_count", total),
+			slog.Float64("max_deletion_percentage", *d.safeguards.maxDeletionPercentage),
+		)
+		return nil
+	}
+
+	d.metrics.abortedRuns.WithLabelValues(d.namespace, abortReasonDeletionPercentage).Inc()
+
+	return fmt.Errorf(
+		"aborting run as %d of %d non-provisioned dashboards (%.1f%%) are unused, which exceeds the maximum deletion "+
+			"percentage of %.1f%%",
+		unused,
+		total,
+		percentage,
+		*d.safeguards.maxDeletionPercentage,
+	)
+}
+
 func (d *DashboardPruner) usedMap(used []DashboardReads) map[DashboardKey]DashboardReads {
 	m := make(map[DashboardKey]DashboardReads, len(used))
 
@@ -219,6 +1173,55 @@ func (d *DashboardPruner) usedMap(used []DashboardReads) map[DashboardKey]Dashbo
 	return m
 }
 
+// included returns true if dashboard is in an included folder or if pruning is not limited to included folders.
+// Archived dashboards are always included so that they are deleted once their archive period has ended.
+func (d *DashboardPruner) included(dashboard *Dashboard, folders map[string]Folder) bool {
+	if len(d.includeFolders) == 0 {
+		return true
+	}
+
+	if _, archived := d.archivedAt(dashboard); archived {
+		return true
+	}
+
+	_, ok := matchFolder(dashboard, folders, d.includeFolders)
+
+	return ok
+}
+
+// belowMinimumUsage returns the reason why usage is below the minimum reads or users. The returned bool is false if
+// usage meets both minimums.
+func (d *DashboardPruner) belowMinimumUsage(usage *DashboardReads) (string, bool) {
+	// Datasource queries count as reads so that dashboards on wall-mounted displays, which are rarely viewed but
+	// constantly queried, are not considered unused.
+	if reads := usage.Reads() + usage.QueryReads(); reads < d.minReads {
+		return fmt.Sprintf("%d read(s) is below the minimum of %d", reads, d.minReads), true
+	}
+
+	if usage.Users() < d.minUsers {
+		return fmt.Sprintf("%d user(s) is below the minimum of %d", usage.Users(), d.minUsers), true
+	}
+
+	return "", false
+}
+
+// used returns true if dashboard has been read at least as much as the minimum reads and users, or if it was edited
+// recently.
+func (d *DashboardPruner) used(dashboard *Dashboard, used map[DashboardKey]DashboardReads, start time.Time) bool {
+	if usage, isUsed := used[dashboard.Key()]; isUsed {
+		if _, below := d.belowMinimumUsage(&usage); !below {
+			return true
+		}
+	}
+
+	return d.recentlyEdited(dashboard, start)
+}
+
+// recentlyEdited returns true if dashboard was saved or created within the edit window before start.
+func (d *DashboardPruner) recentlyEdited(dashboard *Dashboard, start time.Time) bool {
+	return d.editWindow > 0 && start.Sub(dashboard.LastModified()) < d.editWindow
+}
+
 // hasSkipTag returns true if the dashboard has any tag in the skip list, along with the matched tag name.
 func (d *DashboardPruner) hasSkipTag(dashboard *Dashboard) (bool, string) {
 	if len(d.skipTags) == 0 {
@@ -233,3 +1236,50 @@ func (d *DashboardPruner) hasSkipTag(dashboard *Dashboard) (bool, string) {
 
 	return false, ""
 }
+
+// proposalReview indexes deletion proposals by dashboard name.
+type proposalReview struct {
+	proposals []backup.Proposal
+	pending   map[string]*backup.Proposal
+	approved  map[string]approval
+}
+
+type approval struct {
+	proposal *backup.Proposal
+	hash     string
+	location string
+}
+
+func newProposalReview(proposals []backup.Proposal) *proposalReview {
+	r := &proposalReview{
+		proposals: proposals,
+		pending:   make(map[string]*backup.Proposal),
+		approved:  make(map[string]approval),
+	}
+
+	for i := range proposals {
+		proposal := &proposals[i]
+		// Rejected proposals are only indexed in proposals so that they can be resolved.
+		for _, dashboard := range proposal.Dashboards {
+			if proposal.State == backup.ProposalPending {
+				r.pending[dashboard.Name] = proposal
+			} else if proposal.State == backup.ProposalApproved {
+				r.approved[dashboard.Name] = approval{
+					proposal: proposal,
+					hash:     dashboard.Hash,
+					location: dashboard.Location,
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+// reviewed returns true if the deletion of the dashboard is pending or approved.
+func (r *proposalReview) reviewed(name string) bool {
+	_, pending := r.pending[name]
+	_, approved := r.approved[name]
+
+	return pending || approved
+}
@@ -4,13 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/approvals"
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/notify"
+	"github.com/LasseHels/frigg/state"
 )
 
 type mockGrafanaClient struct {
@@ -20,25 +30,161 @@ type mockGrafanaClient struct {
 		r time.Duration,
 		opts UsedDashboardsOptions,
 	) ([]DashboardReads, error)
-	allDashboards   func(ctx context.Context, namespace string) ([]Dashboard, error)
-	deleteDashboard func(ctx context.Context, namespace, name string, dashboardJSON []byte) error
+	usage func(
+		ctx context.Context,
+		labels map[string]string,
+		r time.Duration,
+		opts UsedDashboardsOptions,
+	) (*Usage, error)
+	oldestLogTime           func(ctx context.Context, labels map[string]string, r time.Duration) (time.Time, bool, error)
+	allDashboards           func(ctx context.Context, namespace string) ([]Dashboard, error)
+	allFolders              func(ctx context.Context, namespace string) ([]Folder, error)
+	orgUsers                func(ctx context.Context, namespace string) ([]OrgUser, error)
+	serviceAccounts         func(ctx context.Context, namespace string) ([]string, error)
+	teamMembers             func(ctx context.Context, namespace, name string) ([]string, bool, error)
+	playlists               func(ctx context.Context, namespace string) ([]Playlist, error)
+	homeDashboards          func(ctx context.Context, namespace string) ([]HomeDashboard, error)
+	alertRules              func(ctx context.Context, namespace string) ([]AlertRule, error)
+	starredDashboards       func(ctx context.Context, namespace string) ([]StarredDashboard, error)
+	publicDashboards        func(ctx context.Context, namespace string) ([]PublicDashboard, error)
+	snapshots               func(ctx context.Context, namespace string) ([]Snapshot, error)
+	deleteDashboard         func(ctx context.Context, dashboard *backup.Dashboard) error
+	deleteBackedUpDashboard func(ctx context.Context, namespace, name string) error
+	createFolder            func(ctx context.Context, namespace, name, title string) error
+	restrictFolder          func(ctx context.Context, namespace, uid string) error
+	archiveDashboard        func(ctx context.Context, namespace, name, folder string, archivedAt time.Time) error
+	createDashboard         func(
+		ctx context.Context,
+		namespace, name, folder string,
+		annotations map[string]string,
+		spec any,
+	) error
+	importURL func(namespace string) (string, error)
 }
 
+// UsedDashboards returns the result of usage if set. Otherwise, UsedDashboards returns the dashboards of usedDashboards
+// with one log per read.
 func (m *mockGrafanaClient) UsedDashboards(
 	ctx context.Context,
 	labels map[string]string,
 	r time.Duration,
 	opts UsedDashboardsOptions,
-) ([]DashboardReads, error) {
-	return m.usedDashboards(ctx, labels, r, opts)
+) (*Usage, error) {
+	if m.usage != nil {
+		return m.usage(ctx, labels, r, opts)
+	}
+
+	dashboards, err := m.usedDashboards(ctx, labels, r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	logCount := 0
+	rawReads := make(map[DashboardKey]int, len(dashboards))
+	for _, d := range dashboards {
+		logCount += d.Reads()
+		rawReads[d.Key()] = d.Reads() + d.QueryReads()
+	}
+
+	return &Usage{Dashboards: dashboards, LogCount: logCount, rawReads: rawReads}, nil
+}
+
+// OldestLogTime finds no log unless oldestLogTime is set.
+func (m *mockGrafanaClient) OldestLogTime(
+	ctx context.Context,
+	labels map[string]string,
+	r time.Duration,
+	_ time.Duration,
+) (time.Time, bool, error) {
+	if m.oldestLogTime == nil {
+		return time.Time{}, false, nil
+	}
+	return m.oldestLogTime(ctx, labels, r)
 }
 
 func (m *mockGrafanaClient) AllDashboards(ctx context.Context, namespace string) ([]Dashboard, error) {
 	return m.allDashboards(ctx, namespace)
 }
 
-func (m *mockGrafanaClient) DeleteDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error {
-	return m.deleteDashboard(ctx, namespace, name, dashboardJSON)
+// AllFolders returns no folders unless allFolders is set.
+func (m *mockGrafanaClient) AllFolders(ctx context.Context, namespace string) ([]Folder, error) {
+	if m.allFolders == nil {
+		return nil, nil
+	}
+	return m.allFolders(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) OrgUsers(ctx context.Context, namespace string) ([]OrgUser, error) {
+	return m.orgUsers(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) ServiceAccounts(ctx context.Context, namespace string) ([]string, error) {
+	return m.serviceAccounts(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) TeamMembers(ctx context.Context, namespace, name string) ([]string, bool, error) {
+	return m.teamMembers(ctx, namespace, name)
+}
+
+func (m *mockGrafanaClient) Playlists(ctx context.Context, namespace string) ([]Playlist, error) {
+	return m.playlists(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) HomeDashboards(ctx context.Context, namespace string) ([]HomeDashboard, error) {
+	return m.homeDashboards(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) AlertRules(ctx context.Context, namespace string) ([]AlertRule, error) {
+	return m.alertRules(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) StarredDashboards(ctx context.Context, namespace string) ([]StarredDashboard, error) {
+	return m.starredDashboards(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) PublicDashboards(ctx context.Context, namespace string) ([]PublicDashboard, error) {
+	return m.publicDashboards(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) Snapshots(ctx context.Context, namespace string) ([]Snapshot, error) {
+	return m.snapshots(ctx, namespace)
+}
+
+func (m *mockGrafanaClient) DeleteDashboard(ctx context.Context, dashboard *backup.Dashboard) error {
+	return m.deleteDashboard(ctx, dashboard)
+}
+
+func (m *mockGrafanaClient) DeleteBackedUpDashboard(ctx context.Context, namespace, name string) error {
+	return m.deleteBackedUpDashboard(ctx, namespace, name)
+}
+
+func (m *mockGrafanaClient) CreateFolder(ctx context.Context, namespace, name, title string) error {
+	return m.createFolder(ctx, namespace, name, title)
+}
+
+func (m *mockGrafanaClient) RestrictFolder(ctx context.Context, namespace, uid string) error {
+	return m.restrictFolder(ctx, namespace, uid)
+}
+
+func (m *mockGrafanaClient) ArchiveDashboard(
+	ctx context.Context,
+	namespace, name, folder string,
+	archivedAt time.Time,
+) error {
+	return m.archiveDashboard(ctx, namespace, name, folder, archivedAt)
+}
+
+func (m *mockGrafanaClient) CreateDashboard(
+	ctx context.Context,
+	namespace, name, folder string,
+	annotations map[string]string,
+	spec any,
+) error {
+	return m.createDashboard(ctx, namespace, name, folder, annotations, spec)
+}
+
+func (m *mockGrafanaClient) ImportURL(namespace string) (string, error) {
+	return m.importURL(namespace)
 }
 
 func TestDashboardPruner_Start(t *testing.T) {
@@ -230,17 +376,17 @@ func TestDashboardPruner_Prune(t *testing.T) {
 					newMockDashboardReads("dashboard1", 10, 2),
 				}, nil
 			},
-			deleteDashboard: func(ctx context.Context, namespace, name string, dashboardJSON []byte) error {
-				deletedNames = append(deletedNames, name)
-				assert.Equal(t, "default", namespace)
+			deleteDashboard: func(ctx context.Context, dashboard *backup.Dashboard) error {
+				deletedNames = append(deletedNames, dashboard.Name)
+				assert.Equal(t, "default", dashboard.Namespace)
 
-				switch name {
+				switch dashboard.Name {
 				case "dashboard2":
-					assert.JSONEq(t, `{"title": "Dashboard 2"}`, string(dashboardJSON))
+					assert.JSONEq(t, `{"title": "Dashboard 2"}`, string(dashboard.JSON))
 				case "dashboard3":
-					assert.JSONEq(t, `{"title": "Dashboard 3"}`, string(dashboardJSON))
+					assert.JSONEq(t, `{"title": "Dashboard 3"}`, string(dashboard.JSON))
 				default:
-					t.Errorf("unexpected dashboard deleted: %s", name)
+					t.Errorf("unexpected dashboard deleted: %s", dashboard.Name)
 				}
 
 				return nil
@@ -312,10 +458,10 @@ func TestDashboardPruner_Prune(t *testing.T) {
 			) ([]DashboardReads, error) {
 				return nil, nil
 			},
-			deleteDashboard: func(_ context.Context, namespace, name string, _ []byte) error {
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
 				deleteDashboardCalled = true
-				assert.Equal(t, "default", namespace)
-				assert.Equal(t, "dashboard1", name)
+				assert.Equal(t, "default", dashboard.Namespace)
+				assert.Equal(t, "dashboard1", dashboard.Name)
 				return nil
 			},
 		}
@@ -382,7 +528,7 @@ func TestDashboardPruner_Prune(t *testing.T) {
 					newMockDashboardReads("dashboard1", 10, 2),
 				}, nil
 			},
-			deleteDashboard: func(_ context.Context, _ string, _ string, _ []byte) error {
+			deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
 				deleteDashboardCalled = true
 				return nil
 			},
@@ -511,7 +657,7 @@ func TestDashboardPruner_Prune(t *testing.T) {
 			) ([]DashboardReads, error) {
 				return nil, nil
 			},
-			deleteDashboard: func(_ context.Context, _ string, _ string, _ []byte) error {
+			deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
 				return errors.New("dashboard delete failed")
 			},
 		}
@@ -580,8 +726,8 @@ func TestDashboardPruner_Prune(t *testing.T) {
 			) ([]DashboardReads, error) {
 				return nil, nil
 			},
-			deleteDashboard: func(_ context.Context, namespace, name string, _ []byte) error {
-				deletedNames = append(deletedNames, name)
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deletedNames = append(deletedNames, dashboard.Name)
 				return nil
 			},
 		}
@@ -648,8 +794,8 @@ func TestDashboardPruner_Prune(t *testing.T) {
 			) ([]DashboardReads, error) {
 				return nil, nil
 			},
-			deleteDashboard: func(_ context.Context, namespace, name string, _ []byte) error {
-				deletedNames = append(deletedNames, name)
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deletedNames = append(deletedNames, dashboard.Name)
 				return nil
 			},
 		}
@@ -716,8 +862,8 @@ func TestDashboardPruner_Prune(t *testing.T) {
 			) ([]DashboardReads, error) {
 				return nil, nil
 			},
-			deleteDashboard: func(_ context.Context, namespace, name string, _ []byte) error {
-				deletedNames = append(deletedNames, name)
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deletedNames = append(deletedNames, dashboard.Name)
 				return nil
 			},
 		}
@@ -752,31 +898,3243 @@ func TestDashboardPruner_Prune(t *testing.T) {
 	})
 }
 
-func newMockDashboardReads(name string, reads, users int) DashboardReads {
-	return DashboardReads{
-		name:      name,
-		namespace: "default",
-		reads:     reads,
-		users:     users,
+func TestDashboardPruner_MaxDeletionPercentage(t *testing.T) {
+	t.Parallel()
+
+	managedBy := "classic-file-provisioning"
+	// Two of the four non-provisioned dashboards are unused. The provisioned dashboard and the dashboard with a skip tag
+	// are never deleted and must not count as unused.
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "used", Namespace: "default"},
+		{UID: "uid2", Name: "unused1", Namespace: "default"},
+		{UID: "uid3", Name: "unused2", Namespace: "default"},
+		{UID: "uid4", Name: "kept", Namespace: "default", Tags: []string{"keep"}},
+		{UID: "uid5", Name: "provisioned", Namespace: "default", ManagedBy: &managedBy},
+	}
+
+	tests := map[string]struct {
+		maxDeletionPercentage float64
+		dry                   bool
+		expectedErr           string
+		expectedDeleted       []string
+		expectedLog           string
+		expectedMetrics       string
+	}{
+		"aborts run when unused dashboards exceed percentage": {
+			maxDeletionPercentage: 40,
+			dry:                   false,
+			expectedErr: "aborting run as 2 of 4 non-provisioned dashboards (50.0%) are unused, which exceeds the " +
+				"maximum deletion percentage of 40.0%",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="deletion_percentage"} 1
+`,
+		},
+		"deletes dashboards when unused dashboards are within percentage": {
+			maxDeletionPercentage: 50,
+			dry:                   false,
+			expectedErr:           "",
+			expectedDeleted:       []string{"unused1", "unused2"},
+			expectedLog:           "",
+			expectedMetrics:       "",
+		},
+		"logs warning instead of aborting dry run": {
+			maxDeletionPercentage: 40,
+			dry:                   true,
+			expectedErr:           "",
+			expectedDeleted:       nil,
+			//nolint:lll
+			expectedLog:     `{"level":"WARN","msg":"Unused dashboards exceed maximum deletion percentage, run would be aborted if not dry","dry":true,"namespace":"default","unused_count":2,"total_count":4,"max_deletion_percentage":40}`,
+			expectedMetrics: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return []DashboardReads{newMockDashboardReads("used", 5, 2)}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+			registry := prometheus.NewRegistry()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:               mockClient,
+				Logger:                l,
+				Namespace:             "default",
+				Interval:              time.Hour,
+				Period:                24 * time.Hour,
+				Labels:                map[string]string{"app": "grafana"},
+				Dry:                   tt.dry,
+				SkipTags:              []string{"keep"},
+				MaxDeletionPercentage: &tt.maxDeletionPercentage,
+				Metrics:               NewMetrics(registry),
+			})
+
+			err := pruner.prune(t.Context())
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+
+			err = testutil.GatherAndCompare(registry, strings.NewReader(tt.expectedMetrics), "frigg_prune_aborted_total")
+			require.NoError(t, err)
+		})
 	}
+
+	t.Run("counts used dashboards that are forced by policy as unused", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := (&PolicyConfig{Force: []string{`dashboard.name == "used"`}}).Compile()
+		require.NoError(t, err)
+
+		var deleted []string
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return dashboards, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return []DashboardReads{newMockDashboardReads("used", 5, 2)}, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deleted = append(deleted, dashboard.Name)
+				return nil
+			},
+		}
+
+		l, _ := logger()
+		maxDeletionPercentage := 50.0
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:               mockClient,
+			Logger:                l,
+			Namespace:             "default",
+			Interval:              time.Hour,
+			Period:                24 * time.Hour,
+			Labels:                map[string]string{"app": "grafana"},
+			Dry:                   false,
+			SkipTags:              []string{"keep"},
+			MaxDeletionPercentage: &maxDeletionPercentage,
+			Policy:                policy,
+			Metrics:               NewMetrics(prometheus.NewRegistry()),
+		})
+
+		err = pruner.pruneRun(t.Context(), time.Now())
+		require.EqualError(
+			t,
+			err,
+			"aborting run as 3 of 4 non-provisioned dashboards (75.0%) are unused, which exceeds the maximum deletion "+
+				"percentage of 50.0%",
+		)
+		assert.Empty(t, deleted)
+	})
 }
 
-func logger() (*slog.Logger, *bytes.Buffer) {
-	buf := &bytes.Buffer{}
-	replaceTime := func(_ []string, a slog.Attr) slog.Attr {
-		// The time field of a log line is often the only variable value; we replace it to get deterministic output that
-		// is easier to test.
-		if a.Key == slog.TimeKey {
-			return slog.Attr{}
+func TestDashboardPruner_Canaries(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		canaries        []CanaryConfig
+		dry             bool
+		expectedErr     string
+		expectedDeleted []string
+		expectedLog     string
+		expectedMetrics string
+	}{
+		"deletes dashboards when canaries are used": {
+			canaries: []CanaryConfig{
+				{Namespace: "default", Name: "canary"},
+				{Namespace: "other", Name: "canary"},
+			},
+			dry:             false,
+			expectedErr:     "",
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedMetrics: "",
+		},
+		"aborts run when canaries are unused": {
+			canaries: []CanaryConfig{
+				{Namespace: "default", Name: "canary"},
+				{Namespace: "default", Name: "missing1"},
+				{Namespace: "other", Name: "missing2"},
+			},
+			dry:             false,
+			expectedErr:     "aborting run as canary dashboard(s) default/missing1, other/missing2 were not read in the period",
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="missing_canary"} 1
+`,
+		},
+		"logs warning instead of aborting dry run": {
+			canaries:        []CanaryConfig{{Namespace: "default", Name: "missing"}},
+			dry:             true,
+			expectedErr:     "",
+			expectedDeleted: nil,
+			//nolint:lll
+			expectedLog:     `{"level":"WARN","msg":"Canary dashboards are unused, run would be aborted if not dry","dry":true,"namespace":"default","canaries":"default/missing"}`,
+			expectedMetrics: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{
+						{UID: "uid1", Name: "canary", Namespace: "default"},
+						{UID: "uid2", Name: "unused", Namespace: "default"},
+					}, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					other := newMockDashboardReads("canary", 3, 1)
+					other.namespace = "other"
+					return []DashboardReads{newMockDashboardReads("canary", 3, 1), other}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+			registry := prometheus.NewRegistry()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:   mockClient,
+				Logger:    l,
+				Namespace: "default",
+				Interval:  time.Hour,
+				Period:    24 * time.Hour,
+				Labels:    map[string]string{"app": "grafana"},
+				Dry:       tt.dry,
+				Canaries:  tt.canaries,
+				Metrics:   NewMetrics(registry),
+			})
+
+			err := pruner.prune(t.Context())
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+
+			err = testutil.GatherAndCompare(registry, strings.NewReader(tt.expectedMetrics), "frigg_prune_aborted_total")
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("counts reads of ignored users and bots", func(t *testing.T) {
+		t.Parallel()
+
+		var deleted []string
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{UID: "uid1", Name: "canary", Namespace: "default"},
+					{UID: "uid2", Name: "unused", Namespace: "default"},
+				}, nil
+			},
+			usage: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) (*Usage, error) {
+				// The canary is only read by a synthetic monitor whose reads are ignored.
+				return &Usage{
+					Dashboards: []DashboardReads{newMockDashboardReads("used", 3, 1)},
+					LogCount:   13,
+					rawReads: map[DashboardKey]int{
+						{name: "canary", namespace: "default"}: 10,
+						{name: "used", namespace: "default"}:   3,
+					},
+				}, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deleted = append(deleted, dashboard.Name)
+				return nil
+			},
 		}
 
-		return a
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			Canaries:  []CanaryConfig{{Namespace: "default", Name: "canary"}},
+			Metrics:   NewMetrics(prometheus.NewRegistry()),
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+		assert.Equal(t, []string{"canary", "unused"}, deleted)
+	})
+}
+
+func TestDashboardPruner_Retention(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		retention       *RetentionConfig
+		dry             bool
+		oldestLog       time.Time
+		found           bool
+		oldestLogErr    error
+		expectedErr     string
+		expectedPeriod  time.Duration
+		expectedDeleted []string
+		expectedLog     string
+		expectedMetrics string
+	}{
+		"keeps period when Loki holds logs for the entire period": {
+			retention:       &RetentionConfig{Action: RetentionActionAbort},
+			dry:             false,
+			oldestLog:       start.Add(-24 * time.Hour),
+			found:           true,
+			oldestLogErr:    nil,
+			expectedErr:     "",
+			expectedPeriod:  24 * time.Hour,
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedMetrics: "",
+		},
+		"keeps period when oldest log is within tolerance": {
+			retention:       &RetentionConfig{Action: RetentionActionAbort},
+			dry:             false,
+			oldestLog:       start.Add(-23*time.Hour - 30*time.Minute),
+			found:           true,
+			oldestLogErr:    nil,
+			expectedErr:     "",
+			expectedPeriod:  24 * time.Hour,
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedMetrics: "",
+		},
+		"keeps period when no log is found": {
+			retention:       &RetentionConfig{Action: RetentionActionAbort},
+			dry:             false,
+			oldestLog:       time.Time{},
+			found:           false,
+			oldestLogErr:    nil,
+			expectedErr:     "",
+			expectedPeriod:  24 * time.Hour,
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+			expectedMetrics: "",
+		},
+		"aborts run when Loki does not hold logs for the entire period": {
+			retention:    &RetentionConfig{Action: RetentionActionAbort},
+			dry:          false,
+			oldestLog:    start.Add(-12 * time.Hour),
+			found:        true,
+			oldestLogErr: nil,
+			expectedErr: "aborting run as Loki only holds Grafana logs from the last 12h0m0s, which is shorter than the " +
+				"period of 24h0m0s",
+			expectedPeriod:  0,
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="period_exceeds_retention"} 1
+`,
+		},
+		"aborts run when oldest log is outside custom tolerance": {
+			retention:    &RetentionConfig{Action: RetentionActionAbort, Tolerance: time.Minute},
+			dry:          false,
+			oldestLog:    start.Add(-23*time.Hour - 30*time.Minute),
+			found:        true,
+			oldestLogErr: nil,
+			expectedErr: "aborting run as Loki only holds Grafana logs from the last 23h30m0s, which is shorter than " +
+				"the period of 24h0m0s",
+			expectedPeriod:  0,
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedMetrics: `
+# HELP frigg_prune_aborted_total Total number of prune runs that were aborted before deleting any dashboards.
+# TYPE frigg_prune_aborted_total counter
+frigg_prune_aborted_total{namespace="default",reason="period_exceeds_retention"} 1
+`,
+		},
+		"logs warning instead of aborting dry run": {
+			retention:       &RetentionConfig{Action: RetentionActionAbort},
+			dry:             true,
+			oldestLog:       start.Add(-12 * time.Hour),
+			found:           true,
+			oldestLogErr:    nil,
+			expectedErr:     "",
+			expectedPeriod:  24 * time.Hour,
+			expectedDeleted: nil,
+			//nolint:lll
+			expectedLog:     `{"level":"WARN","msg":"Loki does not hold Grafana logs for the entire period, run would be aborted if not dry","dry":true,"namespace":"default","period":"24h0m0s","available":"12h0m0s","oldest_log":"2026-10-18T00:00:00Z"}`,
+			expectedMetrics: "",
+		},
+		"clamps period to the range that Loki holds logs for": {
+			retention:       &RetentionConfig{Action: RetentionActionClamp},
+			dry:             false,
+			oldestLog:       start.Add(-12*time.Hour - 30*time.Second),
+			found:           true,
+			oldestLogErr:    nil,
+			expectedErr:     "",
+			expectedPeriod:  12 * time.Hour,
+			expectedDeleted: []string{"unused"},
+			//nolint:lll
+			expectedLog:     `{"level":"WARN","msg":"Loki does not hold Grafana logs for the entire period, clamping period to the available range","dry":false,"namespace":"default","period":"24h0m0s","available":"12h0m0s","oldest_log":"2026-10-17T23:59:30Z"}`,
+			expectedMetrics: "",
+		},
+		"errors if oldest log cannot be found": {
+			retention:       &RetentionConfig{Action: RetentionActionAbort},
+			dry:             false,
+			oldestLog:       time.Time{},
+			found:           false,
+			oldestLogErr:    errors.New("loki is down"),
+			expectedErr:     "finding oldest Grafana log: loki is down",
+			expectedPeriod:  0,
+			expectedDeleted: nil,
+			expectedLog:     "",
+			expectedMetrics: "",
+		},
 	}
-	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{
-		ReplaceAttr: replaceTime,
-		Level:       slog.LevelDebug,
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			var period time.Duration
+			mockClient := &mockGrafanaClient{
+				oldestLogTime: func(_ context.Context, _ map[string]string, r time.Duration) (time.Time, bool, error) {
+					assert.Equal(t, 24*time.Hour, r)
+					return tt.oldestLog, tt.found, tt.oldestLogErr
+				},
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{{UID: "uid1", Name: "unused", Namespace: "default"}}, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					r time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					period = r
+					return nil, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+			registry := prometheus.NewRegistry()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:   mockClient,
+				Logger:    l,
+				Namespace: "default",
+				Interval:  time.Hour,
+				Period:    24 * time.Hour,
+				Labels:    map[string]string{"app": "grafana"},
+				Dry:       tt.dry,
+				Retention: tt.retention,
+				Metrics:   NewMetrics(registry),
+			})
+
+			err := pruner.pruneRun(t.Context(), start)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedPeriod, period)
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+
+			err = testutil.GatherAndCompare(registry, strings.NewReader(tt.expectedMetrics), "frigg_prune_aborted_total")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDashboardPruner_Lifetime(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	created := start.Add(-7 * 24 * time.Hour)
+
+	var deleted []string
+	mockClient := &mockGrafanaClient{
+		allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+			return []Dashboard{
+				{UID: "uid1", Name: "kept", Namespace: "default", KeepUntil: start.Add(time.Hour)},
+				{UID: "uid2", Name: "no-longer-kept", Namespace: "default", KeepUntil: start},
+				{UID: "uid3", Name: "expired", Namespace: "default", CreationTimestamp: created, TTL: 7 * 24 * time.Hour},
+				{UID: "uid4", Name: "not-expired", Namespace: "default", CreationTimestamp: created, TTL: 8 * 24 * time.Hour},
+				{
+					UID:               "uid5",
+					Name:              "expired-but-kept",
+					Namespace:         "default",
+					CreationTimestamp: created,
+					TTL:               24 * time.Hour,
+					KeepUntil:         start.Add(time.Hour),
+				},
+			}, nil
+		},
+		usedDashboards: func(
+			_ context.Context,
+			_ map[string]string,
+			_ time.Duration,
+			_ UsedDashboardsOptions,
+		) ([]DashboardReads, error) {
+			return []DashboardReads{
+				newMockDashboardReads("expired", 5, 2),
+				newMockDashboardReads("expired-but-kept", 5, 2),
+				newMockDashboardReads("not-expired", 5, 2),
+			}, nil
+		},
+		deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+			deleted = append(deleted, dashboard.Name)
+			return nil
+		},
+	}
+
+	l, logs := logger()
+
+	pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+		Grafana:   mockClient,
+		Logger:    l,
+		Namespace: "default",
+		Interval:  time.Hour,
+		Period:    24 * time.Hour,
+		Labels:    map[string]string{"app": "grafana"},
+		Dry:       false,
 	})
-	l := slog.New(handler)
 
-	return l, buf
+	require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+	assert.Equal(t, []string{"no-longer-kept", "expired"}, deleted)
+
+	//nolint:lll
+	expectedLogs := []string{
+		`{"level":"INFO","msg":"Skipping dashboard that is kept until a later date","dry":false,"namespace":"default","uid":"uid1","name":"kept","title":"","keep_until":"2026-10-18T13:00:00Z"}`,
+		`{"level":"INFO","msg":"Found dashboard whose TTL has expired","dry":false,"namespace":"default","uid":"uid3","name":"expired","title":"","ttl":"168h0m0s","created":"2026-10-11T12:00:00Z","used":true}`,
+		`{"level":"INFO","msg":"Skipping dashboard that is kept until a later date","dry":false,"namespace":"default","uid":"uid5","name":"expired-but-kept","title":"","keep_until":"2026-10-18T13:00:00Z"}`,
+	}
+	for _, expected := range expectedLogs {
+		assert.Contains(t, logs.String(), expected)
+	}
+}
+
+func TestDashboardPruner_Policy(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	policy, err := (&PolicyConfig{
+		Skip: []string{
+			`dashboard.title.startsWith("[Team-X]") && dashboard.folder == "Y"`,
+			`dashboard.annotations["team"] == "core"`,
+		},
+		Force: []string{`dashboard.users == [dashboard.creator]`},
+	}).Compile()
+	require.NoError(t, err)
+
+	team := map[string]string{"team": "x"}
+
+	var deleted []string
+	mockClient := &mockGrafanaClient{
+		allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+			return []Dashboard{
+				{UID: "uid1", Name: "used", Namespace: "default", Annotations: team},
+				{
+					UID:         "uid2",
+					Name:        "forced",
+					Namespace:   "default",
+					Annotations: map[string]string{"team": "x", createdByAnnotation: "user:u1"},
+				},
+				{UID: "uid3", Name: "skipped", Namespace: "default", Title: "[Team-X] A", Folder: "fy", Annotations: team},
+				{UID: "uid4", Name: "unused", Namespace: "default", Title: "[Team-X] B", Annotations: team},
+				{UID: "uid5", Name: "unknown-team", Namespace: "default"},
+			}, nil
+		},
+		allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+			return []Folder{{Name: "fy", Title: "Y"}}, nil
+		},
+		orgUsers: func(_ context.Context, _ string) ([]OrgUser, error) {
+			return []OrgUser{{Login: "creator", UID: "u1"}}, nil
+		},
+		usedDashboards: func(
+			_ context.Context,
+			_ map[string]string,
+			_ time.Duration,
+			_ UsedDashboardsOptions,
+		) ([]DashboardReads, error) {
+			used := newMockDashboardReads("used", 2, 2)
+			used.userNames = []string{"creator", "someone-else"}
+			forced := newMockDashboardReads("forced", 2, 1)
+			forced.userNames = []string{"creator"}
+			return []DashboardReads{used, forced}, nil
+		},
+		deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+			deleted = append(deleted, dashboard.Name)
+			return nil
+		},
+	}
+
+	l, logs := logger()
+
+	pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+		Grafana:   mockClient,
+		Logger:    l,
+		Namespace: "default",
+		Interval:  time.Hour,
+		Period:    24 * time.Hour,
+		Labels:    map[string]string{"app": "grafana"},
+		Dry:       false,
+		Policy:    policy,
+	})
+
+	require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+	assert.Equal(t, []string{"forced", "unused"}, deleted)
+
+	//nolint:lll
+	expectedLogs := []string{
+		`{"level":"INFO","msg":"Found dashboard matched by force policy","dry":false,"namespace":"default","uid":"uid2","name":"forced","title":"","expression":"dashboard.users == [dashboard.creator]","used":true}`,
+		`{"level":"INFO","msg":"Skipping dashboard matched by skip policy","dry":false,"namespace":"default","uid":"uid3","name":"skipped","title":"[Team-X] A","expression":"dashboard.title.startsWith(\"[Team-X]\") && dashboard.folder == \"Y\""}`,
+		`{"level":"WARN","msg":"Failed to evaluate skip policy, skipping dashboard","dry":false,"namespace":"default","uid":"uid5","name":"unknown-team","error":"evaluating \"dashboard.annotations[\\\"team\\\"] == \\\"core\\\"\": no such key: team"}`,
+		`{"level":"INFO","msg":"Skipping dashboard matched by skip policy","dry":false,"namespace":"default","uid":"uid5","name":"unknown-team","title":"","expression":"dashboard.annotations[\"team\"] == \"core\""}`,
+	}
+	for _, expected := range expectedLogs {
+		assert.Contains(t, logs.String(), expected)
+	}
+}
+
+func TestDashboardPruner_Folders(t *testing.T) {
+	t.Parallel()
+
+	var deleted []string
+	mockClient := &mockGrafanaClient{
+		allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+			return []Dashboard{
+				{UID: "uid1", Name: "root", Namespace: "default"},
+				{UID: "uid2", Name: "sandbox", Namespace: "default", Folder: "sandbox"},
+				{UID: "uid3", Name: "nested", Namespace: "default", Folder: "experiments"},
+				{UID: "uid4", Name: "kept", Namespace: "default", Folder: "keep"},
+				{UID: "uid5", Name: "shared", Namespace: "default", Folder: "shared"},
+			}, nil
+		},
+		allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+			return []Folder{
+				{Name: "sandbox", Title: "Sandbox"},
+				{Name: "experiments", Title: "Experiments", Parent: "sandbox"},
+				{Name: "keep", Title: "Keep", Parent: "sandbox"},
+				{Name: "shared", Title: "Shared"},
+			}, nil
+		},
+		usedDashboards: func(
+			_ context.Context,
+			_ map[string]string,
+			_ time.Duration,
+			_ UsedDashboardsOptions,
+		) ([]DashboardReads, error) {
+			return nil, nil
+		},
+		deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+			deleted = append(deleted, dashboard.Name)
+			return nil
+		},
+	}
+
+	l, logs := logger()
+	// Only the three dashboards in the included folders count, of which two are unused and one is skipped.
+	maxDeletionPercentage := 70.0
+
+	pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+		Grafana:               mockClient,
+		Logger:                l,
+		Namespace:             "default",
+		Interval:              time.Hour,
+		Period:                24 * time.Hour,
+		Labels:                map[string]string{"app": "grafana"},
+		Dry:                   false,
+		SkipFolders:           []string{"Sandbox/Keep"},
+		IncludeFolders:        []string{"sandbox"},
+		MaxDeletionPercentage: &maxDeletionPercentage,
+		Metrics:               NewMetrics(prometheus.NewRegistry()),
+	})
+
+	require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+	assert.Equal(t, []string{"sandbox", "nested"}, deleted)
+	//nolint:lll
+	assert.Contains(
+		t,
+		logs.String(),
+		`{"level":"INFO","msg":"Skipping dashboard in skipped folder","dry":false,"namespace":"default","uid":"uid4","name":"kept","title":"","folder":"Sandbox/Keep"}`,
+	)
+}
+
+func TestDashboardPruner_NeedsFolders(t *testing.T) {
+	t.Parallel()
+
+	folderPolicy, err := (&PolicyConfig{Skip: []string{`dashboard.folder == "Platform"`}}).Compile()
+	require.NoError(t, err)
+
+	titlePolicy, err := (&PolicyConfig{Skip: []string{`dashboard.title == "Platform"`}}).Compile()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		opts     NewDashboardPrunerOptions
+		expected bool
+	}{
+		"no feature": {
+			opts:     NewDashboardPrunerOptions{},
+			expected: false,
+		},
+		"skip folders": {
+			opts:     NewDashboardPrunerOptions{SkipFolders: []string{"Platform"}},
+			expected: true,
+		},
+		"include folders": {
+			opts:     NewDashboardPrunerOptions{IncludeFolders: []string{"Platform"}},
+			expected: true,
+		},
+		"owner folders": {
+			opts:     NewDashboardPrunerOptions{OwnerFolders: map[string]string{"Platform": "platform-team"}},
+			expected: true,
+		},
+		"archive": {
+			opts:     NewDashboardPrunerOptions{Archive: &ArchiveConfig{Folder: "Archive", Period: time.Hour}},
+			expected: true,
+		},
+		"policy reads folder": {
+			opts:     NewDashboardPrunerOptions{Policy: folderPolicy},
+			expected: true,
+		},
+		"policy does not read folder": {
+			opts:     NewDashboardPrunerOptions{Policy: titlePolicy},
+			expected: false,
+		},
+		"backups": {
+			opts:     NewDashboardPrunerOptions{Backups: &mockBackups{}},
+			expected: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l, _ := logger()
+			tt.opts.Logger = l
+
+			pruner := NewDashboardPruner(&tt.opts)
+			assert.Equal(t, tt.expected, pruner.needsFolders())
+		})
+	}
+}
+
+func TestDashboardPruner_MinimumUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		minReads        int
+		minUsers        int
+		expectedDeleted []string
+		expectedLog     string
+	}{
+		"single read makes dashboard used by default": {
+			minReads:        0,
+			minUsers:        0,
+			expectedDeleted: []string{"unused"},
+			expectedLog:     "",
+		},
+		"deletes dashboard with fewer reads than minimum": {
+			minReads:        3,
+			minUsers:        0,
+			expectedDeleted: []string{"clicked", "unused"},
+			//nolint:lll
+			expectedLog: `{"level":"INFO","msg":"Considering dashboard unused as its usage is below the minimum","dry":false,"namespace":"default","uid":"uid1","name":"clicked","title":"","reason":"1 read(s) is below the minimum of 3","reads":1,"users":1,"min_reads":3,"min_users":0,"range":"24h0m0s"}`,
+		},
+		"deletes dashboards with fewer users than minimum": {
+			minReads:        0,
+			minUsers:        2,
+			expectedDeleted: []string{"clicked", "single-user", "unused"},
+			//nolint:lll
+			expectedLog: `{"level":"INFO","msg":"Considering dashboard unused as its usage is below the minimum","dry":false,"namespace":"default","uid":"uid2","name":"single-user","title":"","reason":"1 user(s) is below the minimum of 2","reads":10,"users":1,"min_reads":0,"min_users":2,"range":"24h0m0s"}`,
+		},
+		"keeps dashboards that meet both minimums": {
+			minReads:        5,
+			minUsers:        1,
+			expectedDeleted: []string{"clicked", "unused"},
+			expectedLog:     "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{
+						{UID: "uid1", Name: "clicked", Namespace: "default"},
+						{UID: "uid2", Name: "single-user", Namespace: "default"},
+						{UID: "uid3", Name: "popular", Namespace: "default"},
+						{UID: "uid4", Name: "unused", Namespace: "default"},
+					}, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return []DashboardReads{
+						newMockDashboardReads("clicked", 1, 1),
+						newMockDashboardReads("single-user", 10, 1),
+						newMockDashboardReads("popular", 10, 3),
+					}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:   mockClient,
+				Logger:    l,
+				Namespace: "default",
+				Interval:  time.Hour,
+				Period:    24 * time.Hour,
+				Labels:    map[string]string{"app": "grafana"},
+				Dry:       false,
+				MinReads:  tt.minReads,
+				MinUsers:  tt.minUsers,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+		})
+	}
+}
+
+func TestDashboardPruner_Backups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finishes run after deleting dashboards", func(t *testing.T) {
+		t.Parallel()
+
+		var deletedRunIDs []string
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{
+						UID:       "uid1",
+						Name:      "dashboard1",
+						Namespace: "default",
+						Title:     "Dashboard 1",
+						Tags:      []string{"team-a"},
+						Spec:      json.RawMessage(`{"title": "Dashboard 1"}`),
+					},
+				}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deletedRunIDs = append(deletedRunIDs, dashboard.RunID)
+				assert.Equal(t, "uid1", dashboard.UID)
+				assert.Equal(t, "Dashboard 1", dashboard.Title)
+				assert.Equal(t, []string{"team-a"}, dashboard.Tags)
+				return nil
+			},
+		}
+
+		var finishedRunIDs []string
+		backups := &mockBackups{
+			finishRun: func(_ context.Context, namespace, runID string) error {
+				assert.Equal(t, "default", namespace)
+				finishedRunIDs = append(finishedRunIDs, runID)
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Backups:   backups,
+		})
+
+		err := pruner.prune(t.Context())
+		require.NoError(t, err)
+		require.Len(t, finishedRunIDs, 1)
+		assert.Equal(t, finishedRunIDs, deletedRunIDs)
+		_, err = time.Parse(runIDLayout, finishedRunIDs[0])
+		require.NoError(t, err)
+	})
+
+	t.Run("backs up dashboard with folder and run start", func(t *testing.T) {
+		t.Parallel()
+
+		var backups []*backup.Dashboard
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{UID: "uid1", Name: "dashboard1", Namespace: "default", Folder: "platform"},
+					{UID: "uid2", Name: "dashboard2", Namespace: "default"},
+				}, nil
+			},
+			allFolders: func(_ context.Context, namespace string) ([]Folder, error) {
+				assert.Equal(t, "default", namespace)
+				return []Folder{{Name: "platform", Title: "Platform"}}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				backups = append(backups, dashboard)
+				return nil
+			},
+		}
+
+		storage := &mockBackups{
+			finishRun: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Backups:   storage,
+		})
+
+		before := time.Now().UTC().Truncate(time.Second)
+		err := pruner.prune(t.Context())
+		require.NoError(t, err)
+
+		require.Len(t, backups, 2)
+		assert.Equal(t, "platform", backups[0].FolderUID)
+		assert.Equal(t, "Platform", backups[0].FolderTitle)
+		assert.Empty(t, backups[1].FolderUID)
+		assert.Empty(t, backups[1].FolderTitle)
+		assert.Equal(t, backups[0].RunStart, backups[1].RunStart)
+		assert.Equal(t, backups[0].RunStart.Format(runIDLayout), backups[0].RunID)
+		assert.WithinRange(t, backups[0].RunStart, before, time.Now().UTC())
+	})
+
+	t.Run("does not prune when folders cannot be fetched", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{{UID: "uid1", Name: "dashboard1", Namespace: "default"}}, nil
+			},
+			allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+				return nil, errors.New("forbidden")
+			},
+		}
+
+		backups := &mockBackups{
+			finishRun: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Backups:   backups,
+		})
+
+		err := pruner.prune(t.Context())
+		require.EqualError(t, err, "fetching all Grafana folders: forbidden")
+	})
+
+	t.Run("finishes run when deletion fails", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{{UID: "uid1", Name: "dashboard1", Namespace: "default"}}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
+				return errors.New("dashboard delete failed")
+			},
+		}
+
+		finished := false
+		backups := &mockBackups{
+			finishRun: func(_ context.Context, _, _ string) error {
+				finished = true
+				return errors.New("pull request could not be opened")
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Backups:   backups,
+		})
+
+		err := pruner.prune(t.Context())
+		require.ErrorContains(t, err, "deleting unused dashboard uid1: dashboard delete failed; finishing run ")
+		require.ErrorContains(t, err, ": pull request could not be opened")
+		assert.True(t, finished)
+	})
+
+	t.Run("purges expired backups after finishing run", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return nil, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+		}
+
+		var calls []string
+		var purgedAt time.Time
+		backups := &mockBackups{
+			finishRun: func(_ context.Context, _, _ string) error {
+				calls = append(calls, "finish")
+				return nil
+			},
+			purgeExpired: func(_ context.Context, namespace string, now time.Time, dry bool) error {
+				calls = append(calls, "purge")
+				purgedAt = now
+				assert.Equal(t, "default", namespace)
+				assert.True(t, dry)
+				return errors.New("tree could not be created")
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       true,
+			Backups:   backups,
+		})
+
+		before := time.Now()
+		err := pruner.prune(t.Context())
+		require.EqualError(t, err, "purging expired backups: tree could not be created")
+		assert.Equal(t, []string{"finish", "purge"}, calls)
+		assert.WithinDuration(t, before, purgedAt, time.Minute)
+	})
+
+	t.Run("defers deletion until proposal is approved", func(t *testing.T) {
+		t.Parallel()
+
+		unchangedSpec := json.RawMessage(`{"title": "Approved"}`)
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{UID: "uid1", Name: "pending", Namespace: "default", Title: "Pending"},
+					{UID: "uid2", Name: "approved", Namespace: "default", Title: "Approved", Spec: unchangedSpec},
+					{
+						UID:       "uid3",
+						Name:      "changed",
+						Namespace: "default",
+						Title:     "Changed",
+						Spec:      json.RawMessage(`{"title": "Changed", "panels": []}`),
+					},
+					{UID: "uid4", Name: "approvedused", Namespace: "default", Title: "Approved Used"},
+					{UID: "uid5", Name: "new1", Namespace: "default", Title: "New 1"},
+					{UID: "uid6", Name: "new2", Namespace: "default", Title: "New 2"},
+				}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return []DashboardReads{newMockDashboardReads("approvedused", 1, 1)}, nil
+			},
+			deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
+				assert.Fail(t, "dashboards must not be deleted without approval")
+				return nil
+			},
+			deleteBackedUpDashboard: func(_ context.Context, namespace, name string) error {
+				assert.Equal(t, "default", namespace)
+				assert.Equal(t, "approved", name)
+				return nil
+			},
+		}
+
+		proposals := []backup.Proposal{
+			{
+				ID:         "1",
+				URL:        "https://example.com/1",
+				Namespace:  "default",
+				State:      backup.ProposalPending,
+				Dashboards: []backup.ProposedDashboard{{Name: "pending", Hash: "a"}},
+			},
+			{
+				ID:        "2",
+				URL:       "https://example.com/2",
+				Namespace: "default",
+				State:     backup.ProposalApproved,
+				Dashboards: []backup.ProposedDashboard{
+					{Name: "approved", Hash: backup.Hash(unchangedSpec)},
+					{Name: "changed", Hash: backup.Hash([]byte(`{"title": "Changed"}`))},
+					{Name: "approvedused", Hash: "b"},
+				},
+			},
+			{
+				ID:         "3",
+				URL:        "https://example.com/3",
+				Namespace:  "default",
+				State:      backup.ProposalRejected,
+				Dashboards: []backup.ProposedDashboard{{Name: "rejected", Hash: "c"}},
+			},
+		}
+
+		var proposedNames []string
+		var resolvedIDs []string
+		maxDeletions := 1
+		backups := &mockBackups{
+			backUpDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				proposedNames = append(proposedNames, dashboard.Name)
+				return nil
+			},
+			finishRun: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+			proposals: func(_ context.Context, namespace string) ([]backup.Proposal, error) {
+				assert.Equal(t, "default", namespace)
+				return proposals, nil
+			},
+			resolveProposal: func(_ context.Context, proposal *backup.Proposal) error {
+				resolvedIDs = append(resolvedIDs, proposal.ID)
+				return nil
+			},
+		}
+
+		l, logs := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:       mockClient,
+			Logger:        l,
+			Namespace:     "default",
+			Interval:      time.Hour,
+			Period:        24 * time.Hour,
+			Labels:        map[string]string{"app": "grafana"},
+			MaxDeletions:  &maxDeletions,
+			Backups:       backups,
+			DeferDeletion: true,
+		})
+
+		err := pruner.prune(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new1"}, proposedNames)
+		assert.Equal(t, []string{"2", "3"}, resolvedIDs)
+
+		//nolint:lll
+		expectedLogs := `{"level":"INFO","msg":"Pruning Grafana dashboards","dry":false,"namespace":"default"}
+{"level":"INFO","msg":"Found all Grafana dashboards","dry":false,"namespace":"default","count":6}
+{"level":"INFO","msg":"Found used Grafana dashboards","dry":false,"namespace":"default","count":1}
+{"level":"INFO","msg":"Skipping dashboard whose deletion awaits approval","dry":false,"namespace":"default","uid":"uid1","name":"pending","title":"Pending","proposal":"https://example.com/1"}
+{"level":"INFO","msg":"Deleting dashboard whose deletion was approved","dry":false,"namespace":"default","uid":"uid2","name":"approved","title":"Approved","proposal":"https://example.com/2"}
+{"level":"INFO","msg":"Deleted dashboard whose deletion was approved","dry":false,"namespace":"default","uid":"uid2","name":"approved","title":"Approved","proposal":"https://example.com/2"}
+{"level":"INFO","msg":"Skipping dashboard that changed after its deletion was approved","dry":false,"namespace":"default","uid":"uid3","name":"changed","title":"Changed","proposal":"https://example.com/2"}
+{"level":"DEBUG","msg":"Skipping used dashboard","dry":false,"namespace":"default","uid":"uid4","name":"approvedused","title":"Approved Used","reads":1,"users":1,"range":"24h0m0s"}
+{"level":"INFO","msg":"Proposed deletion of unused dashboard","dry":false,"namespace":"default","uid":"uid5","name":"new1","title":"New 1"}
+{"level":"INFO","msg":"Reached maximum deletion limit","dry":false,"namespace":"default","max_deletions":1,"remaining_unused_dashboards":1}
+{"level":"INFO","msg":"Resolved deletion proposal","dry":false,"namespace":"default","proposal":"https://example.com/2","state":"approved"}
+{"level":"INFO","msg":"Resolved deletion proposal","dry":false,"namespace":"default","proposal":"https://example.com/3","state":"rejected"}
+{"level":"INFO","msg":"Proposed deletion of unused Grafana dashboards","dry":false,"namespace":"default","proposed_count":1,"proposed_dashboards":"default/new1"}
+{"level":"INFO","msg":"Finished pruning Grafana dashboards","dry":false,"namespace":"default","deleted_count":1,"deleted_dashboards":"default/approved"}
+`
+		assert.Equal(t, expectedLogs, logs.String())
+	})
+}
+
+type mockBackups struct {
+	backUpDashboard func(ctx context.Context, dashboard *backup.Dashboard) error
+	finishRun       func(ctx context.Context, namespace, runID string) error
+	proposals       func(ctx context.Context, namespace string) ([]backup.Proposal, error)
+	resolveProposal func(ctx context.Context, proposal *backup.Proposal) error
+	purgeExpired    func(ctx context.Context, namespace string, now time.Time, dry bool) error
+}
+
+func (m *mockBackups) BackUpDashboard(ctx context.Context, dashboard *backup.Dashboard) error {
+	return m.backUpDashboard(ctx, dashboard)
+}
+
+func (m *mockBackups) FinishRun(ctx context.Context, namespace, runID string) error {
+	return m.finishRun(ctx, namespace, runID)
+}
+
+func (m *mockBackups) Proposals(ctx context.Context, namespace string) ([]backup.Proposal, error) {
+	return m.proposals(ctx, namespace)
+}
+
+func (m *mockBackups) ResolveProposal(ctx context.Context, proposal *backup.Proposal) error {
+	return m.resolveProposal(ctx, proposal)
+}
+
+func (m *mockBackups) PurgeExpiredBackups(ctx context.Context, namespace string, now time.Time, dry bool) error {
+	if m.purgeExpired == nil {
+		return nil
+	}
+
+	return m.purgeExpired(ctx, namespace, now, dry)
+}
+
+func newMockDashboardReads(name string, reads, users int) DashboardReads {
+	return DashboardReads{
+		name:      name,
+		namespace: "default",
+		reads:     reads,
+		users:     users,
+	}
+}
+
+func logger() (*slog.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	replaceTime := func(_ []string, a slog.Attr) slog.Attr {
+		// The time field of a log line is often the only variable value; we replace it to get deterministic output that
+		// is easier to test.
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+
+		return a
+	}
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: replaceTime,
+		Level:       slog.LevelDebug,
+	})
+	l := slog.New(handler)
+
+	return l, buf
+}
+
+func TestDashboardPruner_BotDetection(t *testing.T) {
+	t.Parallel()
+
+	botDetection := &BotDetectionConfig{MaxDashboards: 2, Window: time.Minute}
+
+	var options UsedDashboardsOptions
+	var backups []*backup.Dashboard
+	mockClient := &mockGrafanaClient{
+		allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+			return []Dashboard{{UID: "uid1", Name: "unused", Namespace: "default"}}, nil
+		},
+		usage: func(
+			_ context.Context,
+			_ map[string]string,
+			_ time.Duration,
+			opts UsedDashboardsOptions,
+		) (*Usage, error) {
+			options = opts
+			return &Usage{
+				LogCount: 10,
+				Bots:     []Bot{{User: "crawler", Reason: "read 3 distinct dashboards within 1m0s"}},
+			}, nil
+		},
+		deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+			backups = append(backups, dashboard)
+			return nil
+		},
+	}
+
+	l, logs := logger()
+
+	pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+		Grafana:      mockClient,
+		Logger:       l,
+		Namespace:    "default",
+		Interval:     time.Hour,
+		Period:       24 * time.Hour,
+		Labels:       map[string]string{"app": "grafana"},
+		Dry:          false,
+		BotDetection: botDetection,
+	})
+
+	require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+	assert.Same(t, botDetection, options.BotDetection)
+	require.Len(t, backups, 1)
+	assert.Equal(t, []string{"crawler"}, backups[0].Bots)
+	//nolint:lll
+	assert.Contains(t, logs.String(), `{"level":"INFO","msg":"Excluding reads of user detected as bot","dry":false,"namespace":"default","user":"crawler","reason":"read 3 distinct dashboards within 1m0s"}`)
+}
+
+func TestDashboardPruner_IgnoredUsers(t *testing.T) {
+	t.Parallel()
+
+	pattern := regexp.MustCompile("^sa-")
+
+	tests := map[string]struct {
+		ignoreServiceAccounts bool
+		ignoredTeams          []string
+		ignoredRoles          []string
+		expectedIgnoredUsers  []string
+		expectedLog           string
+	}{
+		"passes configured users without querying Grafana": {
+			ignoreServiceAccounts: false,
+			ignoredTeams:          nil,
+			ignoredRoles:          nil,
+			expectedIgnoredUsers:  []string{"admin"},
+			expectedLog:           "",
+		},
+		"resolves service accounts, team members and users with role": {
+			ignoreServiceAccounts: true,
+			ignoredTeams:          []string{"NOC", "Missing"},
+			ignoredRoles:          []string{"Admin"},
+			expectedIgnoredUsers:  []string{"admin", "sa-1-exporter", "noc-screen", "alice"},
+			//nolint:lll
+			expectedLog: `{"level":"WARN","msg":"Ignored team does not exist","dry":false,"namespace":"default","team":"Missing"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var options UsedDashboardsOptions
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return nil, nil
+				},
+				usage: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					opts UsedDashboardsOptions,
+				) (*Usage, error) {
+					options = opts
+					return &Usage{LogCount: 10}, nil
+				},
+				serviceAccounts: func(_ context.Context, namespace string) ([]string, error) {
+					assert.Equal(t, "default", namespace)
+					return []string{"sa-1-exporter"}, nil
+				},
+				teamMembers: func(_ context.Context, namespace, name string) ([]string, bool, error) {
+					assert.Equal(t, "default", namespace)
+					if name == "NOC" {
+						return []string{"noc-screen"}, true, nil
+					}
+					return nil, false, nil
+				},
+				orgUsers: func(_ context.Context, namespace string) ([]OrgUser, error) {
+					assert.Equal(t, "default", namespace)
+					return []OrgUser{{Login: "alice", Role: "Admin"}, {Login: "bob", Role: "Viewer"}}, nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:               mockClient,
+				Logger:                l,
+				Namespace:             "default",
+				Interval:              time.Hour,
+				IgnoredUsers:          []string{"admin"},
+				Period:                24 * time.Hour,
+				Labels:                map[string]string{"app": "grafana"},
+				Dry:                   false,
+				IgnoredUserPatterns:   []*regexp.Regexp{pattern},
+				IgnoreServiceAccounts: tt.ignoreServiceAccounts,
+				IgnoredTeams:          tt.ignoredTeams,
+				IgnoredRoles:          tt.ignoredRoles,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+			assert.Equal(t, tt.expectedIgnoredUsers, options.IgnoredUsers)
+			assert.Equal(t, []*regexp.Regexp{pattern}, options.IgnoredUserPatterns)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+		})
+	}
+
+	t.Run("fails run if users cannot be resolved", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return nil, nil
+			},
+			orgUsers: func(_ context.Context, _ string) ([]OrgUser, error) {
+				return nil, errors.New("forbidden")
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:      mockClient,
+			Logger:       l,
+			Namespace:    "default",
+			Interval:     time.Hour,
+			Period:       24 * time.Hour,
+			Labels:       map[string]string{"app": "grafana"},
+			IgnoredRoles: []string{"Admin"},
+		})
+
+		err := pruner.pruneRun(t.Context(), time.Now())
+		require.EqualError(t, err, "fetching Grafana organisation users: forbidden")
+	})
+}
+
+func TestDashboardPruner_QueryReads(t *testing.T) {
+	t.Parallel()
+
+	var options UsedDashboardsOptions
+	var deleted []string
+	mockClient := &mockGrafanaClient{
+		allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+			return []Dashboard{
+				{UID: "uid1", Name: "noc-screen", Namespace: "default"},
+				{UID: "uid2", Name: "rarely-queried", Namespace: "default"},
+			}, nil
+		},
+		usage: func(
+			_ context.Context,
+			_ map[string]string,
+			_ time.Duration,
+			opts UsedDashboardsOptions,
+		) (*Usage, error) {
+			options = opts
+			return &Usage{
+				Dashboards: []DashboardReads{
+					{name: "noc-screen", namespace: "default", reads: 1, queryReads: 500, users: 1},
+					{name: "rarely-queried", namespace: "default", reads: 0, queryReads: 2, users: 1},
+				},
+				LogCount: 10,
+			}, nil
+		},
+		deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+			deleted = append(deleted, dashboard.Name)
+			return nil
+		},
+	}
+
+	l, logs := logger()
+
+	pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+		Grafana:    mockClient,
+		Logger:     l,
+		Namespace:  "default",
+		Interval:   time.Hour,
+		Period:     24 * time.Hour,
+		Labels:     map[string]string{"app": "grafana"},
+		Dry:        false,
+		MinReads:   3,
+		QueryReads: true,
+	})
+
+	require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+	assert.True(t, options.QueryReads)
+	assert.Equal(t, []string{"rarely-queried"}, deleted)
+	assert.Contains(t, logs.String(), `"reason":"2 read(s) is below the minimum of 3"`)
+}
+
+func TestDashboardPruner_EditWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		editWindow      time.Duration
+		expectedDeleted []string
+		expectedLog     string
+	}{
+		"ignores edits by default": {
+			editWindow:      0,
+			expectedDeleted: []string{"saved", "created", "stale"},
+			expectedLog:     "",
+		},
+		"keeps dashboards saved or created within edit window": {
+			editWindow:      48 * time.Hour,
+			expectedDeleted: []string{"stale"},
+			//nolint:lll
+			expectedLog: `{"level":"INFO","msg":"Considering dashboard used as it was edited recently","dry":false,"namespace":"default","uid":"uid1","name":"saved","title":"","last_modified":"2026-10-17T12:00:00Z","edit_window":"48h0m0s"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{
+						{
+							UID:               "uid1",
+							Name:              "saved",
+							Namespace:         "default",
+							CreationTimestamp: start.AddDate(0, -6, 0),
+							Updated:           start.AddDate(0, 0, -1),
+						},
+						{
+							UID:               "uid2",
+							Name:              "created",
+							Namespace:         "default",
+							CreationTimestamp: start.Add(-time.Hour),
+						},
+						{
+							UID:               "uid3",
+							Name:              "stale",
+							Namespace:         "default",
+							CreationTimestamp: start.AddDate(0, -6, 0),
+							Updated:           start.AddDate(0, 0, -10),
+						},
+					}, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:    mockClient,
+				Logger:     l,
+				Namespace:  "default",
+				Interval:   time.Hour,
+				Period:     24 * time.Hour,
+				Labels:     map[string]string{"app": "grafana"},
+				Dry:        false,
+				EditWindow: tt.editWindow,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Contains(t, logs.String(), tt.expectedLog)
+		})
+	}
+}
+
+func TestDashboardPruner_ProtectReferences(t *testing.T) {
+	t.Parallel()
+
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "overview", Namespace: "default", Spec: json.RawMessage(`{"links":[{"url":"/d/details"}]}`)},
+		{UID: "uid2", Name: "details", Namespace: "default", Spec: json.RawMessage(`{"links":[{"url":"/d/drilldown"}]}`)},
+		{UID: "uid3", Name: "tv", Namespace: "default", Spec: json.RawMessage(`{}`)},
+		{UID: "uid4", Name: "team-home", Namespace: "default", Spec: json.RawMessage(`{}`)},
+		{UID: "uid5", Name: "orphan", Namespace: "default", Spec: json.RawMessage(`{}`)},
+		{UID: "uid6", Name: "drilldown", Namespace: "default", Spec: json.RawMessage(`{}`)},
+	}
+
+	tests := map[string]struct {
+		protectReferences bool
+		referenceDepth    int
+		expectedDeleted   []string
+		expectedLogs      []string
+	}{
+		"deletes referenced dashboards by default": {
+			protectReferences: false,
+			referenceDepth:    0,
+			expectedDeleted:   []string{"details", "tv", "team-home", "orphan", "drilldown"},
+			expectedLogs:      nil,
+		},
+		"skips dashboards referenced by used dashboards, playlists and home settings": {
+			protectReferences: true,
+			referenceDepth:    0,
+			expectedDeleted:   []string{"orphan", "drilldown"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping referenced dashboard","dry":false,"namespace":"default","uid":"uid2","name":"details","title":"","reason":"linked from dashboard \"overview\""}`,
+				`{"level":"INFO","msg":"Skipping referenced dashboard","dry":false,"namespace":"default","uid":"uid3","name":"tv","title":"","reason":"included in playlist \"NOC\""}`,
+				`{"level":"INFO","msg":"Skipping referenced dashboard","dry":false,"namespace":"default","uid":"uid4","name":"team-home","title":"","reason":"home dashboard of team \"SRE\""}`,
+			},
+		},
+		"skips dashboards referenced within reference depth": {
+			protectReferences: true,
+			referenceDepth:    2,
+			expectedDeleted:   []string{"orphan"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping referenced dashboard","dry":false,"namespace":"default","uid":"uid6","name":"drilldown","title":"","reason":"linked from dashboard \"details\""}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return []DashboardReads{{name: "overview", namespace: "default", reads: 1, users: 1}}, nil
+				},
+				playlists: func(_ context.Context, namespace string) ([]Playlist, error) {
+					assert.Equal(t, "default", namespace)
+					return []Playlist{
+						{UID: "p1", Name: "NOC", Items: []PlaylistItem{
+							{Type: "dashboard_by_tag", Value: "orphan"},
+							{Type: "dashboard_by_uid", Value: "tv"},
+						}},
+					}, nil
+				},
+				homeDashboards: func(_ context.Context, namespace string) ([]HomeDashboard, error) {
+					assert.Equal(t, "default", namespace)
+					return []HomeDashboard{{UID: "team-home", Team: "SRE"}}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:           mockClient,
+				Logger:            l,
+				Namespace:         "default",
+				Interval:          time.Hour,
+				Period:            24 * time.Hour,
+				Labels:            map[string]string{"app": "grafana"},
+				Dry:               false,
+				ProtectReferences: tt.protectReferences,
+				ReferenceDepth:    tt.referenceDepth,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+		})
+	}
+}
+
+func TestDashboardPruner_ProtectAlertRules(t *testing.T) {
+	t.Parallel()
+
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "latency", Namespace: "default", Spec: json.RawMessage(`{"links":[{"url":"/d/hosts"}]}`)},
+		{UID: "uid2", Name: "hosts", Namespace: "default", Spec: json.RawMessage(`{}`)},
+		{UID: "uid3", Name: "orphan", Namespace: "default", Spec: json.RawMessage(`{}`)},
+	}
+	rules := []AlertRule{
+		{UID: "r1", Title: "High latency", Annotations: map[string]string{"__dashboardUid__": "latency"}},
+		{UID: "r2", Title: "Disk full", Annotations: map[string]string{"summary": "Disk is full"}},
+	}
+
+	tests := map[string]struct {
+		protectAlertRules bool
+		protectReferences bool
+		expectedDeleted   []string
+		expectedLogs      []string
+	}{
+		"deletes dashboards linked from alert rules by default": {
+			protectAlertRules: false,
+			protectReferences: false,
+			expectedDeleted:   []string{"latency", "hosts", "orphan"},
+			expectedLogs:      nil,
+		},
+		"skips dashboards linked from alert rules": {
+			protectAlertRules: true,
+			protectReferences: false,
+			expectedDeleted:   []string{"hosts", "orphan"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping dashboard linked from alert rule","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","reason":"linked from alert rule \"High latency\""}`,
+			},
+		},
+		"skips dashboards referenced by dashboards linked from alert rules": {
+			protectAlertRules: true,
+			protectReferences: true,
+			expectedDeleted:   []string{"orphan"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping dashboard linked from alert rule","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","reason":"linked from alert rule \"High latency\""}`,
+				`{"level":"INFO","msg":"Skipping referenced dashboard","dry":false,"namespace":"default","uid":"uid2","name":"hosts","title":"","reason":"linked from dashboard \"latency\""}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				alertRules: func(_ context.Context, namespace string) ([]AlertRule, error) {
+					assert.Equal(t, "default", namespace)
+					return rules, nil
+				},
+				playlists: func(_ context.Context, _ string) ([]Playlist, error) {
+					return nil, nil
+				},
+				homeDashboards: func(_ context.Context, _ string) ([]HomeDashboard, error) {
+					return nil, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:           mockClient,
+				Logger:            l,
+				Namespace:         "default",
+				Interval:          time.Hour,
+				Period:            24 * time.Hour,
+				Labels:            map[string]string{"app": "grafana"},
+				Dry:               false,
+				ProtectAlertRules: tt.protectAlertRules,
+				ProtectReferences: tt.protectReferences,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+		})
+	}
+
+	t.Run("returns error if alert rules cannot be fetched", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return dashboards, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			alertRules: func(_ context.Context, _ string) ([]AlertRule, error) {
+				return nil, errors.New("forbidden")
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:           mockClient,
+			Logger:            l,
+			Namespace:         "default",
+			Interval:          time.Hour,
+			Period:            24 * time.Hour,
+			Labels:            map[string]string{"app": "grafana"},
+			ProtectAlertRules: true,
+		})
+
+		err := pruner.pruneRun(t.Context(), time.Now())
+		require.EqualError(t, err, "fetching Grafana alert rules: forbidden")
+	})
+}
+
+func TestDashboardPruner_ProtectStarredAndPublic(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "incident", Namespace: "default"},
+		{UID: "uid2", Name: "status-page", Namespace: "default"},
+		{UID: "uid3", Name: "paused", Namespace: "default"},
+		{UID: "uid4", Name: "orphan", Namespace: "default"},
+		{UID: "uid5", Name: "review", Namespace: "default"},
+		{UID: "uid6", Name: "expired", Namespace: "default"},
+	}
+
+	tests := map[string]struct {
+		protectStarred   bool
+		protectPublic    bool
+		protectSnapshots bool
+		expectedDeleted  []string
+		expectedLogs     []string
+	}{
+		"deletes starred and public dashboards by default": {
+			protectStarred:   false,
+			protectPublic:    false,
+			protectSnapshots: false,
+			expectedDeleted:  []string{"incident", "status-page", "paused", "orphan", "review", "expired"},
+			expectedLogs:     nil,
+		},
+		"skips starred dashboards": {
+			protectStarred:   true,
+			protectPublic:    false,
+			protectSnapshots: false,
+			expectedDeleted:  []string{"status-page", "paused", "orphan", "review", "expired"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping starred dashboard","dry":false,"namespace":"default","uid":"uid1","name":"incident","title":"","reason":"starred by 2 user(s)"}`,
+			},
+		},
+		"skips enabled public dashboards": {
+			protectStarred:   false,
+			protectPublic:    true,
+			protectSnapshots: false,
+			expectedDeleted:  []string{"incident", "paused", "orphan", "review", "expired"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping public dashboard","dry":false,"namespace":"default","uid":"uid2","name":"status-page","title":"","reason":"published as public dashboard \"pd1\""}`,
+			},
+		},
+		"skips dashboards shared as snapshots that have not expired": {
+			protectStarred:   false,
+			protectPublic:    false,
+			protectSnapshots: true,
+			expectedDeleted:  []string{"incident", "status-page", "paused", "orphan", "expired"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping dashboard shared as snapshot","dry":false,"namespace":"default","uid":"uid5","name":"review","title":"","reason":"shared as snapshot \"Incident review\""}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				starredDashboards: func(_ context.Context, namespace string) ([]StarredDashboard, error) {
+					assert.Equal(t, "default", namespace)
+					return []StarredDashboard{{UID: "incident", Users: 2}}, nil
+				},
+				publicDashboards: func(_ context.Context, namespace string) ([]PublicDashboard, error) {
+					assert.Equal(t, "default", namespace)
+					return []PublicDashboard{
+						{UID: "pd1", DashboardUID: "status-page", Enabled: true},
+						{UID: "pd2", DashboardUID: "paused", Enabled: false},
+					}, nil
+				},
+				snapshots: func(_ context.Context, namespace string) ([]Snapshot, error) {
+					assert.Equal(t, "default", namespace)
+					return []Snapshot{
+						{Key: "s1", Name: "Incident review", DashboardUID: "review", Expires: now.Add(time.Hour)},
+						{Key: "s2", Name: "Old review", DashboardUID: "expired", Expires: now.Add(-time.Hour)},
+						{Key: "s3", Name: "Shared externally", DashboardUID: "", Expires: now.Add(time.Hour)},
+					}, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:          mockClient,
+				Logger:           l,
+				Namespace:        "default",
+				Interval:         time.Hour,
+				Period:           24 * time.Hour,
+				Labels:           map[string]string{"app": "grafana"},
+				Dry:              false,
+				ProtectStarred:   tt.protectStarred,
+				ProtectPublic:    tt.protectPublic,
+				ProtectSnapshots: tt.protectSnapshots,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), now))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+		})
+	}
+}
+
+type mockNotifier struct {
+	notify func(ctx context.Context, event notify.Event, dashboards []backup.Dashboard) error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, event notify.Event, dashboards []backup.Dashboard) error {
+	return m.notify(ctx, event, dashboards)
+}
+
+func TestDashboardPruner_NotifyOwners(t *testing.T) {
+	t.Parallel()
+
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "tagged", Namespace: "default", Tags: []string{"team-a", "owner:team-a@example.com"}},
+		{
+			UID:         "uid2",
+			Name:        "created",
+			Namespace:   "default",
+			Annotations: map[string]string{"grafana.app/createdBy": "user:u1"},
+		},
+		{UID: "uid3", Name: "in-folder", Namespace: "default", Folder: "platform"},
+		{UID: "uid4", Name: "unowned", Namespace: "default"},
+	}
+
+	newClient := func() *mockGrafanaClient {
+		return &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return dashboards, nil
+			},
+			allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+				return []Folder{{Name: "platform", Title: "Platform"}}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			orgUsers: func(_ context.Context, namespace string) ([]OrgUser, error) {
+				assert.Equal(t, "default", namespace)
+				return []OrgUser{
+					{UID: "u1", Login: "alice", Email: "alice@example.com"},
+					{UID: "u2", Login: "bob"},
+				}, nil
+			},
+			deleteDashboard: func(_ context.Context, _ *backup.Dashboard) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("notifies owners of deleted dashboards", func(t *testing.T) {
+		t.Parallel()
+
+		owners := make(map[string]string)
+		var events []notify.Event
+		notifier := &mockNotifier{
+			notify: func(_ context.Context, event notify.Event, dashboards []backup.Dashboard) error {
+				events = append(events, event)
+				for _, dashboard := range dashboards {
+					owners[dashboard.Name] = dashboard.Owner
+				}
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:      newClient(),
+			Logger:       l,
+			Namespace:    "default",
+			Interval:     time.Hour,
+			Period:       24 * time.Hour,
+			Labels:       map[string]string{"app": "grafana"},
+			Notifier:     notifier,
+			OwnerFolders: map[string]string{"Platform": "team-platform"},
+			DefaultOwner: "grafana-admins",
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+		// Nothing was proposed for deletion, so the owners are only notified about deleted dashboards.
+		assert.Equal(t, []notify.Event{notify.EventDeleted}, events)
+		expectedOwners := map[string]string{
+			"tagged":    "team-a@example.com",
+			"created":   "alice@example.com",
+			"in-folder": "team-platform",
+			"unowned":   "grafana-admins",
+		}
+		assert.Equal(t, expectedOwners, owners)
+	})
+
+	t.Run("links backups of dashboards whose deletion was deferred", func(t *testing.T) {
+		t.Parallel()
+
+		client := newClient()
+		client.deleteBackedUpDashboard = func(_ context.Context, _, name string) error {
+			assert.Equal(t, "tagged", name)
+			return nil
+		}
+
+		locations := make(map[notify.Event]map[string]string)
+		notifier := &mockNotifier{
+			notify: func(_ context.Context, event notify.Event, dashboards []backup.Dashboard) error {
+				locations[event] = make(map[string]string)
+				for _, dashboard := range dashboards {
+					locations[event][dashboard.Name] = dashboard.Location
+				}
+				return nil
+			},
+		}
+
+		backups := &mockBackups{
+			backUpDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				dashboard.Location = "https://github.com/octocat/backups/blob/commit-sha/" + dashboard.Name + ".json"
+				return nil
+			},
+			finishRun: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+			proposals: func(_ context.Context, _ string) ([]backup.Proposal, error) {
+				return []backup.Proposal{{
+					ID:        "1",
+					URL:       "https://github.com/octocat/backups/pull/1",
+					Namespace: "default",
+					State:     backup.ProposalApproved,
+					Dashboards: []backup.ProposedDashboard{{
+						Name:     "tagged",
+						Hash:     backup.Hash(nil),
+						Path:     "tagged.json",
+						Location: "https://github.com/octocat/backups/blob/main/tagged.json",
+					}},
+				}}, nil
+			},
+			resolveProposal: func(_ context.Context, _ *backup.Proposal) error {
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:       client,
+			Logger:        l,
+			Namespace:     "default",
+			Interval:      time.Hour,
+			Period:        24 * time.Hour,
+			Labels:        map[string]string{"app": "grafana"},
+			Notifier:      notifier,
+			Backups:       backups,
+			DeferDeletion: true,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+		// The branch of the run that proposed the deletion has been deleted, so the backup is linked on the base branch.
+		expected := map[notify.Event]map[string]string{
+			notify.EventProposed: {
+				"created":   "https://github.com/octocat/backups/blob/commit-sha/created.json",
+				"in-folder": "https://github.com/octocat/backups/blob/commit-sha/in-folder.json",
+				"unowned":   "https://github.com/octocat/backups/blob/commit-sha/unowned.json",
+			},
+			notify.EventDeleted: {
+				"tagged": "https://github.com/octocat/backups/blob/main/tagged.json",
+			},
+		}
+		assert.Equal(t, expected, locations)
+	})
+
+	t.Run("logs notification failure without failing run", func(t *testing.T) {
+		t.Parallel()
+
+		notifier := &mockNotifier{
+			notify: func(_ context.Context, _ notify.Event, _ []backup.Dashboard) error {
+				return errors.New("webhook unavailable")
+			},
+		}
+
+		l, logs := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   newClient(),
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Notifier:  notifier,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), time.Now()))
+
+		//nolint:lll
+		expectedLog := `{"level":"ERROR","msg":"Failed to notify dashboard owners","dry":false,"namespace":"default","event":"deleted","error":"webhook unavailable"}`
+		assert.Contains(t, logs.String(), expectedLog)
+	})
+
+	t.Run("does not prune when organisation users cannot be fetched", func(t *testing.T) {
+		t.Parallel()
+
+		client := newClient()
+		client.orgUsers = func(_ context.Context, _ string) ([]OrgUser, error) {
+			return nil, errors.New("forbidden")
+		}
+		client.deleteDashboard = func(_ context.Context, dashboard *backup.Dashboard) error {
+			t.Errorf("unexpected deletion of dashboard %s", dashboard.Name)
+			return nil
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   client,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Notifier: &mockNotifier{
+				notify: func(_ context.Context, _ notify.Event, _ []backup.Dashboard) error {
+					return nil
+				},
+			},
+		})
+
+		err := pruner.pruneRun(t.Context(), time.Now())
+		require.EqualError(t, err, "fetching Grafana organisation users: forbidden")
+	})
+}
+
+type mockSnoozes struct {
+	snoozed func(namespace string, now time.Time) (map[string]time.Time, error)
+}
+
+func (m *mockSnoozes) Snoozed(namespace string, now time.Time) (map[string]time.Time, error) {
+	return m.snoozed(namespace, now)
+}
+
+func TestDashboardPruner_Snoozes(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2026, time.October, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		snoozed         func(namespace string, now time.Time) (map[string]time.Time, error)
+		expectedDeleted []string
+		expectedLogs    []string
+		expectedErr     string
+	}{
+		"skips snoozed dashboard": {
+			snoozed: func(namespace string, now time.Time) (map[string]time.Time, error) {
+				assert.Equal(t, "default", namespace)
+				assert.Equal(t, start, now)
+				return map[string]time.Time{"latency": until}, nil
+			},
+			expectedDeleted: []string{"errors"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Skipping snoozed dashboard","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","reason":"snoozed until 2026-10-31T12:00:00Z"}`,
+			},
+			expectedErr: "",
+		},
+		"does not prune when snoozes cannot be fetched": {
+			snoozed: func(_ string, _ time.Time) (map[string]time.Time, error) {
+				return nil, errors.New("corrupt state")
+			},
+			expectedDeleted: nil,
+			expectedLogs:    nil,
+			expectedErr:     "fetching snoozed dashboards: corrupt state",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return []Dashboard{
+						{UID: "uid1", Name: "latency", Namespace: "default"},
+						{UID: "uid2", Name: "errors", Namespace: "default"},
+					}, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:   mockClient,
+				Logger:    l,
+				Namespace: "default",
+				Interval:  time.Hour,
+				Period:    24 * time.Hour,
+				Labels:    map[string]string{"app": "grafana"},
+				Dry:       false,
+				Snoozes:   &mockSnoozes{snoozed: tt.snoozed},
+			})
+
+			err := pruner.pruneRun(t.Context(), start)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+		})
+	}
+}
+
+type mockApprovalRuns struct {
+	approvalRuns
+
+	open func(namespace string, now time.Time) ([]approvals.Run, error)
+}
+
+func (m *mockApprovalRuns) Open(namespace string, now time.Time) ([]approvals.Run, error) {
+	return m.open(namespace, now)
+}
+
+func TestDashboardPruner_Approvals(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("deletes approved candidates in the next run", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := approvals.NewRegistry(store, approvals.DefaultExpiry)
+
+		dashboards := []Dashboard{
+			{UID: "uid1", Name: "latency", Namespace: "default", Spec: json.RawMessage(`{"title":"Latency"}`)},
+			{UID: "uid2", Name: "errors", Namespace: "default", Spec: json.RawMessage(`{"title":"Errors"}`)},
+			{UID: "uid3", Name: "hosts", Namespace: "default", Spec: json.RawMessage(`{"title":"Hosts"}`)},
+			{UID: "uid4", Name: "cpu", Namespace: "default", Spec: json.RawMessage(`{"title":"CPU"}`)},
+		}
+		var reads []DashboardReads
+		var deleted []string
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return dashboards, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return reads, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deleted = append(deleted, dashboard.Name)
+				return nil
+			},
+		}
+
+		l, logs := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			MinReads:  5,
+			Approvals: registry,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+		assert.Empty(t, deleted, "candidates must not be deleted before they are approved")
+
+		runs, err := registry.List()
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, "default-20261001T120000Z", runs[0].ID)
+		assert.Equal(t, approvals.StatusPending, runs[0].Status)
+		require.Len(t, runs[0].Candidates, 4)
+		assert.Equal(t, approvals.Candidate{
+			Name:  "latency",
+			UID:   "uid1",
+			Title: "",
+			Hash:  backup.Hash([]byte(`{"title":"Latency"}`)),
+		}, runs[0].Candidates[0])
+
+		// Pending candidates are neither deleted nor proposed again.
+		require.NoError(t, pruner.pruneRun(t.Context(), start.Add(time.Hour)))
+		assert.Empty(t, deleted)
+		runs, err = registry.List()
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+
+		_, err = registry.Approve(runs[0].ID, []string{"hosts"}, start.Add(90*time.Minute))
+		require.NoError(t, err)
+
+		// errors was viewed and cpu changed after the run, and a new unused dashboard appeared.
+		reads = []DashboardReads{
+			{name: "errors", namespace: "default", reads: 1, users: 1, lastRead: start.Add(30 * time.Minute)},
+		}
+		dashboards[3].Spec = json.RawMessage(`{"title":"CPU usage"}`)
+		dashboards = append(dashboards, Dashboard{UID: "uid5", Name: "disk", Namespace: "default"})
+
+		second := start.Add(2 * time.Hour)
+		require.NoError(t, pruner.pruneRun(t.Context(), second))
+		assert.Equal(t, []string{"latency"}, deleted)
+
+		runs, err = registry.List()
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, approvals.StatusCompleted, runs[0].Status)
+		assert.Equal(t, second, runs[0].Completed)
+		assert.Equal(t, []string{"latency"}, runs[0].Deleted)
+		assert.Equal(t, []string{"errors", "cpu"}, runs[0].Dropped)
+
+		assert.Equal(t, "default-20261001T140000Z", runs[1].ID)
+		assert.Equal(t, approvals.StatusPending, runs[1].Status)
+		require.Len(t, runs[1].Candidates, 1)
+		assert.Equal(t, "disk", runs[1].Candidates[0].Name)
+
+		//nolint:lll
+		expectedLogs := []string{
+			`{"level":"INFO","msg":"Recorded deletion candidates awaiting approval","dry":false,"namespace":"default","run":"default-20261001T120000Z","candidate_count":4}`,
+			`{"level":"INFO","msg":"Skipping dashboard whose deletion awaits approval","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","run":"default-20261001T120000Z"}`,
+			`{"level":"INFO","msg":"Deleted dashboard whose deletion was approved","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","run":"default-20261001T120000Z"}`,
+			`{"level":"INFO","msg":"Dropping dashboard that was viewed after it became a deletion candidate","dry":false,"namespace":"default","uid":"uid2","name":"errors","title":"","run":"default-20261001T120000Z","last_viewed":"2026-10-01T12:30:00Z"}`,
+			`{"level":"INFO","msg":"Skipping dashboard excluded from approved deletion","dry":false,"namespace":"default","uid":"uid3","name":"hosts","title":"","run":"default-20261001T120000Z"}`,
+			`{"level":"INFO","msg":"Dropping dashboard that changed after it became a deletion candidate","dry":false,"namespace":"default","uid":"uid4","name":"cpu","title":"","run":"default-20261001T120000Z"}`,
+			`{"level":"INFO","msg":"Completed approved deletion run","dry":false,"namespace":"default","run":"default-20261001T120000Z","deleted_count":1,"dropped_count":2}`,
+		}
+		for _, expectedLog := range expectedLogs {
+			assert.Contains(t, logs.String(), expectedLog)
+		}
+	})
+
+	t.Run("does not prune when approval runs cannot be fetched", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{{UID: "uid1", Name: "latency", Namespace: "default"}}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			Approvals: &mockApprovalRuns{open: func(_ string, _ time.Time) ([]approvals.Run, error) {
+				return nil, errors.New("corrupt state")
+			}},
+		})
+
+		err := pruner.pruneRun(t.Context(), start)
+		require.EqualError(t, err, "fetching approval runs: corrupt state")
+	})
+
+	t.Run("records deleted candidates of runs that fail", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := approvals.NewRegistry(store, approvals.DefaultExpiry)
+
+		dashboards := []Dashboard{
+			{UID: "uid1", Name: "latency", Namespace: "default"},
+			{UID: "uid2", Name: "errors", Namespace: "default"},
+		}
+		var deleted []string
+		failDeletion := true
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return dashboards, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				if dashboard.Name == "errors" && failDeletion {
+					return errors.New("connection reset")
+				}
+				deleted = append(deleted, dashboard.Name)
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			Approvals: registry,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+		_, err = registry.Approve("default-20261001T120000Z", nil, start.Add(time.Minute))
+		require.NoError(t, err)
+
+		err = pruner.pruneRun(t.Context(), start.Add(time.Hour))
+		require.EqualError(t, err, "deleting approved dashboard uid2: connection reset")
+		assert.Equal(t, []string{"latency"}, deleted)
+
+		run, err := registry.Get("default-20261001T120000Z")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusApproved, run.Status)
+		assert.Equal(t, []string{"latency"}, run.Deleted)
+
+		// latency is gone, so the next run only deletes errors and must not report latency as dropped.
+		dashboards = dashboards[1:]
+		failDeletion = false
+		require.NoError(t, pruner.pruneRun(t.Context(), start.Add(2*time.Hour)))
+		assert.Equal(t, []string{"latency", "errors"}, deleted)
+
+		run, err = registry.Get("default-20261001T120000Z")
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusCompleted, run.Status)
+		assert.Equal(t, []string{"latency", "errors"}, run.Deleted)
+		assert.Empty(t, run.Dropped)
+	})
+
+	t.Run("proposes candidates of expired runs again", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := approvals.NewRegistry(store, 24*time.Hour)
+
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{{UID: "uid1", Name: "latency", Namespace: "default"}}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Dry:       false,
+			Approvals: registry,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+		require.NoError(t, pruner.pruneRun(t.Context(), start.Add(25*time.Hour)))
+
+		runs, err := registry.List()
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, approvals.StatusExpired, runs[0].Status)
+		assert.Equal(t, approvals.StatusPending, runs[1].Status)
+		require.Len(t, runs[1].Candidates, 1)
+		assert.Equal(t, "latency", runs[1].Candidates[0].Name)
+	})
+}
+
+func TestDashboardPruner_Archive(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "latency", Namespace: "default", Folder: "team-a"},
+		{
+			UID:         "uid2",
+			Name:        "errors",
+			Namespace:   "default",
+			Folder:      "frigg-archive",
+			Annotations: map[string]string{archivedAtAnnotation: "2026-09-30T12:00:00Z"},
+		},
+		{
+			UID:         "uid3",
+			Name:        "hosts",
+			Namespace:   "default",
+			Folder:      "frigg-archive",
+			Annotations: map[string]string{archivedAtAnnotation: "2026-08-31T12:00:00Z"},
+		},
+		{UID: "uid4", Name: "cpu", Namespace: "default", Folder: "team-a"},
+	}
+
+	tests := map[string]struct {
+		folders        []Folder
+		includeFolders []string
+		// maxDeletions is unlimited if zero.
+		maxDeletions     int
+		archiveErr       error
+		expectedFolders  []string
+		expectedArchived []string
+		expectedDeleted  []string
+		expectedLogs     []string
+		expectedErr      string
+	}{
+		"archives unused dashboards and deletes those whose archive period has ended": {
+			folders:          []Folder{{Name: "team-a", Title: "Team A"}},
+			expectedFolders:  []string{"frigg-archive"},
+			expectedArchived: []string{"latency", "cpu"},
+			expectedDeleted:  []string{"hosts"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Created archive folder","dry":false,"namespace":"default","folder":"frigg-archive","title":"Frigg Archive"}`,
+				`{"level":"INFO","msg":"Archived unused dashboard","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"","folder":"frigg-archive"}`,
+				`{"level":"INFO","msg":"Skipping archived dashboard until its archive period has ended","dry":false,"namespace":"default","uid":"uid2","name":"errors","title":"","delete_after":"2026-10-30T12:00:00Z"}`,
+				`{"level":"INFO","msg":"Found archived dashboard whose archive period has ended","dry":false,"namespace":"default","uid":"uid3","name":"hosts","title":"","archived_at":"2026-08-31T12:00:00Z"}`,
+				`{"level":"INFO","msg":"Archived unused Grafana dashboards","dry":false,"namespace":"default","archived_count":2,"archived_dashboards":"default/latency, default/cpu"}`,
+				`{"level":"INFO","msg":"Finished pruning Grafana dashboards","dry":false,"namespace":"default","deleted_count":1,"deleted_dashboards":"default/hosts"}`,
+			},
+		},
+		"does not create existing archive folder": {
+			folders: []Folder{
+				{Name: "team-a", Title: "Team A"},
+				{Name: "frigg-archive", Title: "Archive"},
+			},
+			expectedFolders:  nil,
+			expectedArchived: []string{"latency", "cpu"},
+			expectedDeleted:  []string{"hosts"},
+		},
+		"deletes archived dashboards outside included folders": {
+			folders:          []Folder{{Name: "team-a", Title: "Team A"}, {Name: "team-b", Title: "Team B"}},
+			includeFolders:   []string{"team-b"},
+			expectedFolders:  nil,
+			expectedArchived: nil,
+			expectedDeleted:  []string{"hosts"},
+		},
+		"limits archived and deleted dashboards": {
+			folders:          []Folder{{Name: "team-a", Title: "Team A"}},
+			maxDeletions:     2,
+			expectedFolders:  []string{"frigg-archive"},
+			expectedArchived: []string{"latency"},
+			expectedDeleted:  []string{"hosts"},
+		},
+		"fails if dashboard cannot be archived": {
+			folders:          []Folder{{Name: "team-a", Title: "Team A"}},
+			archiveErr:       errors.New("conflict"),
+			expectedFolders:  []string{"frigg-archive"},
+			expectedArchived: nil,
+			expectedDeleted:  nil,
+			expectedErr:      "archiving unused dashboard uid1: conflict",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var createdFolders, archived, deleted []string
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+					return tt.folders, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				createFolder: func(_ context.Context, namespace, name, title string) error {
+					assert.Equal(t, "default", namespace)
+					assert.Equal(t, "Frigg Archive", title)
+					createdFolders = append(createdFolders, name)
+					return nil
+				},
+				restrictFolder: func(_ context.Context, namespace, uid string) error {
+					assert.Equal(t, "default", namespace)
+					assert.Equal(t, "frigg-archive", uid)
+					return nil
+				},
+				archiveDashboard: func(_ context.Context, namespace, name, folder string, archivedAt time.Time) error {
+					if tt.archiveErr != nil {
+						return tt.archiveErr
+					}
+					assert.Equal(t, "default", namespace)
+					assert.Equal(t, "frigg-archive", folder)
+					assert.Equal(t, start, archivedAt)
+					archived = append(archived, name)
+					return nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					return nil
+				},
+			}
+
+			var maxDeletions *int
+			if tt.maxDeletions > 0 {
+				maxDeletions = &tt.maxDeletions
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:        mockClient,
+				Logger:         l,
+				Namespace:      "default",
+				Interval:       time.Hour,
+				Period:         24 * time.Hour,
+				Labels:         map[string]string{"app": "grafana"},
+				Dry:            false,
+				IncludeFolders: tt.includeFolders,
+				MaxDeletions:   maxDeletions,
+				Archive: &ArchiveConfig{
+					Folder: "frigg-archive",
+					Title:  "Frigg Archive",
+					Period: 30 * 24 * time.Hour,
+				},
+			})
+
+			err := pruner.pruneRun(t.Context(), start)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedFolders, createdFolders)
+			assert.Equal(t, tt.expectedArchived, archived)
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+		})
+	}
+
+	t.Run("restores original folder and notifies owners", func(t *testing.T) {
+		t.Parallel()
+
+		var deleted []*backup.Dashboard
+		notified := make(map[notify.Event][]string)
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{UID: "uid1", Name: "latency", Namespace: "default", Folder: "team-a"},
+					{
+						UID:       "uid3",
+						Name:      "hosts",
+						Namespace: "default",
+						Folder:    "frigg-archive",
+						Annotations: map[string]string{
+							archivedAtAnnotation:   "2026-08-31T12:00:00Z",
+							archivedFromAnnotation: "team-b",
+						},
+					},
+				}, nil
+			},
+			allFolders: func(_ context.Context, _ string) ([]Folder, error) {
+				return []Folder{
+					{Name: "team-a", Title: "Team A"},
+					{Name: "team-b", Title: "Team B"},
+					{Name: "frigg-archive", Title: "Frigg Archive"},
+				}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			orgUsers: func(_ context.Context, _ string) ([]OrgUser, error) {
+				return nil, nil
+			},
+			archiveDashboard: func(_ context.Context, _, _, _ string, _ time.Time) error {
+				return nil
+			},
+			deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+				deleted = append(deleted, dashboard)
+				return nil
+			},
+		}
+		notifier := &mockNotifier{
+			notify: func(_ context.Context, event notify.Event, dashboards []backup.Dashboard) error {
+				for _, dashboard := range dashboards {
+					notified[event] = append(notified[event], dashboard.Name+" in "+dashboard.FolderTitle)
+				}
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   mockClient,
+			Logger:    l,
+			Namespace: "default",
+			Interval:  time.Hour,
+			Period:    24 * time.Hour,
+			Labels:    map[string]string{"app": "grafana"},
+			Archive: &ArchiveConfig{
+				Folder: "frigg-archive",
+				Title:  "Frigg Archive",
+				Period: 30 * 24 * time.Hour,
+			},
+			Notifier: notifier,
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+		require.Len(t, deleted, 1)
+		assert.Equal(t, "hosts", deleted[0].Name)
+		assert.Equal(t, "team-b", deleted[0].FolderUID)
+		assert.Equal(t, "Team B", deleted[0].FolderTitle)
+		assert.Equal(
+			t,
+			map[notify.Event][]string{
+				notify.EventArchived: {"latency in Team A"},
+				notify.EventDeleted:  {"hosts in Team B"},
+			},
+			notified,
+		)
+	})
+}
+
+// tombstone is a tombstone that createDashboard received.
+type tombstone struct {
+	name        string
+	folder      string
+	annotations map[string]string
+	spec        any
+}
+
+func TestDashboardPruner_Tombstones(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	tombstoneSpec := json.RawMessage(`{"title": "Deleted", "editable": false}`)
+	hash, err := tombstoneHash(json.RawMessage(`{"editable":false,"title":"Deleted"}`))
+	require.NoError(t, err)
+	provisioner := "terraform"
+	dashboards := []Dashboard{
+		{UID: "uid1", Name: "latency", Namespace: "default", Title: "Latency", Folder: "team-a"},
+		{
+			UID:       "uid2",
+			Name:      "errors",
+			Namespace: "default",
+			Title:     "Errors (deleted)",
+			Spec:      tombstoneSpec,
+			Annotations: map[string]string{
+				tombstoneAnnotation:     "2026-09-30T12:00:00Z",
+				tombstoneHashAnnotation: hash,
+			},
+		},
+		{
+			UID:       "uid3",
+			Name:      "hosts",
+			Namespace: "default",
+			Title:     "Hosts (deleted)",
+			Spec:      tombstoneSpec,
+			Annotations: map[string]string{
+				tombstoneAnnotation:     "2026-08-31T12:00:00Z",
+				tombstoneHashAnnotation: hash,
+			},
+		},
+		{
+			UID:               "uid4",
+			Name:              "incident",
+			Namespace:         "default",
+			Title:             "Incident",
+			CreationTimestamp: start.Add(-48 * time.Hour),
+			TTL:               24 * time.Hour,
+		},
+		{
+			UID:       "uid5",
+			Name:      "disk",
+			Namespace: "default",
+			Title:     "Disk (deleted)",
+			Spec:      tombstoneSpec,
+			ManagedBy: &provisioner,
+			Annotations: map[string]string{
+				tombstoneAnnotation:     "2026-08-31T12:00:00Z",
+				tombstoneHashAnnotation: hash,
+			},
+		},
+		// cpu was restored over its tombstone, which kept the tombstone annotations.
+		{
+			UID:       "uid6",
+			Name:      "cpu",
+			Namespace: "default",
+			Title:     "CPU",
+			Spec:      json.RawMessage(`{"title": "CPU"}`),
+			Annotations: map[string]string{
+				tombstoneAnnotation:     "2026-08-31T12:00:00Z",
+				tombstoneHashAnnotation: hash,
+			},
+		},
+	}
+	//nolint:lll
+	latencyContent := "## Latency was deleted\n\n" +
+		"Frigg deleted this dashboard on 2026-10-01T12:00:00Z as it had not been used in the preceding 24h0m0s.\n\n" +
+		"The dashboard was backed up to https://github.com/octocat/hello-world/blob/main/latency.json.\n\n" +
+		"To restore the dashboard, download its backup and [import it](https://grafana.example.com/dashboard/import?orgId=1), overwriting this tombstone. The restored dashboard keeps its UID, so links to it work again.\n\n" +
+		"This tombstone is removed after 2026-10-31T12:00:00Z.\n"
+
+	tests := map[string]struct {
+		tombstone          *TombstoneConfig
+		maxDeletions       int
+		createErr          error
+		expectedDeleted    []string
+		expectedTombstones []string
+		expectedRemoved    []string
+		expectedLogs       []string
+	}{
+		"replaces deleted dashboards with tombstones and removes expired tombstones": {
+			tombstone:          &TombstoneConfig{Period: 30 * 24 * time.Hour},
+			maxDeletions:       0,
+			createErr:          nil,
+			expectedDeleted:    []string{"latency", "incident", "cpu"},
+			expectedTombstones: []string{"latency", "incident", "cpu"},
+			expectedRemoved:    []string{"hosts"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Created tombstone of deleted dashboard","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"Latency"}`,
+				`{"level":"DEBUG","msg":"Skipping tombstone of deleted dashboard","dry":false,"namespace":"default","uid":"uid2","name":"errors","title":"Errors (deleted)"}`,
+				`{"level":"INFO","msg":"Deleted expired tombstone","dry":false,"namespace":"default","uid":"uid3","name":"hosts","title":"Hosts (deleted)"}`,
+				`{"level":"DEBUG","msg":"Skipping provisioned tombstone","dry":false,"namespace":"default","uid":"uid5","name":"disk","title":"Disk (deleted)","managed_by":"terraform"}`,
+				`{"level":"INFO","msg":"Deleted unused dashboard","dry":false,"namespace":"default","uid":"uid6","name":"cpu","title":"CPU","raw_json":"{\"title\": \"CPU\"}"}`,
+			},
+		},
+		"counts removed tombstones towards the maximum deletions": {
+			tombstone:          &TombstoneConfig{Period: 30 * 24 * time.Hour},
+			maxDeletions:       2,
+			createErr:          nil,
+			expectedDeleted:    []string{"latency"},
+			expectedTombstones: []string{"latency"},
+			expectedRemoved:    []string{"hosts"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"INFO","msg":"Reached maximum deletion limit","dry":false,"namespace":"default","max_deletions":2,"remaining_unused_dashboards":2}`,
+			},
+		},
+		"keeps deleting when tombstone cannot be created": {
+			tombstone:          &TombstoneConfig{Period: 30 * 24 * time.Hour},
+			maxDeletions:       0,
+			createErr:          errors.New("conflict"),
+			expectedDeleted:    []string{"latency", "incident", "cpu"},
+			expectedTombstones: nil,
+			expectedRemoved:    []string{"hosts"},
+			//nolint:lll
+			expectedLogs: []string{
+				`{"level":"ERROR","msg":"Failed to create tombstone of deleted dashboard","dry":false,"namespace":"default","uid":"uid1","name":"latency","title":"Latency","error":"conflict"}`,
+			},
+		},
+		"never deletes tombstones when tombstones are disabled": {
+			tombstone:          nil,
+			maxDeletions:       0,
+			createErr:          nil,
+			expectedDeleted:    []string{"latency", "incident", "cpu"},
+			expectedTombstones: nil,
+			expectedRemoved:    nil,
+			expectedLogs:       nil,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var deleted, removed []string
+			var tombstones []tombstone
+			mockClient := &mockGrafanaClient{
+				allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+					return dashboards, nil
+				},
+				usedDashboards: func(
+					_ context.Context,
+					_ map[string]string,
+					_ time.Duration,
+					_ UsedDashboardsOptions,
+				) ([]DashboardReads, error) {
+					return nil, nil
+				},
+				deleteDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
+					deleted = append(deleted, dashboard.Name)
+					dashboard.Location = "https://github.com/octocat/hello-world/blob/main/" + dashboard.Name + ".json"
+					return nil
+				},
+				deleteBackedUpDashboard: func(_ context.Context, namespace, name string) error {
+					assert.Equal(t, "default", namespace)
+					removed = append(removed, name)
+					return nil
+				},
+				importURL: func(namespace string) (string, error) {
+					assert.Equal(t, "default", namespace)
+					return "https://grafana.example.com/dashboard/import?orgId=1", nil
+				},
+				createDashboard: func(
+					_ context.Context,
+					namespace, name, folder string,
+					annotations map[string]string,
+					spec any,
+				) error {
+					assert.Equal(t, "default", namespace)
+					if tt.createErr != nil {
+						return tt.createErr
+					}
+					tombstones = append(tombstones, tombstone{
+						name:        name,
+						folder:      folder,
+						annotations: annotations,
+						spec:        spec,
+					})
+					return nil
+				},
+			}
+
+			var maxDeletions *int
+			if tt.maxDeletions > 0 {
+				maxDeletions = &tt.maxDeletions
+			}
+
+			l, logs := logger()
+
+			pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+				Grafana:      mockClient,
+				Logger:       l,
+				Namespace:    "default",
+				Interval:     time.Hour,
+				Period:       24 * time.Hour,
+				Labels:       map[string]string{"app": "grafana"},
+				Dry:          false,
+				MaxDeletions: maxDeletions,
+				Tombstone:    tt.tombstone,
+			})
+
+			require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+			assert.Equal(t, tt.expectedDeleted, deleted)
+			assert.Equal(t, tt.expectedRemoved, removed)
+
+			names := make([]string, 0, len(tombstones))
+			for _, created := range tombstones {
+				names = append(names, created.name)
+			}
+			if tt.expectedTombstones == nil {
+				assert.Empty(t, names)
+			} else {
+				assert.Equal(t, tt.expectedTombstones, names)
+			}
+
+			for _, expectedLog := range tt.expectedLogs {
+				assert.Contains(t, logs.String(), expectedLog)
+			}
+
+			if len(tombstones) == 0 {
+				return
+			}
+
+			latency := tombstones[0]
+			assert.Equal(t, "team-a", latency.folder)
+			latencyHash, err := tombstoneHash(latency.spec)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				tombstoneAnnotation:     "2026-10-01T12:00:00Z",
+				tombstoneHashAnnotation: latencyHash,
+			}, latency.annotations)
+			assert.Equal(t, map[string]any{
+				"title":    "Latency (deleted)",
+				"editable": false,
+				"panels": []map[string]any{
+					{
+						"id":      1,
+						"type":    "text",
+						"title":   "",
+						"gridPos": map[string]int{"h": 12, "w": 24, "x": 0, "y": 0},
+						"options": map[string]any{
+							"mode":    "markdown",
+							"content": latencyContent,
+						},
+					},
+				},
+			}, latency.spec)
+
+			if len(tombstones) > 1 {
+				assert.Contains(t, fmt.Sprint(tombstones[1].spec), "as its TTL of 24h0m0s had expired.")
+			}
+		})
+	}
+	t.Run("links tombstones of approved dashboards to their recorded backups", func(t *testing.T) {
+		t.Parallel()
+
+		dashboardSpec := json.RawMessage(`{"title": "Latency"}`)
+		var tombstones []tombstone
+		mockClient := &mockGrafanaClient{
+			allDashboards: func(_ context.Context, _ string) ([]Dashboard, error) {
+				return []Dashboard{
+					{UID: "uid1", Name: "latency", Namespace: "default", Title: "Latency", Spec: dashboardSpec},
+					{UID: "uid2", Name: "errors", Namespace: "default", Title: "Errors", Spec: dashboardSpec},
+				}, nil
+			},
+			usedDashboards: func(
+				_ context.Context,
+				_ map[string]string,
+				_ time.Duration,
+				_ UsedDashboardsOptions,
+			) ([]DashboardReads, error) {
+				return nil, nil
+			},
+			deleteBackedUpDashboard: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+			importURL: func(_ string) (string, error) {
+				return "https://grafana.example.com/dashboard/import?orgId=1", nil
+			},
+			createDashboard: func(
+				_ context.Context,
+				_, name, _ string,
+				_ map[string]string,
+				spec any,
+			) error {
+				tombstones = append(tombstones, tombstone{name: name, spec: spec})
+				return nil
+			},
+		}
+
+		backups := &mockBackups{
+			finishRun: func(_ context.Context, _, _ string) error {
+				return nil
+			},
+			proposals: func(_ context.Context, _ string) ([]backup.Proposal, error) {
+				return []backup.Proposal{{
+					ID:        "2",
+					URL:       "https://github.com/octocat/hello-world/pull/2",
+					Namespace: "default",
+					State:     backup.ProposalApproved,
+					Dashboards: []backup.ProposedDashboard{
+						{
+							Name:     "latency",
+							Hash:     backup.Hash(dashboardSpec),
+							Path:     "latency.json",
+							Location: "https://github.com/octocat/hello-world/blob/main/latency.json",
+						},
+						// Proposals made before the paths of backups were recorded are linked instead.
+						{Name: "errors", Hash: backup.Hash(dashboardSpec)},
+					},
+				}}, nil
+			},
+			resolveProposal: func(_ context.Context, _ *backup.Proposal) error {
+				return nil
+			},
+		}
+
+		l, _ := logger()
+
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:       mockClient,
+			Logger:        l,
+			Namespace:     "default",
+			Interval:      time.Hour,
+			Period:        24 * time.Hour,
+			Labels:        map[string]string{"app": "grafana"},
+			Backups:       backups,
+			DeferDeletion: true,
+			Tombstone:     &TombstoneConfig{Period: 30 * 24 * time.Hour},
+		})
+
+		require.NoError(t, pruner.pruneRun(t.Context(), start))
+
+		require.Len(t, tombstones, 2)
+		assert.Equal(t, "latency", tombstones[0].name)
+		assert.Contains(
+			t,
+			fmt.Sprint(tombstones[0].spec),
+			"The dashboard was backed up to https://github.com/octocat/hello-world/blob/main/latency.json.",
+		)
+		assert.Equal(t, "errors", tombstones[1].name)
+		assert.Contains(
+			t,
+			fmt.Sprint(tombstones[1].spec),
+			"The dashboard was backed up to https://github.com/octocat/hello-world/pull/2.",
+		)
+	})
 }
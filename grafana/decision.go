@@ -0,0 +1,130 @@
+package grafana
+
+import (
+	"log/slog"
+	"time"
+)
+
+// runState holds what a prune run has gathered about the dashboards of the namespace before it handles each of them.
+type runState struct {
+	// start is the start time of the prune run.
+	start time.Time
+	// period is the period in which usage was analysed, see checkRetention.
+	period time.Duration
+	used   map[DashboardKey]DashboardReads
+	// bots are the users detected as bots in the prune run.
+	bots        []string
+	folders     map[string]Folder
+	decisions   map[DashboardKey]policyDecision
+	protections *protections
+	// creators maps user identities to the owners of the dashboards that they created, see creators.
+	creators   map[string]string
+	review     *proposalReview
+	candidates *candidateReview
+}
+
+// prunable returns true if dashboard is to be pruned, i.e., deleted, archived or proposed for deletion. A dashboard is
+// pruned if it is unused, forced by policy or expired, and nothing exempts or protects it. prunable logs why dashboard
+// is skipped and why it is pruned even though it is used.
+func (d *DashboardPruner) prunable(logger *slog.Logger, dashboard *Dashboard, run *runState) bool {
+	usage, isUsed := run.used[dashboard.Key()]
+	if reason, below := d.belowMinimumUsage(&usage); isUsed && below {
+		logger.Info(
+			"Considering dashboard unused as its usage is below the minimum",
+			slog.String("reason", reason),
+			slog.Int("reads", usage.Reads()),
+			slog.Int("users", usage.Users()),
+			slog.Int("min_reads", d.minReads),
+			slog.Int("min_users", d.minUsers),
+			slog.String("range", run.period.String()),
+		)
+		isUsed = false
+	}
+	if !isUsed && d.recentlyEdited(dashboard, run.start) {
+		logger.Info(
+			"Considering dashboard used as it was edited recently",
+			slog.String("last_modified", dashboard.LastModified().UTC().Format(time.RFC3339)),
+			slog.String("edit_window", d.editWindow.String()),
+		)
+		isUsed = true
+	}
+	decision := run.decisions[dashboard.Key()]
+	expired := dashboard.Expired(run.start)
+	if isUsed && !expired && decision.force == "" {
+		logger.Debug(
+			"Skipping used dashboard",
+			slog.Int("reads", usage.Reads()),
+			slog.Int("users", usage.Users()),
+			slog.String("range", run.period.String()),
+		)
+		return false
+	}
+
+	if dashboard.Provisioned() {
+		logger.Debug("Skipping provisioned dashboard", slog.String("managed_by", *dashboard.ManagedBy))
+		return false
+	}
+
+	if skip, matchedTag := d.hasSkipTag(dashboard); skip {
+		logger.Info(
+			"Skipping dashboard with skip tag",
+			slog.String("tag", matchedTag),
+			slog.Any("dashboard_tags", dashboard.Tags),
+		)
+		return false
+	}
+
+	if !d.included(dashboard, run.folders) {
+		logger.Debug("Skipping dashboard outside included folders", slog.String("folder", dashboard.Folder))
+		return false
+	}
+
+	if folder, skip := matchFolder(dashboard, run.folders, d.skipFolders); skip {
+		logger.Info("Skipping dashboard in skipped folder", slog.String("folder", folder))
+		return false
+	}
+
+	if decision.skip != "" {
+		logger.Info("Skipping dashboard matched by skip policy", slog.String("expression", decision.skip))
+		return false
+	}
+
+	if run.protections.protects(logger, dashboard) {
+		return false
+	}
+
+	if dashboard.Kept(run.start) {
+		logger.Info(
+			"Skipping dashboard that is kept until a later date",
+			slog.String("keep_until", dashboard.KeepUntil.UTC().Format(time.RFC3339)),
+		)
+		return false
+	}
+
+	if until, archived := d.archivedUntil(dashboard, run.start); archived {
+		logger.Info(
+			"Skipping archived dashboard until its archive period has ended",
+			slog.String("delete_after", until.UTC().Format(time.RFC3339)),
+		)
+		return false
+	}
+
+	if expired {
+		logger.Info(
+			"Found dashboard whose TTL has expired",
+			slog.String("ttl", dashboard.TTL.String()),
+			slog.String("created", dashboard.CreationTimestamp.UTC().Format(time.RFC3339)),
+			slog.Bool("used", isUsed),
+		)
+	}
+
+	if decision.force != "" {
+		logger.Info(
+			"Found dashboard matched by force policy",
+			slog.String("expression", decision.force),
+			slog.Bool("used", isUsed),
+		)
+	}
+
+	return true
+}
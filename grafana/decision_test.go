@@ -0,0 +1,152 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardPruner_Prunable(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	provisioner := "kubectl"
+	folders := map[string]Folder{
+		"team-a": {Name: "team-a", Title: "Team A"},
+		"team-b": {Name: "team-b", Title: "Team B"},
+	}
+	key := DashboardKey{name: "latency", namespace: "default"}
+
+	tests := map[string]struct {
+		opts        NewDashboardPrunerOptions
+		dashboard   Dashboard
+		used        map[DashboardKey]DashboardReads
+		decision    policyDecision
+		protections protections
+		expected    bool
+		expectedLog string
+	}{
+		"prunes unused dashboard": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			expected:    true,
+			expectedLog: "",
+		},
+		"skips used dashboard": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			used:        map[DashboardKey]DashboardReads{key: newMockDashboardReads("latency", 3, 2)},
+			expected:    false,
+			expectedLog: "Skipping used dashboard",
+		},
+		"prunes used dashboard whose usage is below the minimum": {
+			opts:        NewDashboardPrunerOptions{MinReads: 5},
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			used:        map[DashboardKey]DashboardReads{key: newMockDashboardReads("latency", 3, 2)},
+			expected:    true,
+			expectedLog: "Considering dashboard unused as its usage is below the minimum",
+		},
+		"skips unused dashboard that was edited recently": {
+			opts:        NewDashboardPrunerOptions{EditWindow: 24 * time.Hour},
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", CreationTimestamp: start.Add(-time.Hour)},
+			expected:    false,
+			expectedLog: "Considering dashboard used as it was edited recently",
+		},
+		"prunes used dashboard whose TTL has expired": {
+			dashboard: Dashboard{
+				Name:              "latency",
+				Namespace:         "default",
+				CreationTimestamp: start.Add(-48 * time.Hour),
+				TTL:               24 * time.Hour,
+			},
+			used:        map[DashboardKey]DashboardReads{key: newMockDashboardReads("latency", 3, 2)},
+			expected:    true,
+			expectedLog: "Found dashboard whose TTL has expired",
+		},
+		"prunes used dashboard matched by force policy": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			used:        map[DashboardKey]DashboardReads{key: newMockDashboardReads("latency", 3, 2)},
+			decision:    policyDecision{force: `dashboard.title == "Latency"`},
+			expected:    true,
+			expectedLog: "Found dashboard matched by force policy",
+		},
+		"skips provisioned dashboard": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", ManagedBy: &provisioner},
+			expected:    false,
+			expectedLog: "Skipping provisioned dashboard",
+		},
+		"skips dashboard with skip tag": {
+			opts:        NewDashboardPrunerOptions{SkipTags: []string{"keep"}},
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", Tags: []string{"keep"}},
+			expected:    false,
+			expectedLog: "Skipping dashboard with skip tag",
+		},
+		"skips dashboard outside included folders": {
+			opts:        NewDashboardPrunerOptions{IncludeFolders: []string{"Team A"}},
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", Folder: "team-b"},
+			expected:    false,
+			expectedLog: "Skipping dashboard outside included folders",
+		},
+		"skips dashboard in skipped folder": {
+			opts:        NewDashboardPrunerOptions{SkipFolders: []string{"Team B"}},
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", Folder: "team-b"},
+			expected:    false,
+			expectedLog: "Skipping dashboard in skipped folder",
+		},
+		"skips dashboard matched by skip policy": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			decision:    policyDecision{skip: `"keep" in dashboard.tags`},
+			expected:    false,
+			expectedLog: "Skipping dashboard matched by skip policy",
+		},
+		"skips protected dashboard": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default"},
+			protections: protections{starred: map[DashboardKey]string{key: "starred by 2 user(s)"}},
+			expected:    false,
+			expectedLog: "Skipping starred dashboard",
+		},
+		"skips dashboard that is kept until a later date": {
+			dashboard:   Dashboard{Name: "latency", Namespace: "default", KeepUntil: start.Add(time.Hour)},
+			expected:    false,
+			expectedLog: "Skipping dashboard that is kept until a later date",
+		},
+		"skips archived dashboard until its archive period has ended": {
+			opts: NewDashboardPrunerOptions{Archive: &ArchiveConfig{Folder: "frigg-archive", Period: 24 * time.Hour}},
+			dashboard: Dashboard{
+				Name:        "latency",
+				Namespace:   "default",
+				Folder:      "frigg-archive",
+				Annotations: map[string]string{archivedAtAnnotation: "2026-10-01T00:00:00Z"},
+			},
+			expected:    false,
+			expectedLog: "Skipping archived dashboard until its archive period has ended",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l, logs := logger()
+			opts := tc.opts
+			opts.Logger = l
+			opts.Namespace = "default"
+			pruner := NewDashboardPruner(&opts)
+
+			run := &runState{
+				start:       start,
+				period:      24 * time.Hour,
+				used:        tc.used,
+				folders:     folders,
+				decisions:   map[DashboardKey]policyDecision{key: tc.decision},
+				protections: &tc.protections,
+			}
+
+			assert.Equal(t, tc.expected, pruner.prunable(l, &tc.dashboard, run))
+			if tc.expectedLog == "" {
+				assert.Empty(t, logs.String())
+			} else {
+				assert.Contains(t, logs.String(), `"msg":"`+tc.expectedLog+`"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package grafana
+
+import "strings"
+
+// maxFolderDepth bounds the walk up the folder hierarchy in case folders form a cycle.
+const maxFolderDepth = 64
+
+// matchFolder returns the entry of patterns that matches the folder of dashboard or any of its ancestor folders.
+// folders holds all folders of the namespace keyed by their name.
+//
+// A pattern matches a folder if it is equal to the folder's UID or to the folder's title path, i.e., the titles of
+// the folder and its ancestors separated by "/", e.g., "Shared/Team A". The returned bool is false if no pattern
+// matches, which is always the case for dashboards at the root.
+func matchFolder(dashboard *Dashboard, folders map[string]Folder, patterns []string) (string, bool) {
+	if len(patterns) == 0 || dashboard.Folder == "" {
+		return "", false
+	}
+
+	// chain holds the dashboard's folder followed by its ancestors.
+	var chain []Folder
+	for name := dashboard.Folder; name != "" && len(chain) < maxFolderDepth; {
+		folder, ok := folders[name]
+		if !ok {
+			// The folder is unknown, e.g., because the token cannot list it. Only its UID can be matched.
+			folder = Folder{Name: name}
+		}
+		chain = append(chain, folder)
+		name = folder.Parent
+	}
+
+	for i := range chain {
+		titles := make([]string, 0, len(chain)-i)
+		for j := len(chain) - 1; j >= i; j-- {
+			titles = append(titles, chain[j].Title)
+		}
+		path := strings.Join(titles, "/")
+
+		for _, pattern := range patterns {
+			if pattern == chain[i].Name || pattern == path {
+				return pattern, true
+			}
+		}
+	}
+
+	return "", false
+}
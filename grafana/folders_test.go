@@ -0,0 +1,92 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchFolder(t *testing.T) {
+	t.Parallel()
+
+	folders := map[string]Folder{
+		"shared": {Name: "shared", Title: "Shared"},
+		"team-a": {Name: "team-a", Title: "Team A", Parent: "shared"},
+		"alerts": {Name: "alerts", Title: "Alerts", Parent: "team-a"},
+		"orphan": {Name: "orphan", Title: "Orphan", Parent: "unknown"},
+		"cycle1": {Name: "cycle1", Title: "Cycle 1", Parent: "cycle2"},
+		"cycle2": {Name: "cycle2", Title: "Cycle 2", Parent: "cycle1"},
+	}
+
+	tests := map[string]struct {
+		folder          string
+		patterns        []string
+		expectedPattern string
+		expectedMatch   bool
+	}{
+		"matches folder by UID": {
+			folder:          "team-a",
+			patterns:        []string{"team-a"},
+			expectedPattern: "team-a",
+			expectedMatch:   true,
+		},
+		"matches folder by title path": {
+			folder:          "team-a",
+			patterns:        []string{"Shared/Team A"},
+			expectedPattern: "Shared/Team A",
+			expectedMatch:   true,
+		},
+		"matches nested subfolder by ancestor UID": {
+			folder:          "alerts",
+			patterns:        []string{"other", "shared"},
+			expectedPattern: "shared",
+			expectedMatch:   true,
+		},
+		"matches nested subfolder by ancestor title path": {
+			folder:          "alerts",
+			patterns:        []string{"Shared/Team A"},
+			expectedPattern: "Shared/Team A",
+			expectedMatch:   true,
+		},
+		"does not match title that is not a full path": {
+			folder:          "alerts",
+			patterns:        []string{"Team A", "Alerts"},
+			expectedPattern: "",
+			expectedMatch:   false,
+		},
+		"does not match sibling folder": {
+			folder:          "shared",
+			patterns:        []string{"team-a", "Shared/Team A"},
+			expectedPattern: "",
+			expectedMatch:   false,
+		},
+		"does not match dashboard at the root": {
+			folder:          "",
+			patterns:        []string{"shared"},
+			expectedPattern: "",
+			expectedMatch:   false,
+		},
+		"matches unknown parent by UID": {
+			folder:          "orphan",
+			patterns:        []string{"unknown"},
+			expectedPattern: "unknown",
+			expectedMatch:   true,
+		},
+		"terminates on folder cycle": {
+			folder:          "cycle1",
+			patterns:        []string{"Shared"},
+			expectedPattern: "",
+			expectedMatch:   false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, match := matchFolder(&Dashboard{Folder: tt.folder}, folders, tt.patterns)
+			assert.Equal(t, tt.expectedPattern, pattern)
+			assert.Equal(t, tt.expectedMatch, match)
+		})
+	}
+}
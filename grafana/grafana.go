@@ -1,6 +1,7 @@
 package grafana
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,17 +9,21 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/LasseHels/frigg/backup"
 	"github.com/LasseHels/frigg/loki"
 )
 
 type client interface {
 	QueryRange(ctx context.Context, query string, start, end time.Time) ([]loki.Log, error)
+	OldestLog(ctx context.Context, query string, start, end time.Time) (loki.Log, bool, error)
 }
 
 type httpClient interface {
@@ -26,9 +31,16 @@ type httpClient interface {
 }
 
 type storage interface {
-	BackUpDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error
+	BackUpDashboard(ctx context.Context, dashboard *backup.Dashboard) error
 }
 
+// folderAnnotation is the annotation that holds the name of the folder that contains a dashboard or folder.
+const folderAnnotation = "grafana.app/folder"
+
+// updatedTimestampAnnotation is the annotation that holds the time at which a dashboard was last saved. Grafana only
+// sets the annotation once a dashboard has been saved after its creation.
+const updatedTimestampAnnotation = "grafana.app/updatedTimestamp"
+
 // errUnexpectedPathPartCount is returned by extractPathVariables when the path has an unexpected number of parts.
 var errUnexpectedPathPartCount = errors.New("unexpected path part count")
 
@@ -86,6 +98,9 @@ type UsedDashboardsOptions struct {
 	//
 	// By default, no users are ignored.
 	IgnoredUsers []string
+	// IgnoredUserPatterns are regular expressions that ignore the reads of every user whose name matches any of them,
+	// just like IgnoredUsers. For example, "^sa-" ignores the reads of all users whose name starts with "sa-".
+	IgnoredUserPatterns []*regexp.Regexp
 	// ChunkSize used when querying logs. To avoid executing a single large query, Client chunks queries into smaller
 	// queries of this size. For example, if ChunkSize is two hours, Client splits a single 10-hour query into five
 	// smaller two-hour queries.
@@ -104,6 +119,22 @@ type UsedDashboardsOptions struct {
 	//
 	// LowerThreshold defaults to 10.
 	LowerThreshold int
+	// BotDetection detects users that read dashboards in bulk, e.g., scripts and crawlers. Reads by detected users do
+	// not count towards dashboard reads, just like reads by IgnoredUsers. See Usage.Bots.
+	//
+	// If nil, no users are detected.
+	BotDetection *BotDetectionConfig
+	// QueryReads makes UsedDashboards count the datasource queries that dashboards send as usage, see
+	// DashboardReads.QueryReads. Dashboards on wall-mounted displays are loaded once and then only refresh their panels,
+	// so they are rarely read otherwise.
+	//
+	// UsedDashboards finds queries through the "Plugin Request Completed" logs that Grafana emits for datasource
+	// queries that carry the UID of the dashboard that sent them. Query logs do not count towards LowerThreshold.
+	QueryReads bool
+	// Namespace that Frigg prunes. Datasource query logs identify the organisation of a dashboard by its ID rather than
+	// by its namespace; UsedDashboards attributes the queries of Namespace's organisation to Namespace, which is
+	// required for Grafana Cloud stacks. See namespaceOf.
+	Namespace string
 }
 
 // validate checks that the options are valid.
@@ -117,11 +148,41 @@ func (o *UsedDashboardsOptions) validate() error {
 	return nil
 }
 
+// Usage is the dashboard usage found by Client.UsedDashboards.
+type Usage struct {
+	// Dashboards that have been read, sorted by name.
+	Dashboards []DashboardReads
+	// LogCount is the number of dashboard read logs found in the range, including reads by ignored users.
+	LogCount int
+	// ChunkLogCounts is the number of dashboard read logs found in each chunk of the range, in chronological order.
+	// A trailing chunk that is shorter than UsedDashboardsOptions.ChunkSize is not included, as a short chunk may hold
+	// no logs even if Grafana logs are ingested as usual.
+	ChunkLogCounts []int
+	// Bots are the users detected by UsedDashboardsOptions.BotDetection, sorted by user. Their reads are not included in
+	// Dashboards.
+	Bots []Bot
+	// QueryLogCount is the number of datasource query logs found in the range if UsedDashboardsOptions.QueryReads is
+	// set, including queries by ignored users.
+	QueryLogCount int
+	// rawReads is the number of reads and datasource queries of each dashboard, including those by ignored users and
+	// bots.
+	rawReads map[DashboardKey]int
+}
+
+// RawReads returns the number of reads and datasource queries of the dashboard with name in namespace, including those
+// by ignored users and detected bots, which are not counted in Dashboards.
+func (u *Usage) RawReads(namespace, name string) int {
+	return u.rawReads[DashboardKey{name: name, namespace: namespace}]
+}
+
 type DashboardReads struct {
-	name      string
-	namespace string
-	reads     int
-	users     int
+	name       string
+	namespace  string
+	reads      int
+	queryReads int
+	users      int
+	userNames  []string
+	lastRead   time.Time
 }
 
 // Name of the dashboard.
@@ -139,11 +200,27 @@ func (d *DashboardReads) Reads() int {
 	return d.reads
 }
 
-// Users is the number of unique users that have read the dashboard.
+// QueryReads is the number of datasource queries that the dashboard has sent. QueryReads is zero unless
+// UsedDashboardsOptions.QueryReads is set.
+func (d *DashboardReads) QueryReads() int {
+	return d.queryReads
+}
+
+// Users is the number of unique users that have read the dashboard or sent its queries.
 func (d *DashboardReads) Users() int {
 	return d.users
 }
 
+// UserNames are the names of the unique users that have read the dashboard, sorted by name.
+func (d *DashboardReads) UserNames() []string {
+	return d.userNames
+}
+
+// LastRead is the time of the most recent read of the dashboard.
+func (d *DashboardReads) LastRead() time.Time {
+	return d.lastRead
+}
+
 func (d *DashboardReads) Key() DashboardKey {
 	return DashboardKey{
 		name:      d.name,
@@ -230,8 +307,8 @@ func extractPathVariables(path string) (DashboardKey, error) {
 
 // UsedDashboards returns information about dashboard usage in range (now() - r) to now().
 //
-// A used dashboard is one that has been read by an un-ignored user (see UsedDashboardsOptions.IgnoredUsers) in the
-// given range.
+// A used dashboard is one that has been read by an un-ignored user (see UsedDashboardsOptions.IgnoredUsers) that is not
+// detected as a bot (see UsedDashboardsOptions.BotDetection) in the given range.
 //
 // UsedDashboards errors if labels is empty.
 //
@@ -242,7 +319,7 @@ func (c *Client) UsedDashboards(
 	labels map[string]string,
 	r time.Duration,
 	opts UsedDashboardsOptions,
-) ([]DashboardReads, error) {
+) (*Usage, error) {
 	if len(labels) == 0 {
 		return nil, errors.New("labels must not be empty")
 	}
@@ -263,7 +340,7 @@ func (c *Client) UsedDashboards(
 	end := time.Now().UTC()
 	start := end.Add(-r)
 
-	logs, err := c.queryLogs(ctx, query, start, end, opts.ChunkSize)
+	logs, chunkLogCounts, err := c.queryLogs(ctx, query, start, end, opts.ChunkSize)
 	if err != nil {
 		return nil, err
 	}
@@ -272,25 +349,104 @@ func (c *Client) UsedDashboards(
 		return nil, fmt.Errorf("found fewer logs (%d) than the lower threshold (%d)", len(logs), opts.LowerThreshold)
 	}
 
-	ignoredUsers := make(map[string]struct{})
-	for _, user := range opts.IgnoredUsers {
-		ignoredUsers[user] = struct{}{}
+	var queryLogs []loki.Log
+	if opts.QueryReads {
+		queryLogs, _, err = c.queryLogs(ctx, buildQueryLogQuery(labels), start, end, opts.ChunkSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ignoredUsers := newUserFilter(opts.IgnoredUsers, opts.IgnoredUserPatterns)
+
+	usage, err := c.processLogs(logs, queryLogs, ignoredUsers, opts.BotDetection, opts.Namespace)
+	if err != nil {
+		return nil, err
 	}
 
-	return c.processLogs(logs, ignoredUsers)
+	usage.LogCount = len(logs)
+	usage.ChunkLogCounts = chunkLogCounts
+	usage.QueryLogCount = len(queryLogs)
+
+	return usage, nil
 }
 
-// buildLogQuery constructs a LogQL query for finding dashboard read logs.
-func buildLogQuery(labels map[string]string) string {
+// OldestLogTime returns the time of the oldest Grafana log with labels in the given range. The returned bool is false
+// if no log is found in the range.
+//
+// Unlike UsedDashboards, OldestLogTime considers all Grafana logs and not just dashboard read logs. Grafana emits logs
+// continuously, so the oldest log approximates how far back Loki holds logs.
+//
+// OldestLogTime probes the range in chunks of chunkSize so that no request exceeds the maximum query length of Loki.
+// chunkSize defaults to four hours, like UsedDashboardsOptions.ChunkSize. As Loki removes logs from the oldest end,
+// the chunks without logs precede the chunks with logs, and OldestLogTime binary-searches for the oldest chunk with
+// logs. The number of requests is therefore logarithmic in the number of chunks, even if the range is much longer than
+// the retention of Loki. A gap in log ingestion may make OldestLogTime return a later time than the oldest log, which
+// errs on the side of treating the retention of Loki as shorter than it is.
+func (c *Client) OldestLogTime(
+	ctx context.Context,
+	labels map[string]string,
+	r time.Duration,
+	chunkSize time.Duration,
+) (time.Time, bool, error) {
+	if len(labels) == 0 {
+		return time.Time{}, false, errors.New("labels must not be empty")
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = 4 * time.Hour
+	}
+
+	selector := buildSelector(labels)
+	end := time.Now().UTC()
+	start := end.Add(-r)
+	chunks := int((r + chunkSize - 1) / chunkSize)
+
+	// The oldest chunk with logs is in [low, high]. high is chunks if no chunk has been found to hold logs.
+	low, high := 0, chunks
+	var oldest time.Time
+	for low < high {
+		mid := low + (high-low)/2
+
+		chunkStart := start.Add(time.Duration(mid) * chunkSize)
+		chunkEnd := chunkStart.Add(chunkSize)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		log, found, err := c.client.OldestLog(ctx, selector, chunkStart, chunkEnd)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("querying loki: %w", err)
+		}
+
+		if found {
+			high = mid
+			oldest = log.Timestamp()
+		} else {
+			low = mid + 1
+		}
+	}
+
+	return oldest, high < chunks, nil
+}
+
+// buildSelector constructs a LogQL stream selector that matches labels.
+func buildSelector(labels map[string]string) string {
 	var labelParts []string
 	for k, v := range labels {
 		labelParts = append(labelParts, fmt.Sprintf(`%s=%q`, k, v))
 	}
+	sort.Strings(labelParts)
 	labelStr := strings.Join(labelParts, ", ")
 	if labelStr != "" {
 		labelStr = "{" + labelStr + "}"
 	}
 
+	return labelStr
+}
+
+// buildLogQuery constructs a LogQL query for finding dashboard read logs.
+func buildLogQuery(labels map[string]string) string {
 	return fmt.Sprintf(`%s
 |= "/apis/dashboard.grafana.app/"
 |= "/namespaces/"
@@ -298,18 +454,30 @@ func buildLogQuery(labels map[string]string) string {
 |= "Request Completed"
 | logfmt
 | method = "GET"
-| handler = "/apis/*"`, labelStr)
+| handler = "/apis/*"`, buildSelector(labels))
+}
+
+// buildQueryLogQuery constructs a LogQL query for finding logs of datasource queries sent by dashboards.
+func buildQueryLogQuery(labels map[string]string) string {
+	return fmt.Sprintf(`%s
+|= "Plugin Request Completed"
+|= "dashboardUID="
+| logfmt
+| endpoint = "queryData"
+| dashboardUID != ""`, buildSelector(labels))
 }
 
-// queryLogs executes Loki queries in time-based chunks to avoid large single queries.
+// queryLogs executes Loki queries in time-based chunks to avoid large single queries. queryLogs also returns the
+// number of logs found in each chunk that spans chunkSize.
 func (c *Client) queryLogs(
 	ctx context.Context,
 	query string,
 	start,
 	end time.Time,
 	chunkSize time.Duration,
-) ([]loki.Log, error) {
+) ([]loki.Log, []int, error) {
 	var logs []loki.Log
+	var chunkLogCounts []int
 
 	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.Add(chunkSize) {
 		chunkEnd := chunkStart.Add(chunkSize)
@@ -319,20 +487,30 @@ func (c *Client) queryLogs(
 
 		chunkLogs, err := c.client.QueryRange(ctx, query, chunkStart, chunkEnd)
 		if err != nil {
-			return nil, fmt.Errorf("querying loki: %w", err)
+			return nil, nil, fmt.Errorf("querying loki: %w", err)
 		}
 
 		logs = append(logs, chunkLogs...)
+		if chunkEnd.Sub(chunkStart) == chunkSize {
+			chunkLogCounts = append(chunkLogCounts, len(chunkLogs))
+		}
 	}
 
-	return logs, nil
+	return logs, chunkLogCounts, nil
 }
 
-// processLogs extracts dashboard read information from Grafana logs.
-// It returns a slice of DashboardReads sorted by dashboard name.
-func (c *Client) processLogs(logs []loki.Log, ignoredUsers map[string]struct{}) ([]DashboardReads, error) {
-	readsByUID := make(map[DashboardKey]map[string]struct{})
-	readCounts := make(map[DashboardKey]int)
+// processLogs extracts dashboard read information from Grafana logs and datasource query logs.
+// It returns the Usage of dashboards sorted by dashboard name along with the bots detected by botDetection and the raw
+// reads of each dashboard. processLogs does not set the log counts of Usage. See UsedDashboardsOptions.Namespace for
+// prunedNamespace.
+func (c *Client) processLogs(
+	logs []loki.Log,
+	queryLogs []loki.Log,
+	ignoredUsers *userFilter,
+	botDetection *BotDetectionConfig,
+	prunedNamespace string,
+) (*Usage, error) {
+	reads := make([]dashboardRead, 0, len(logs))
 
 	for _, log := range logs {
 		stream := log.Stream()
@@ -365,16 +543,65 @@ func (c *Client) processLogs(logs []loki.Log, ignoredUsers map[string]struct{})
 		//
 		// To err on the side of not erroneously deleting used dashboards, we consider such a log line as intent to view
 		// and count it as a view, even though we cannot attribute it to a specific user.
-		user := stream["uname"]
+		reads = append(reads, dashboardRead{key: key, user: stream["uname"], time: log.Timestamp()})
+	}
+
+	for _, log := range queryLogs {
+		stream := log.Stream()
+
+		// Datasource queries are sent to legacy API endpoints that identify the organisation by its ID rather than by
+		// its namespace.
+		namespace, err := namespaceOf(stream["orgId"], prunedNamespace)
+		if err != nil {
+			c.logger.Info("Skipping query log with unexpected organisation",
+				slog.String("dashboard_uid", stream["dashboardUID"]),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		// The UID of a dashboard is the name of the dashboard in Grafana v12's API. See extractPathVariables.
+		reads = append(reads, dashboardRead{
+			key:   DashboardKey{name: stream["dashboardUID"], namespace: namespace},
+			user:  stream["uname"],
+			time:  log.Timestamp(),
+			query: true,
+		})
+	}
+
+	bots := detectBots(reads, ignoredUsers, botDetection)
+	botUsers := make(map[string]struct{}, len(bots))
+	for _, bot := range bots {
+		botUsers[bot.User] = struct{}{}
+	}
+
+	readsByUID := make(map[DashboardKey]map[string]struct{})
+	readCounts := make(map[DashboardKey]int)
+	queryReadCounts := make(map[DashboardKey]int)
+	lastReads := make(map[DashboardKey]time.Time)
+	rawReads := make(map[DashboardKey]int)
+
+	for _, read := range reads {
+		key, user := read.key, read.user
+		rawReads[key]++
 
-		// Only check ignored users if we have a username. Empty username is never ignored.
+		// Only check ignored users and bots if we have a username. Empty username is never ignored.
 		if user != "" {
-			if _, ignored := ignoredUsers[user]; ignored {
+			if ignoredUsers.ignored(user) {
+				continue
+			}
+			if _, bot := botUsers[user]; bot {
 				continue
 			}
 		}
 
-		readCounts[key]++
+		if read.query {
+			queryReadCounts[key]++
+		} else {
+			readCounts[key]++
+		}
+		if read.time.After(lastReads[key]) {
+			lastReads[key] = read.time
+		}
 
 		if _, exists := readsByUID[key]; !exists {
 			readsByUID[key] = make(map[string]struct{})
@@ -388,11 +615,20 @@ func (c *Client) processLogs(logs []loki.Log, ignoredUsers map[string]struct{})
 
 	result := make([]DashboardReads, 0, len(readsByUID))
 	for vars, users := range readsByUID {
+		userNames := make([]string, 0, len(users))
+		for user := range users {
+			userNames = append(userNames, user)
+		}
+		sort.Strings(userNames)
+
 		result = append(result, DashboardReads{
-			name:      vars.name,
-			namespace: vars.namespace,
-			reads:     readCounts[vars],
-			users:     len(users),
+			name:       vars.name,
+			namespace:  vars.namespace,
+			reads:      readCounts[vars],
+			queryReads: queryReadCounts[vars],
+			users:      len(users),
+			userNames:  userNames,
+			lastRead:   lastReads[vars],
 		})
 	}
 
@@ -407,7 +643,7 @@ func (c *Client) processLogs(logs []loki.Log, ignoredUsers map[string]struct{})
 		return result[i].name < result[j].name
 	})
 
-	return result, nil
+	return &Usage{Dashboards: result, Bots: bots, rawReads: rawReads}, nil
 }
 
 type Dashboard struct {
@@ -419,6 +655,19 @@ type Dashboard struct {
 	Tags              []string        `json:"tags"`
 	Spec              json.RawMessage `json:"spec"`
 	ManagedBy         *string         `json:"managedBy,omitempty"`
+	// Folder is the name of the folder that contains the dashboard. Folder is empty for dashboards at the root.
+	Folder string `json:"folder,omitempty"`
+	// KeepUntil is the time until which the dashboard is protected from pruning, even if it is unused. KeepUntil is
+	// zero if the dashboard has no valid frigg-keep-until tag or annotation.
+	KeepUntil time.Time `json:"keepUntil,omitzero"`
+	// TTL is the duration after its creation at which the dashboard is deleted, even if it is used. TTL is zero if the
+	// dashboard has no valid frigg-ttl tag or annotation.
+	TTL time.Duration `json:"ttl,omitempty"`
+	// Annotations of the dashboard's metadata.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Updated is the time at which the dashboard was last saved. Updated is zero if the dashboard has not been saved
+	// since its creation.
+	Updated time.Time `json:"updated,omitzero"`
 }
 
 func (d *Dashboard) Key() DashboardKey {
@@ -435,6 +684,26 @@ func (d *Dashboard) Provisioned() bool {
 	return d.ManagedBy != nil
 }
 
+// LastModified returns the time at which the dashboard was last saved or, if it has not been saved since its creation,
+// the time at which it was created.
+func (d *Dashboard) LastModified() time.Time {
+	if d.Updated.IsZero() {
+		return d.CreationTimestamp
+	}
+
+	return d.Updated
+}
+
+// Kept returns true if the dashboard is protected from pruning at now by its KeepUntil time.
+func (d *Dashboard) Kept(now time.Time) bool {
+	return now.Before(d.KeepUntil)
+}
+
+// Expired returns true if the TTL of the dashboard has expired at now.
+func (d *Dashboard) Expired(now time.Time) bool {
+	return d.TTL > 0 && !now.Before(d.CreationTimestamp.Add(d.TTL))
+}
+
 func (d *Dashboard) HasTag(tag string) bool {
 	for _, t := range d.Tags {
 		if t == tag {
@@ -511,6 +780,72 @@ func (c *Client) dashboardsPage(
 ) ([]Dashboard, string, error) {
 	u := c.endpoint.JoinPath("apis", "dashboard.grafana.app", "v1beta1", "namespaces", namespace, "dashboards")
 
+	var response dashboardListResponse
+	if err := c.listPage(ctx, u, limit, continueToken, &response); err != nil {
+		return nil, "", err
+	}
+
+	dashboards := make([]Dashboard, 0, len(response.Items))
+	for i := range response.Items {
+		item := &response.Items[i]
+
+		var spec dashboardSpec
+		title := ""
+		var tags []string
+		if err := json.Unmarshal(item.Spec, &spec); err == nil {
+			title = spec.Title
+			tags = spec.Tags
+		}
+
+		var managedBy *string
+		if value, ok := item.Metadata.Annotations["grafana.app/managedBy"]; ok {
+			managedBy = &value
+		}
+
+		keepUntil, ttl, err := lifetimeOf(tags, item.Metadata.Annotations)
+		if err != nil {
+			c.logger.Warn(
+				"Ignoring invalid lifetime of dashboard",
+				slog.String("namespace", item.Metadata.Namespace),
+				slog.String("name", item.Metadata.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		var updated time.Time
+		if value, ok := item.Metadata.Annotations[updatedTimestampAnnotation]; ok {
+			if updated, err = time.Parse(time.RFC3339, value); err != nil {
+				c.logger.Warn(
+					"Ignoring invalid update time of dashboard",
+					slog.String("namespace", item.Metadata.Namespace),
+					slog.String("name", item.Metadata.Name),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+
+		dashboards = append(dashboards, Dashboard{
+			Name:              item.Metadata.Name,
+			Namespace:         item.Metadata.Namespace,
+			UID:               item.Metadata.UID,
+			CreationTimestamp: item.Metadata.CreationTimestamp,
+			Title:             title,
+			Tags:              tags,
+			Spec:              item.Spec,
+			ManagedBy:         managedBy,
+			Folder:            item.Metadata.Annotations[folderAnnotation],
+			KeepUntil:         keepUntil,
+			TTL:               ttl,
+			Annotations:       item.Metadata.Annotations,
+			Updated:           updated,
+		})
+	}
+
+	return dashboards, response.Metadata.Continue, nil
+}
+
+// listPage fetches a single page of a Kubernetes-style list endpoint of the Grafana API and decodes it into target.
+func (c *Client) listPage(ctx context.Context, u *url.URL, limit int, continueToken string, target any) error {
 	q := u.Query()
 	q.Set("limit", fmt.Sprintf("%d", limit))
 	if continueToken != "" {
@@ -519,65 +854,151 @@ func (c *Client) dashboardsPage(
 
 	u.RawQuery = q.Encode()
 
+	return c.getJSON(ctx, u, 0, target)
+}
+
+// getJSON sends a GET request to u and decodes the JSON response into target. orgID selects the organisation that a
+// request to Grafana's legacy HTTP API applies to. orgID is not sent if it is zero.
+func (c *Client) getJSON(ctx context.Context, u *url.URL, orgID int64, target any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "creating request")
+		return errors.Wrap(err, "creating request")
 	}
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(orgID, 10))
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "making request to Grafana")
+		return errors.Wrap(err, "making request to Grafana")
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf(
+		return fmt.Errorf(
 			"unexpected status code: %d, body: %s",
 			resp.StatusCode,
 			readResponseBody(resp.Body),
 		)
 	}
 
-	var response dashboardListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, "", errors.Wrap(err, "decoding response")
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return errors.Wrap(err, "decoding response")
 	}
 
-	dashboards := make([]Dashboard, 0, len(response.Items))
-	for i := range response.Items {
-		item := &response.Items[i]
+	return nil
+}
 
-		var spec dashboardSpec
-		title := ""
-		var tags []string
-		if err := json.Unmarshal(item.Spec, &spec); err == nil {
-			title = spec.Title
-			tags = spec.Tags
+// sendJSON sends a request with method and body encoded as JSON to u. sendJSON decodes the JSON response into target
+// unless target is nil. orgID selects the organisation like in getJSON.
+func (c *Client) sendJSON(ctx context.Context, method string, u *url.URL, orgID int64, body, target any) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "encoding request body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(buf))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(orgID, 10))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "making request to Grafana")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf(
+			"unexpected status code: %d, body: %s",
+			resp.StatusCode,
+			readResponseBody(resp.Body),
+		)
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return errors.Wrap(err, "decoding response")
+	}
+
+	return nil
+}
+
+// Folder is a Grafana folder.
+type Folder struct {
+	// Name is the folder's UID.
+	Name  string
+	Title string
+	// Parent is the name of the folder's parent folder. Parent is empty for folders at the root.
+	Parent string
+}
+
+type folderListResponse struct {
+	Metadata listMetadata `json:"metadata"`
+	Items    []folderItem `json:"items"`
+}
+
+type folderItem struct {
+	Metadata dashboardItemMetadata `json:"metadata"`
+	Spec     folderSpec            `json:"spec"`
+}
+
+type folderSpec struct {
+	Title string `json:"title"`
+}
+
+// AllFolders returns all folders from the specified namespace in the Grafana instance.
+//
+// AllFolders uses the Grafana HTTP API endpoint GET /apis/folder.grafana.app/v1beta1/namespaces/:namespace/folders.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/folder/.
+//
+// AllFolders handles pagination automatically and fetches all pages.
+func (c *Client) AllFolders(ctx context.Context, namespace string) ([]Folder, error) {
+	var folders []Folder
+	continueToken := ""
+
+	for {
+		u := c.endpoint.JoinPath("apis", "folder.grafana.app", "v1beta1", "namespaces", namespace, "folders")
+
+		var response folderListResponse
+		if err := c.listPage(ctx, u, 500, continueToken, &response); err != nil {
+			return nil, errors.Wrap(err, "getting folders page")
 		}
 
-		var managedBy *string
-		if value, ok := item.Metadata.Annotations["grafana.app/managedBy"]; ok {
-			managedBy = &value
+		for i := range response.Items {
+			item := &response.Items[i]
+			folders = append(folders, Folder{
+				Name:   item.Metadata.Name,
+				Title:  item.Spec.Title,
+				Parent: item.Metadata.Annotations[folderAnnotation],
+			})
 		}
 
-		dashboards = append(dashboards, Dashboard{
-			Name:              item.Metadata.Name,
-			Namespace:         item.Metadata.Namespace,
-			UID:               item.Metadata.UID,
-			CreationTimestamp: item.Metadata.CreationTimestamp,
-			Title:             title,
-			Tags:              tags,
-			Spec:              item.Spec,
-			ManagedBy:         managedBy,
-		})
+		if response.Metadata.Continue == "" {
+			break
+		}
+
+		continueToken = response.Metadata.Continue
 	}
 
-	return dashboards, response.Metadata.Continue, nil
+	return folders, nil
 }
 
 type deleteDashboardResponse struct {
@@ -589,21 +1010,33 @@ type deleteDashboardResponse struct {
 // The dashboard JSON is backed up using the configured storage before deletion. If the backup fails, the dashboard is
 // not deleted and an error is returned.
 //
-// DeleteDashboard uses the Grafana HTTP API endpoint DELETE
+// See DeleteBackedUpDashboard.
+func (c *Client) DeleteDashboard(ctx context.Context, dashboard *backup.Dashboard) error {
+	if dashboard.Name == "" {
+		return errors.New("dashboard name must not be empty")
+	}
+
+	if err := c.storage.BackUpDashboard(ctx, dashboard); err != nil {
+		return errors.Wrap(err, "backing up dashboard")
+	}
+
+	return c.DeleteBackedUpDashboard(ctx, dashboard.Namespace, dashboard.Name)
+}
+
+// DeleteBackedUpDashboard deletes a dashboard without backing it up first. DeleteBackedUpDashboard must only be used
+// for dashboards whose backup has already been stored, e.g., through a merged deletion proposal.
+//
+// DeleteBackedUpDashboard uses the Grafana HTTP API endpoint DELETE
 // /apis/dashboard.grafana.app/v1beta1/namespaces/:namespace/dashboards/:uid to delete a dashboard in Grafana v12.
 //
 // See [documentation].
 //
 // [documentation]: https://grafana.com/docs/grafana/v12.0/developers/http_api/dashboard/#delete-dashboard
-func (c *Client) DeleteDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error {
+func (c *Client) DeleteBackedUpDashboard(ctx context.Context, namespace, name string) error {
 	if name == "" {
 		return errors.New("dashboard name must not be empty")
 	}
 
-	if err := c.storage.BackUpDashboard(ctx, namespace, name, dashboardJSON); err != nil {
-		return errors.Wrap(err, "backing up dashboard")
-	}
-
 	u := c.endpoint.JoinPath("apis", "dashboard.grafana.app", "v1beta1", "namespaces", namespace, "dashboards", name)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), http.NoBody)
@@ -3,29 +3,50 @@ package grafana_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/LasseHels/frigg/backup"
 	"github.com/LasseHels/frigg/grafana"
 	"github.com/LasseHels/frigg/loki"
 )
 
 type mockClient struct {
 	logs []loki.Log
-	err  error
+	// queryLogs are returned for queries of datasource query logs.
+	queryLogs []loki.Log
+	err       error
+	// oldestLogQuery is the query of the last OldestLog call.
+	oldestLogQuery string
 }
 
-func (m *mockClient) QueryRange(_ context.Context, _ string, _, _ time.Time) ([]loki.Log, error) {
+func (m *mockClient) QueryRange(_ context.Context, query string, _, _ time.Time) ([]loki.Log, error) {
+	if strings.Contains(query, "Plugin Request Completed") {
+		return m.queryLogs, m.err
+	}
 	return m.logs, m.err
 }
 
+// OldestLog returns the first log of logs.
+func (m *mockClient) OldestLog(_ context.Context, query string, _, _ time.Time) (loki.Log, bool, error) {
+	m.oldestLogQuery = query
+	if m.err != nil || len(m.logs) == 0 {
+		return loki.Log{}, false, m.err
+	}
+	return m.logs[0], true, nil
+}
+
 func TestNewClient(t *testing.T) {
 	t.Parallel()
 
@@ -155,9 +176,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 				ChunkSize:      tc.chunkSize,
 			}
 
-			reads, err := g.UsedDashboards(t.Context(), tc.labels, time.Hour, opts)
+			usage, err := g.UsedDashboards(t.Context(), tc.labels, time.Hour, opts)
 			require.EqualError(t, err, tc.expectedErrText)
-			assert.Nil(t, reads)
+			assert.Nil(t, usage)
 		})
 	}
 
@@ -215,8 +236,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			LowerThreshold: 1,
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 2)
 
 		assert.Equal(t, "dashboard1", results[0].Name())
@@ -276,8 +298,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			LowerThreshold: 1,
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 2)
 
 		assert.Equal(t, "dashboard1", results[0].Name())
@@ -291,6 +314,36 @@ func TestClient_UsedDashboards(t *testing.T) {
 		assert.Equal(t, 2, results[1].Users())
 	})
 
+	t.Run("tracks user names and time of last read", func(t *testing.T) {
+		t.Parallel()
+
+		first := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+		path := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/dashboard1"
+		logs := []loki.Log{
+			loki.NewLog(first.Add(time.Hour), "log message 1", map[string]string{"path": path, "uname": "user2"}),
+			loki.NewLog(first, "log message 2", map[string]string{"path": path, "uname": "user1"}),
+			loki.NewLog(first.Add(time.Minute), "log message 3", map[string]string{"path": path, "uname": "user2"}),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, err: nil},
+			Token:  "pear",
+		})
+		require.NoError(t, err)
+
+		opts := grafana.UsedDashboardsOptions{
+			LowerThreshold: 1,
+		}
+
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 1)
+
+		assert.Equal(t, []string{"user1", "user2"}, usage.Dashboards[0].UserNames())
+		assert.Equal(t, first.Add(time.Hour), usage.Dashboards[0].LastRead())
+	})
+
 	t.Run("ignores specified users", func(t *testing.T) {
 		t.Parallel()
 
@@ -346,8 +399,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			IgnoredUsers:   []string{"ignoredUser"},
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 2) // dashboard2 should be excluded as it's only accessed by ignored user.
 
 		assert.Equal(t, "dashboard1", results[0].Name())
@@ -361,6 +415,215 @@ func TestClient_UsedDashboards(t *testing.T) {
 		assert.Equal(t, 1, results[1].Users())
 	})
 
+	t.Run("excludes reads by detected bots", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		read := func(name, user string) loki.Log {
+			path := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/" + name
+			return loki.NewLog(now, "log message", map[string]string{"path": path, "uname": user})
+		}
+		logs := []loki.Log{
+			read("dashboard1", "crawler"),
+			read("dashboard2", "crawler"),
+			read("dashboard3", "crawler"),
+			read("dashboard1", "user1"),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, err: nil},
+			Token:  "kiwi",
+		})
+		require.NoError(t, err)
+
+		opts := grafana.UsedDashboardsOptions{
+			LowerThreshold: 1,
+			BotDetection:   &grafana.BotDetectionConfig{MaxDashboards: 2, Window: time.Minute},
+		}
+
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 1)
+
+		assert.Equal(t, "dashboard1", usage.Dashboards[0].Name())
+		assert.Equal(t, []string{"user1"}, usage.Dashboards[0].UserNames())
+		assert.Equal(t, 4, usage.LogCount)
+		assert.Equal(t, []grafana.Bot{{
+			User:   "crawler",
+			Reason: "read 3 distinct dashboards within 1m0s, which exceeds the maximum of 2",
+		}}, usage.Bots)
+		assert.Equal(t, 2, usage.RawReads("default", "dashboard1"))
+		assert.Equal(t, 1, usage.RawReads("default", "dashboard2"))
+		assert.Equal(t, 0, usage.RawReads("default", "dashboard4"))
+	})
+
+	t.Run("ignores users matching patterns", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		read := func(name, user string) loki.Log {
+			path := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/" + name
+			return loki.NewLog(now, "log message", map[string]string{"path": path, "uname": user})
+		}
+		logs := []loki.Log{
+			read("dashboard1", "sa-1-exporter"),
+			read("dashboard1", "user1"),
+			read("dashboard2", "sa-1-exporter"),
+			read("dashboard3", "user-sa-2"),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, err: nil},
+			Token:  "mango",
+		})
+		require.NoError(t, err)
+
+		opts := grafana.UsedDashboardsOptions{
+			LowerThreshold:      1,
+			IgnoredUserPatterns: []*regexp.Regexp{regexp.MustCompile("^sa-")},
+		}
+
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 2)
+
+		assert.Equal(t, "dashboard1", usage.Dashboards[0].Name())
+		assert.Equal(t, []string{"user1"}, usage.Dashboards[0].UserNames())
+		assert.Equal(t, "dashboard3", usage.Dashboards[1].Name())
+	})
+
+	t.Run("counts datasource queries as query reads", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+		path := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/dashboard1"
+		logs := []loki.Log{
+			loki.NewLog(now, "log message", map[string]string{"path": path, "uname": "user1"}),
+		}
+		query := func(uid, orgID, user string, offset time.Duration) loki.Log {
+			return loki.NewLog(now.Add(offset), "query log", map[string]string{
+				"dashboardUID": uid,
+				"orgId":        orgID,
+				"uname":        user,
+				"endpoint":     "queryData",
+			})
+		}
+		queryLogs := []loki.Log{
+			query("dashboard1", "1", "noc-screen", time.Minute),
+			query("dashboard2", "1", "noc-screen", 0),
+			query("dashboard2", "1", "noc-screen", time.Hour),
+			query("dashboard2", "3", "user2", 0),
+			query("dashboard3", "1", "ignored", 0),
+			query("dashboard4", "0", "user3", 0),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, queryLogs: queryLogs, err: nil},
+			Token:  "papaya",
+		})
+		require.NoError(t, err)
+
+		opts := grafana.UsedDashboardsOptions{
+			LowerThreshold: 1,
+			IgnoredUsers:   []string{"ignored"},
+			QueryReads:     true,
+		}
+
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 3)
+		assert.Equal(t, 1, usage.LogCount)
+		assert.Equal(t, 6, usage.QueryLogCount)
+
+		assert.Equal(t, "dashboard1", usage.Dashboards[0].Name())
+		assert.Equal(t, 1, usage.Dashboards[0].Reads())
+		assert.Equal(t, 1, usage.Dashboards[0].QueryReads())
+		assert.Equal(t, []string{"noc-screen", "user1"}, usage.Dashboards[0].UserNames())
+		assert.Equal(t, now.Add(time.Minute), usage.Dashboards[0].LastRead())
+
+		assert.Equal(t, "dashboard2", usage.Dashboards[1].Name())
+		assert.Equal(t, "default", usage.Dashboards[1].Namespace())
+		assert.Equal(t, 0, usage.Dashboards[1].Reads())
+		assert.Equal(t, 2, usage.Dashboards[1].QueryReads())
+		assert.Equal(t, now.Add(time.Hour), usage.Dashboards[1].LastRead())
+
+		assert.Equal(t, "dashboard2", usage.Dashboards[2].Name())
+		assert.Equal(t, "org-3", usage.Dashboards[2].Namespace())
+		assert.Equal(t, 1, usage.Dashboards[2].QueryReads())
+	})
+
+	t.Run("attributes datasource queries to the namespace of a Grafana Cloud stack", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+		path := "/apis/dashboard.grafana.app/v1beta1/namespaces/stacks-123/dashboards/dashboard1"
+		logs := []loki.Log{
+			loki.NewLog(now, "log message", map[string]string{"path": path, "uname": "user1"}),
+		}
+		queryLogs := []loki.Log{
+			loki.NewLog(now, "query log", map[string]string{
+				"dashboardUID": "dashboard2",
+				"orgId":        "1",
+				"uname":        "noc-screen",
+				"endpoint":     "queryData",
+			}),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, queryLogs: queryLogs, err: nil},
+			Token:  "quince",
+		})
+		require.NoError(t, err)
+
+		opts := grafana.UsedDashboardsOptions{
+			LowerThreshold: 1,
+			QueryReads:     true,
+			Namespace:      "stacks-123",
+		}
+
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 2)
+
+		assert.Equal(t, "dashboard2", usage.Dashboards[1].Name())
+		assert.Equal(t, "stacks-123", usage.Dashboards[1].Namespace())
+		assert.Equal(t, 1, usage.Dashboards[1].QueryReads())
+	})
+
+	t.Run("does not query datasource query logs by default", func(t *testing.T) {
+		t.Parallel()
+
+		path := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/dashboard1"
+		logs := []loki.Log{
+			loki.NewLog(time.Now(), "log message", map[string]string{"path": path, "uname": "user1"}),
+		}
+		queryLogs := []loki.Log{
+			loki.NewLog(time.Now(), "query log", map[string]string{"dashboardUID": "dashboard2", "orgId": "1"}),
+		}
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger: slog.Default(),
+			Client: &mockClient{logs: logs, queryLogs: queryLogs, err: nil},
+			Token:  "guava",
+		})
+		require.NoError(t, err)
+
+		usage, err := g.UsedDashboards(
+			t.Context(),
+			map[string]string{"app": "grafana"},
+			time.Hour,
+			grafana.UsedDashboardsOptions{LowerThreshold: 1},
+		)
+		require.NoError(t, err)
+		require.Len(t, usage.Dashboards, 1)
+		assert.Equal(t, "dashboard1", usage.Dashboards[0].Name())
+		assert.Equal(t, 0, usage.QueryLogCount)
+	})
+
 	t.Run("with multiple chunks", func(t *testing.T) {
 		t.Parallel()
 
@@ -399,9 +662,12 @@ func TestClient_UsedDashboards(t *testing.T) {
 		}
 
 		// Use a 5-minute duration to ensure multiple chunks are created.
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, 5*time.Minute, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, 5*time.Minute, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 2)
+		assert.Equal(t, 10, usage.LogCount)
+		assert.Equal(t, []int{2, 2, 2, 2, 2}, usage.ChunkLogCounts)
 
 		// In our mock, each chunk returns the same 2 logs, so with 5 chunks:
 		// - dashboard1 should have 5 reads from user1.
@@ -463,8 +729,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			LowerThreshold: 1,
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 1)
 
 		// All three logs count as reads, but only one has a valid username.
@@ -511,8 +778,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			LowerThreshold: 1,
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 1)
 
 		// Dashboard is considered used even with zero identified users.
@@ -560,8 +828,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 			IgnoredUsers:   []string{"ignoredUser"},
 		}
 
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 1)
 
 		// The ignored user's read is filtered out, but the empty uname read counts.
@@ -629,8 +898,9 @@ func TestClient_UsedDashboards(t *testing.T) {
 		}
 
 		// Should succeed and return only the valid dashboards.
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		require.Len(t, results, 2)
 
 		assert.Equal(t, "dashboard1", results[0].Name())
@@ -683,12 +953,186 @@ func TestClient_UsedDashboards(t *testing.T) {
 		}
 
 		// Should succeed but return empty results since all logs have malformed paths.
-		results, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
+		usage, err := g.UsedDashboards(t.Context(), map[string]string{"app": "grafana"}, time.Hour, opts)
 		require.NoError(t, err)
+		results := usage.Dashboards
 		assert.Empty(t, results)
 	})
 }
 
+func TestClient_OldestLogTime(t *testing.T) {
+	t.Parallel()
+
+	oldest := time.Date(2026, time.September, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		labels         map[string]string
+		mockLogs       []loki.Log
+		mockErr        error
+		expectedTime   time.Time
+		expectedFound  bool
+		expectedQuery  string
+		expectedErrMsg string
+	}{
+		"returns time of oldest log": {
+			labels:         map[string]string{"app": "grafana", "env": "prod"},
+			mockLogs:       []loki.Log{loki.NewLog(oldest, "log message", nil)},
+			mockErr:        nil,
+			expectedTime:   oldest,
+			expectedFound:  true,
+			expectedQuery:  `{app="grafana", env="prod"}`,
+			expectedErrMsg: "",
+		},
+		"reports no log if none is found": {
+			labels:         map[string]string{"app": "grafana"},
+			mockLogs:       nil,
+			mockErr:        nil,
+			expectedTime:   time.Time{},
+			expectedFound:  false,
+			expectedQuery:  `{app="grafana"}`,
+			expectedErrMsg: "",
+		},
+		"errors if labels are empty": {
+			labels:         map[string]string{},
+			mockLogs:       nil,
+			mockErr:        nil,
+			expectedTime:   time.Time{},
+			expectedFound:  false,
+			expectedQuery:  "",
+			expectedErrMsg: "labels must not be empty",
+		},
+		"errors if query fails": {
+			labels:         map[string]string{"app": "grafana"},
+			mockLogs:       nil,
+			mockErr:        errors.New("loki is down"),
+			expectedTime:   time.Time{},
+			expectedFound:  false,
+			expectedQuery:  `{app="grafana"}`,
+			expectedErrMsg: "querying loki: loki is down",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &mockClient{logs: tt.mockLogs, err: tt.mockErr}
+
+			g, err := grafana.NewClient(&grafana.NewClientOptions{
+				Logger: slog.Default(),
+				Client: client,
+				Token:  "plum",
+			})
+			require.NoError(t, err)
+
+			oldestTime, found, err := g.OldestLogTime(t.Context(), tt.labels, 30*24*time.Hour, 24*time.Hour)
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedTime, oldestTime)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedQuery, client.oldestLogQuery)
+		})
+	}
+
+	t.Run("probes range in chunks", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now().UTC()
+		tests := map[string]struct {
+			retention      time.Duration
+			expectedOldest time.Duration
+			expectedFound  bool
+		}{
+			"loki holds logs for part of the range": {
+				retention:      (25*24 + 12) * time.Hour,
+				expectedOldest: (25*24 + 12) * time.Hour,
+				expectedFound:  true,
+			},
+			"loki holds logs for less than a chunk": {
+				retention:      time.Hour,
+				expectedOldest: time.Hour,
+				expectedFound:  true,
+			},
+			"loki holds logs for the entire range": {
+				retention: 90 * 24 * time.Hour,
+				// OldestLogTime never looks further back than the range.
+				expectedOldest: 60 * 24 * time.Hour,
+				expectedFound:  true,
+			},
+			"loki holds no logs": {
+				retention:      -time.Hour,
+				expectedOldest: 0,
+				expectedFound:  false,
+			},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				// Loki rejects queries longer than a day, like max_query_length.
+				client := &rangeLimitedClient{
+					oldest:         now.Add(-tc.retention),
+					maxQueryLength: 24 * time.Hour,
+				}
+
+				g, err := grafana.NewClient(&grafana.NewClientOptions{
+					Logger: slog.Default(),
+					Client: client,
+					Token:  "plum",
+				})
+				require.NoError(t, err)
+
+				labels := map[string]string{"app": "grafana"}
+				oldestTime, found, err := g.OldestLogTime(t.Context(), labels, 60*24*time.Hour, 24*time.Hour)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedFound, found)
+				if tc.expectedFound {
+					assert.WithinDuration(t, now.Add(-tc.expectedOldest), oldestTime, time.Minute)
+				}
+				// The 60 chunks are binary-searched.
+				assert.LessOrEqual(t, client.queries, 6)
+			})
+		}
+	})
+}
+
+// rangeLimitedClient holds Grafana logs from oldest onwards and rejects queries whose range exceeds maxQueryLength.
+type rangeLimitedClient struct {
+	mockClient
+
+	oldest         time.Time
+	maxQueryLength time.Duration
+	queries        int
+}
+
+// OldestLog returns the oldest log in the range.
+func (c *rangeLimitedClient) OldestLog(_ context.Context, _ string, start, end time.Time) (loki.Log, bool, error) {
+	c.queries++
+	if length := end.Sub(start); length > c.maxQueryLength {
+		return loki.Log{}, false, fmt.Errorf(
+			"the query time range exceeds the limit (query length: %s, limit: %s)",
+			length,
+			c.maxQueryLength,
+		)
+	}
+
+	if !c.oldest.Before(end) {
+		return loki.Log{}, false, nil
+	}
+
+	timestamp := c.oldest
+	if timestamp.Before(start) {
+		timestamp = start
+	}
+
+	return loki.NewLog(timestamp, "log message", nil), true, nil
+}
+
 func TestClient_AllDashboards(t *testing.T) {
 	t.Parallel()
 
@@ -846,6 +1290,7 @@ func TestClient_AllDashboards(t *testing.T) {
 		assert.Equal(t, "default", dashboards[0].Namespace)
 		assert.Equal(t, "VQyL7pNTpfGPNlPM6HRJSePrBg5dXmxr4iPQL7txLtwX", dashboards[0].UID)
 		assert.Equal(t, formattedTime, dashboards[0].CreationTimestamp.Format(time.RFC3339))
+		assert.Equal(t, "fef30w4jaxla8b", dashboards[0].Folder)
 		assert.JSONEq(
 			t,
 			`{"editable": true,"fiscalYearStartMonth": 0,"graphTooltip": 0,"time": {"from":`+
@@ -857,6 +1302,8 @@ func TestClient_AllDashboards(t *testing.T) {
 		assert.Equal(t, "default", dashboards[1].Namespace)
 		assert.Equal(t, "uid2", dashboards[1].UID)
 		assert.Equal(t, formattedTime, dashboards[1].CreationTimestamp.Format(time.RFC3339))
+		assert.Empty(t, dashboards[1].Folder)
+		assert.Equal(t, formattedTime, dashboards[1].Updated.Format(time.RFC3339))
 		assert.JSONEq(t, `{"schemaVersion": 41,"title": "Dashboard 2"}`, string(dashboards[1].Spec))
 
 		assert.Equal(t, 1, requestCount)
@@ -966,56 +1413,95 @@ func TestClient_AllDashboards(t *testing.T) {
 
 		assert.Equal(t, 2, requestCount)
 	})
-}
-
-func TestClient_DeleteDashboard(t *testing.T) {
-	t.Parallel()
 
-	t.Run("empty name", func(t *testing.T) {
+	t.Run("parses lifetime from tags and annotations", func(t *testing.T) {
 		t.Parallel()
 
-		g, err := grafana.NewClient(&grafana.NewClientOptions{
-			Logger:     slog.Default(),
-			HTTPClient: http.DefaultClient,
-			Endpoint:   mustParseURL(t, "https://grafana.example.com"),
-			Token:      "abc123",
-			Storage:    noopStorage,
-		})
-		require.NoError(t, err)
-
-		err = g.DeleteDashboard(t.Context(), "default", "", nil)
-		require.EqualError(t, err, "dashboard name must not be empty")
-	})
-
-	t.Run("server error", func(t *testing.T) {
-		t.Parallel()
+		rawJSON := `{
+			"kind": "DashboardList",
+			"apiVersion": "dashboard.grafana.app/v1beta1",
+			"metadata": {},
+			"items": [
+				{
+					"kind": "Dashboard",
+					"apiVersion": "dashboard.grafana.app/v1beta1",
+					"metadata": {
+						"name": "kept",
+						"namespace": "default",
+						"uid": "uid1",
+						"creationTimestamp": "2026-10-01T12:00:00Z",
+						"annotations": {
+							"frigg-ttl": "36h"
+						}
+					},
+					"spec": {
+						"title": "Kept",
+						"tags": ["frigg-keep-until:2026-12-31"]
+					}
+				},
+				{
+					"kind": "Dashboard",
+					"apiVersion": "dashboard.grafana.app/v1beta1",
+					"metadata": {
+						"name": "invalid",
+						"namespace": "default",
+						"uid": "uid2",
+						"creationTimestamp": "2026-10-01T12:00:00Z"
+					},
+					"spec": {
+						"title": "Invalid",
+						"tags": ["frigg-ttl:soon"]
+					}
+				}
+			]
+		}`
 
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusInternalServerError)
-			_, err := w.Write([]byte("server error"))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(rawJSON))
 			assert.NoError(t, err)
 		}))
 		defer server.Close()
 
+		var logs strings.Builder
 		g, err := grafana.NewClient(&grafana.NewClientOptions{
-			Logger:     slog.Default(),
+			Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
 			HTTPClient: http.DefaultClient,
 			Endpoint:   mustParseURL(t, server.URL),
 			Token:      "abc123",
-			Storage:    noopStorage,
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", nil)
-		require.EqualError(t, err, "unexpected status code: 500, body: server error")
+		dashboards, err := g.AllDashboards(t.Context(), "default")
+		require.NoError(t, err)
+		require.Len(t, dashboards, 2)
+
+		assert.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), dashboards[0].KeepUntil)
+		assert.Equal(t, 36*time.Hour, dashboards[0].TTL)
+
+		assert.True(t, dashboards[1].KeepUntil.IsZero())
+		assert.Zero(t, dashboards[1].TTL)
+		assert.Contains(
+			t,
+			logs.String(),
+			`"msg":"Ignoring invalid lifetime of dashboard","namespace":"default","name":"invalid","error":"parsing `+
+				`frigg-ttl \"soon\": expected a number of days or a duration: time: invalid duration \"soon\""`,
+		)
 	})
+}
 
-	t.Run("invalid json response", func(t *testing.T) {
+func TestClient_AllFolders(t *testing.T) {
+	t.Parallel()
+
+	t.Run("non-200 response", func(t *testing.T) {
 		t.Parallel()
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			_, err := w.Write([]byte("!!!"))
+			assert.Equal(t, "/apis/folder.grafana.app/v1beta1/namespaces/default/folders", r.URL.Path)
+			assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+			w.WriteHeader(http.StatusForbidden)
+			_, err := w.Write([]byte("forbidden"))
 			assert.NoError(t, err)
 		}))
 		defer server.Close()
@@ -1025,19 +1511,645 @@ func TestClient_DeleteDashboard(t *testing.T) {
 			HTTPClient: http.DefaultClient,
 			Endpoint:   mustParseURL(t, server.URL),
 			Token:      "abc123",
-			Storage:    noopStorage,
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", nil)
-		require.EqualError(
-			t,
-			err,
-			`decoding response: invalid character '!' looking for beginning of value`,
-		)
+		folders, err := g.AllFolders(t.Context(), "default")
+		require.EqualError(t, err, "getting folders page: unexpected status code: 403, body: forbidden")
+		assert.Nil(t, folders)
 	})
 
-	t.Run("non-success status in response", func(t *testing.T) {
+	t.Run("multiple pages", func(t *testing.T) {
+		t.Parallel()
+
+		firstPageJSON := `{
+			"kind": "FolderList",
+			"apiVersion": "folder.grafana.app/v1beta1",
+			"metadata": {
+				"continue": "next-page-token"
+			},
+			"items": [
+				{
+					"kind": "Folder",
+					"apiVersion": "folder.grafana.app/v1beta1",
+					"metadata": {
+						"name": "platform",
+						"namespace": "default",
+						"annotations": {
+							"grafana.app/createdBy": "service-account:cef2t2rfm73lsb"
+						}
+					},
+					"spec": {
+						"title": "Platform"
+					}
+				}
+			]
+		}`
+
+		secondPageJSON := `{
+			"kind": "FolderList",
+			"apiVersion": "folder.grafana.app/v1beta1",
+			"metadata": {
+				"continue": ""
+			},
+			"items": [
+				{
+					"kind": "Folder",
+					"apiVersion": "folder.grafana.app/v1beta1",
+					"metadata": {
+						"name": "databases",
+						"namespace": "default",
+						"annotations": {
+							"grafana.app/folder": "platform"
+						}
+					},
+					"spec": {
+						"title": "Databases"
+					}
+				}
+			]
+		}`
+
+		var requestCount int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			responseBody := firstPageJSON
+			if r.URL.Query().Get("continue") == "next-page-token" {
+				responseBody = secondPageJSON
+			}
+
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(responseBody))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		folders, err := g.AllFolders(t.Context(), "default")
+		require.NoError(t, err)
+
+		expected := []grafana.Folder{
+			{Name: "platform", Title: "Platform"},
+			{Name: "databases", Title: "Databases", Parent: "platform"},
+		}
+		assert.Equal(t, expected, folders)
+		assert.Equal(t, 2, requestCount)
+	})
+}
+
+func TestClient_OrgUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		namespace     string
+		expectedOrgID string
+		expectedUsers []grafana.OrgUser
+		expectedErr   string
+	}{
+		"default namespace": {
+			namespace:     "default",
+			expectedOrgID: "1",
+			expectedUsers: []grafana.OrgUser{{Login: "admin", Role: "Admin"}, {Login: "alice", Role: "Viewer"}},
+			expectedErr:   "",
+		},
+		"organisation namespace": {
+			namespace:     "org-3",
+			expectedOrgID: "3",
+			expectedUsers: []grafana.OrgUser{{Login: "admin", Role: "Admin"}, {Login: "alice", Role: "Viewer"}},
+			expectedErr:   "",
+		},
+		"stack namespace": {
+			namespace:     "stacks-1234",
+			expectedOrgID: "1",
+			expectedUsers: []grafana.OrgUser{{Login: "admin", Role: "Admin"}, {Login: "alice", Role: "Viewer"}},
+			expectedErr:   "",
+		},
+		"unexpected namespace": {
+			namespace:     "org-zero",
+			expectedOrgID: "",
+			expectedUsers: nil,
+			expectedErr:   `unexpected namespace format: "org-zero"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/org/users", r.URL.Path)
+				assert.Equal(t, tt.expectedOrgID, r.Header.Get("X-Grafana-Org-Id"))
+				assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+				_, err := w.Write([]byte(`[
+					{"orgId": 1, "userId": 1, "login": "admin", "role": "Admin"},
+					{"orgId": 1, "userId": 2, "login": "alice", "role": "Viewer"}
+				]`))
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			g, err := grafana.NewClient(&grafana.NewClientOptions{
+				Logger:     slog.Default(),
+				HTTPClient: http.DefaultClient,
+				Endpoint:   mustParseURL(t, server.URL),
+				Token:      "abc123",
+			})
+			require.NoError(t, err)
+
+			users, err := g.OrgUsers(t.Context(), tt.namespace)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedUsers, users)
+		})
+	}
+}
+
+func TestClient_ServiceAccounts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("non-200 response", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, err := w.Write([]byte("forbidden"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		logins, err := g.ServiceAccounts(t.Context(), "default")
+		require.EqualError(t, err, "getting service accounts page: unexpected status code: 403, body: forbidden")
+		assert.Nil(t, logins)
+	})
+
+	t.Run("multiple pages", func(t *testing.T) {
+		t.Parallel()
+
+		var pages []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/serviceaccounts/search", r.URL.Path)
+			assert.Equal(t, "500", r.URL.Query().Get("perpage"))
+			pages = append(pages, r.URL.Query().Get("page"))
+
+			responseBody := `{"totalCount": 2, "serviceAccounts": [{"id": 1, "login": "sa-1-exporter"}]}`
+			if r.URL.Query().Get("page") == "2" {
+				responseBody = `{"totalCount": 2, "serviceAccounts": [{"id": 2, "login": "sa-1-screenshots"}]}`
+			}
+
+			_, err := w.Write([]byte(responseBody))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		logins, err := g.ServiceAccounts(t.Context(), "default")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sa-1-exporter", "sa-1-screenshots"}, logins)
+		assert.Equal(t, []string{"1", "2"}, pages)
+	})
+}
+
+func TestClient_TeamMembers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		team            string
+		expectedMembers []string
+		expectedFound   bool
+	}{
+		"existing team": {
+			team:            "NOC",
+			expectedMembers: []string{"noc-screen", "alice"},
+			expectedFound:   true,
+		},
+		"missing team": {
+			team:            "Missing",
+			expectedMembers: nil,
+			expectedFound:   false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var responseBody string
+				switch r.URL.Path {
+				case "/api/teams/search":
+					responseBody = `{"totalCount": 0, "teams": []}`
+					if r.URL.Query().Get("name") == "NOC" {
+						responseBody = `{"totalCount": 1, "teams": [{"id": 7, "name": "NOC"}]}`
+					}
+				case "/api/teams/7/members":
+					responseBody = `[{"teamId": 7, "login": "noc-screen"}, {"teamId": 7, "login": "alice"}]`
+				default:
+					assert.Fail(t, "unexpected request", r.URL.Path)
+				}
+
+				_, err := w.Write([]byte(responseBody))
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			g, err := grafana.NewClient(&grafana.NewClientOptions{
+				Logger:     slog.Default(),
+				HTTPClient: http.DefaultClient,
+				Endpoint:   mustParseURL(t, server.URL),
+				Token:      "abc123",
+			})
+			require.NoError(t, err)
+
+			members, found, err := g.TeamMembers(t.Context(), "default", tt.team)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMembers, members)
+			assert.Equal(t, tt.expectedFound, found)
+		})
+	}
+}
+
+func TestClient_Playlists(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "3", r.Header.Get("X-Grafana-Org-Id"))
+
+		var responseBody string
+		switch r.URL.Path {
+		case "/api/playlists":
+			responseBody = `[{"id": 1, "uid": "p1", "name": "NOC", "interval": "5m"}]`
+		case "/api/playlists/p1/items":
+			responseBody = `[
+				{"id": 1, "playlistUid": "p1", "type": "dashboard_by_uid", "value": "overview"},
+				{"id": 2, "playlistUid": "p1", "type": "dashboard_by_tag", "value": "noc"}
+			]`
+		default:
+			assert.Fail(t, "unexpected request", r.URL.Path)
+		}
+
+		_, err := w.Write([]byte(responseBody))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	playlists, err := g.Playlists(t.Context(), "org-3")
+	require.NoError(t, err)
+
+	expected := []grafana.Playlist{
+		{
+			UID:  "p1",
+			Name: "NOC",
+			Items: []grafana.PlaylistItem{
+				{Type: "dashboard_by_uid", Value: "overview"},
+				{Type: "dashboard_by_tag", Value: "noc"},
+			},
+		},
+	}
+	assert.Equal(t, expected, playlists)
+}
+
+func TestClient_HomeDashboards(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.Header.Get("X-Grafana-Org-Id"))
+
+		var responseBody string
+		switch r.URL.Path {
+		case "/api/org/preferences":
+			responseBody = `{"theme": "dark", "homeDashboardUID": "overview"}`
+		case "/api/teams/search":
+			responseBody = `{"totalCount": 2, "teams": [{"id": 7, "name": "NOC"}, {"id": 8, "name": "SRE"}]}`
+		case "/api/teams/7/preferences":
+			responseBody = `{"homeDashboardUID": "noc-home"}`
+		case "/api/teams/8/preferences":
+			responseBody = `{"homeDashboardUID": ""}`
+		default:
+			assert.Fail(t, "unexpected request", r.URL.Path)
+		}
+
+		_, err := w.Write([]byte(responseBody))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	homes, err := g.HomeDashboards(t.Context(), "default")
+	require.NoError(t, err)
+
+	expected := []grafana.HomeDashboard{
+		{UID: "overview", Team: ""},
+		{UID: "noc-home", Team: "NOC"},
+	}
+	assert.Equal(t, expected, homes)
+}
+
+func TestClient_AlertRules(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/provisioning/alert-rules", r.URL.Path)
+		assert.Equal(t, "2", r.Header.Get("X-Grafana-Org-Id"))
+		assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+		_, err := w.Write([]byte(`[
+			{
+				"uid": "r1",
+				"title": "High latency",
+				"folderUID": "alerts",
+				"annotations": {"__dashboardUid__": "latency", "__panelId__": "2"}
+			},
+			{"uid": "r2", "title": "Disk full", "folderUID": "alerts"}
+		]`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	rules, err := g.AlertRules(t.Context(), "org-2")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, "High latency", rules[0].Title)
+	assert.Equal(t, "latency", rules[0].DashboardUID())
+	assert.Equal(t, "Disk full", rules[1].Title)
+	assert.Empty(t, rules[1].DashboardUID())
+}
+
+func TestClient_StarredDashboards(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/preferences.grafana.app/v1alpha1/namespaces/default/stars", r.URL.Path)
+		assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+		var responseBody string
+		switch r.URL.Query().Get("continue") {
+		case "":
+			responseBody = `{
+				"metadata": {"continue": "page2"},
+				"items": [{
+					"metadata": {"name": "user-alice"},
+					"spec": {"resource": [
+						{"group": "dashboard.grafana.app", "kind": "Dashboard", "names": ["overview", "incident"]},
+						{"group": "folder.grafana.app", "kind": "Folder", "names": ["ops"]}
+					]}
+				}]
+			}`
+		case "page2":
+			responseBody = `{
+				"metadata": {},
+				"items": [{
+					"metadata": {"name": "user-bob"},
+					"spec": {"resource": [
+						{"group": "dashboard.grafana.app", "kind": "Dashboard", "names": ["incident"]}
+					]}
+				}]
+			}`
+		default:
+			assert.Fail(t, "unexpected continue token", r.URL.Query().Get("continue"))
+		}
+
+		_, err := w.Write([]byte(responseBody))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	starred, err := g.StarredDashboards(t.Context(), "default")
+	require.NoError(t, err)
+
+	expected := []grafana.StarredDashboard{
+		{UID: "incident", Users: 2},
+		{UID: "overview", Users: 1},
+	}
+	assert.Equal(t, expected, starred)
+}
+
+func TestClient_PublicDashboards(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/dashboards/public-dashboards", r.URL.Path)
+		assert.Equal(t, "4", r.Header.Get("X-Grafana-Org-Id"))
+
+		var responseBody string
+		switch r.URL.Query().Get("page") {
+		case "1":
+			responseBody = `{"totalCount": 2, "page": 1, "perPage": 500, "publicDashboards": [
+				{"uid": "pd1", "accessToken": "abc", "title": "Status", "dashboardUid": "status", "isEnabled": true}
+			]}`
+		case "2":
+			responseBody = `{"totalCount": 2, "page": 2, "perPage": 500, "publicDashboards": [
+				{"uid": "pd2", "accessToken": "def", "title": "Old", "dashboardUid": "old", "isEnabled": false}
+			]}`
+		default:
+			assert.Fail(t, "unexpected page", r.URL.Query().Get("page"))
+		}
+
+		_, err := w.Write([]byte(responseBody))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	public, err := g.PublicDashboards(t.Context(), "org-4")
+	require.NoError(t, err)
+
+	expected := []grafana.PublicDashboard{
+		{UID: "pd1", DashboardUID: "status", Enabled: true},
+		{UID: "pd2", DashboardUID: "old", Enabled: false},
+	}
+	assert.Equal(t, expected, public)
+}
+
+func TestClient_Snapshots(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "4", r.Header.Get("X-Grafana-Org-Id"))
+
+		var responseBody string
+		switch r.URL.Path {
+		case "/api/dashboard/snapshots":
+			assert.Equal(t, "10000", r.URL.Query().Get("limit"))
+			responseBody = `[
+				{"id": 1, "name": "Incident review", "key": "abc", "external": false, "expires": "2026-11-01T00:00:00Z"},
+				{"id": 2, "name": "Shared externally", "key": "def", "external": true, "expires": "2026-12-01T00:00:00Z"}
+			]`
+		case "/api/snapshots/abc":
+			responseBody = `{"dashboard": {"uid": "incident", "title": "Incident"}, "meta": {"isSnapshot": true}}`
+		case "/api/snapshots/def":
+			responseBody = `{"dashboard": {}, "meta": {"isSnapshot": true}}`
+		default:
+			assert.Fail(t, "unexpected path", r.URL.Path)
+		}
+
+		_, err := w.Write([]byte(responseBody))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	snapshots, err := g.Snapshots(t.Context(), "org-4")
+	require.NoError(t, err)
+
+	expected := []grafana.Snapshot{
+		{
+			Key:          "abc",
+			Name:         "Incident review",
+			DashboardUID: "incident",
+			Expires:      time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Key:          "def",
+			Name:         "Shared externally",
+			DashboardUID: "",
+			Expires:      time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	assert.Equal(t, expected, snapshots)
+}
+
+func TestClient_DeleteDashboard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty name", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, "https://grafana.example.com"),
+			Token:      "abc123",
+			Storage:    noopStorage,
+		})
+		require.NoError(t, err)
+
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default"})
+		require.EqualError(t, err, "dashboard name must not be empty")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("server error"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+			Storage:    noopStorage,
+		})
+		require.NoError(t, err)
+
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default", Name: "dashboard-name"})
+		require.EqualError(t, err, "unexpected status code: 500, body: server error")
+	})
+
+	t.Run("invalid json response", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte("!!!"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+			Storage:    noopStorage,
+		})
+		require.NoError(t, err)
+
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default", Name: "dashboard-name"})
+		require.EqualError(
+			t,
+			err,
+			`decoding response: invalid character '!' looking for beginning of value`,
+		)
+	})
+
+	t.Run("non-success status in response", func(t *testing.T) {
 		t.Parallel()
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1067,7 +2179,7 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", nil)
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default", Name: "dashboard-name"})
 		require.EqualError(
 			t,
 			err,
@@ -1109,7 +2221,7 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", nil)
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default", Name: "dashboard-name"})
 		require.NoError(t, err)
 		assert.Equal(t, "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/dashboard-name", request.URL.Path)
 		assert.Equal(t, "Bearer abc123", request.Header.Get("Authorization"))
@@ -1140,7 +2252,7 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", nil)
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{Namespace: "default", Name: "dashboard-name"})
 		require.ErrorContains(t, err, "making request to Grafana")
 		require.ErrorContains(t, err, "simulated client error")
 	})
@@ -1153,12 +2265,12 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		var callOrder []string
 
 		storage := &mockStorage{
-			backUpDashboard: func(_ context.Context, namespace, name string, dashboardJSON []byte) error {
+			backUpDashboard: func(_ context.Context, dashboard *backup.Dashboard) error {
 				backupCalled = true
 				callOrder = append(callOrder, "backup")
-				assert.Equal(t, "default", namespace)
-				assert.Equal(t, "dashboard-name", name)
-				assert.JSONEq(t, `{"title":"Test"}`, string(dashboardJSON))
+				assert.Equal(t, "default", dashboard.Namespace)
+				assert.Equal(t, "dashboard-name", dashboard.Name)
+				assert.JSONEq(t, `{"title":"Test"}`, string(dashboard.JSON))
 				return nil
 			},
 		}
@@ -1181,7 +2293,11 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", []byte(`{"title":"Test"}`))
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{
+			Namespace: "default",
+			Name:      "dashboard-name",
+			JSON:      []byte(`{"title":"Test"}`),
+		})
 		require.NoError(t, err)
 		assert.True(t, backupCalled, "backup should be called")
 		assert.True(t, deletionCalled, "deletion should be called")
@@ -1192,7 +2308,7 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		t.Parallel()
 
 		storage := &mockStorage{
-			backUpDashboard: func(_ context.Context, _, _ string, _ []byte) error {
+			backUpDashboard: func(_ context.Context, _ *backup.Dashboard) error {
 				return errors.New("GitHub API error")
 			},
 		}
@@ -1211,7 +2327,11 @@ func TestClient_DeleteDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = g.DeleteDashboard(t.Context(), "default", "dashboard-name", []byte(`{"title":"Test"}`))
+		err = g.DeleteDashboard(t.Context(), &backup.Dashboard{
+			Namespace: "default",
+			Name:      "dashboard-name",
+			JSON:      []byte(`{"title":"Test"}`),
+		})
 		require.EqualError(t, err, "backing up dashboard: GitHub API error")
 	})
 }
@@ -1224,15 +2344,15 @@ func (e *errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
 }
 
 type mockStorage struct {
-	backUpDashboard func(ctx context.Context, namespace, name string, dashboardJSON []byte) error
+	backUpDashboard func(ctx context.Context, dashboard *backup.Dashboard) error
 }
 
-func (m *mockStorage) BackUpDashboard(ctx context.Context, namespace, name string, dashboardJSON []byte) error {
-	return m.backUpDashboard(ctx, namespace, name, dashboardJSON)
+func (m *mockStorage) BackUpDashboard(ctx context.Context, dashboard *backup.Dashboard) error {
+	return m.backUpDashboard(ctx, dashboard)
 }
 
 var noopStorage = &mockStorage{
-	backUpDashboard: func(_ context.Context, _, _ string, _ []byte) error {
+	backUpDashboard: func(_ context.Context, _ *backup.Dashboard) error {
 		return nil
 	},
 }
@@ -1245,3 +2365,286 @@ func mustParseURL(t *testing.T, input string) url.URL {
 	}
 	return *parsed
 }
+
+func TestClient_CreateFolder(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/apis/folder.grafana.app/v1beta1/namespaces/org-4/folders", r.URL.Path)
+		assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"metadata":{"name":"frigg-archive"},"spec":{"title":"Frigg Archive"}}`, string(body))
+
+		w.WriteHeader(http.StatusCreated)
+		_, err = w.Write([]byte(`{"metadata":{"name":"frigg-archive"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, g.CreateFolder(t.Context(), "org-4", "frigg-archive", "Frigg Archive"))
+}
+
+func TestClient_RestrictFolder(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/folders/frigg-archive/permissions", r.URL.Path)
+		assert.Equal(t, "4", r.Header.Get("X-Grafana-Org-Id"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"items":[]}`, string(body))
+
+		_, err = w.Write([]byte(`{"message":"Folder permissions updated"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	g, err := grafana.NewClient(&grafana.NewClientOptions{
+		Logger:     slog.Default(),
+		HTTPClient: http.DefaultClient,
+		Endpoint:   mustParseURL(t, server.URL),
+		Token:      "abc123",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, g.RestrictFolder(t.Context(), "org-4", "frigg-archive"))
+}
+
+func TestClient_ArchiveDashboard(t *testing.T) {
+	t.Parallel()
+
+	archivedAt := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		annotations         string
+		expectedAnnotations string
+		putStatus           int
+		expectedError       string
+	}{
+		"moves dashboard to folder": {
+			annotations: `{"grafana.app/folder": "team-a"}`,
+			expectedAnnotations: `{"grafana.app/folder":"frigg-archive","frigg-archived-at":"2026-10-01T12:00:00Z",` +
+				`"frigg-archived-from":"team-a"}`,
+			putStatus:     http.StatusOK,
+			expectedError: "",
+		},
+		"moves dashboard from root to folder": {
+			annotations: `{}`,
+			expectedAnnotations: `{"grafana.app/folder":"frigg-archive","frigg-archived-at":"2026-10-01T12:00:00Z",` +
+				`"frigg-archived-from":""}`,
+			putStatus:     http.StatusOK,
+			expectedError: "",
+		},
+		"dashboard changed in the meantime": {
+			annotations: `{"grafana.app/folder": "team-a"}`,
+			expectedAnnotations: `{"grafana.app/folder":"frigg-archive","frigg-archived-at":"2026-10-01T12:00:00Z",` +
+				`"frigg-archived-from":"team-a"}`,
+			putStatus:     http.StatusConflict,
+			expectedError: "updating dashboard: unexpected status code: 409, body: conflict",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards/latency", r.URL.Path)
+
+				switch r.Method {
+				case http.MethodGet:
+					_, err := w.Write([]byte(`{
+						"kind": "Dashboard",
+						"metadata": {
+							"name": "latency",
+							"resourceVersion": "42",
+							"annotations": ` + tt.annotations + `
+						},
+						"spec": {"title": "Latency"}
+					}`))
+					assert.NoError(t, err)
+				case http.MethodPut:
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					assert.JSONEq(
+						t,
+						`{"kind":"Dashboard","metadata":{"name":"latency","resourceVersion":"42","annotations":`+
+							tt.expectedAnnotations+`},"spec":{"title":"Latency"}}`,
+						string(body),
+					)
+
+					w.WriteHeader(tt.putStatus)
+					if tt.putStatus != http.StatusOK {
+						_, err = w.Write([]byte("conflict"))
+						assert.NoError(t, err)
+					}
+				default:
+					assert.Fail(t, "unexpected method", r.Method)
+				}
+			}))
+			defer server.Close()
+
+			g, err := grafana.NewClient(&grafana.NewClientOptions{
+				Logger:     slog.Default(),
+				HTTPClient: http.DefaultClient,
+				Endpoint:   mustParseURL(t, server.URL),
+				Token:      "abc123",
+			})
+			require.NoError(t, err)
+
+			err = g.ArchiveDashboard(t.Context(), "default", "latency", "frigg-archive", archivedAt)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_CreateDashboard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty name", func(t *testing.T) {
+		t.Parallel()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, "https://grafana.example.com"),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		err = g.CreateDashboard(t.Context(), "default", "", "", nil, nil)
+		require.EqualError(t, err, "dashboard name must not be empty")
+	})
+
+	t.Run("creates dashboard in folder", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards", r.URL.Path)
+			assert.Equal(t, "Bearer abc123", r.Header.Get("Authorization"))
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			//nolint:lll
+			assert.JSONEq(
+				t,
+				`{"metadata":{"name":"latency","annotations":{"frigg-tombstone":"2026-10-01T12:00:00Z","grafana.app/folder":"team-a"}},"spec":{"title":"Latency (deleted)"}}`,
+				string(body),
+			)
+
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		err = g.CreateDashboard(
+			t.Context(),
+			"default",
+			"latency",
+			"team-a",
+			map[string]string{"frigg-tombstone": "2026-10-01T12:00:00Z"},
+			map[string]any{"title": "Latency (deleted)"},
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("already exists"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		g, err := grafana.NewClient(&grafana.NewClientOptions{
+			Logger:     slog.Default(),
+			HTTPClient: http.DefaultClient,
+			Endpoint:   mustParseURL(t, server.URL),
+			Token:      "abc123",
+		})
+		require.NoError(t, err)
+
+		err = g.CreateDashboard(t.Context(), "default", "latency", "", nil, map[string]any{})
+		require.EqualError(t, err, "creating dashboard latency: unexpected status code: 409, body: already exists")
+	})
+}
+
+func TestClient_ImportURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		namespace   string
+		expected    string
+		expectedErr string
+	}{
+		"default organisation": {
+			namespace:   "default",
+			expected:    "https://grafana.example.com/grafana/dashboard/import?orgId=1",
+			expectedErr: "",
+		},
+		"other organisation": {
+			namespace:   "org-3",
+			expected:    "https://grafana.example.com/grafana/dashboard/import?orgId=3",
+			expectedErr: "",
+		},
+		"Grafana Cloud stack": {
+			namespace:   "stacks-123",
+			expected:    "https://grafana.example.com/grafana/dashboard/import?orgId=1",
+			expectedErr: "",
+		},
+		"unexpected namespace": {
+			namespace:   "team-a",
+			expected:    "",
+			expectedErr: `unexpected namespace format: "team-a"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g, err := grafana.NewClient(&grafana.NewClientOptions{
+				Logger:     slog.Default(),
+				HTTPClient: http.DefaultClient,
+				Endpoint:   mustParseURL(t, "https://grafana.example.com/grafana"),
+				Token:      "abc123",
+			})
+			require.NoError(t, err)
+
+			u, err := g.ImportURL(tc.namespace)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, u)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package grafana
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+const (
+	// keepUntilKey is the name of the tag prefix and annotation that protect a dashboard from pruning until a date,
+	// e.g., the tag "frigg-keep-until:2026-12-31".
+	keepUntilKey = "frigg-keep-until"
+	// ttlKey is the name of the tag prefix and annotation that mark a dashboard for deletion a duration after its
+	// creation, e.g., the tag "frigg-ttl:7d".
+	ttlKey = "frigg-ttl"
+)
+
+// lifetimeOf returns the keep-until time and TTL of a dashboard with tags and annotations. The keep-until time is zero
+// if the dashboard has no keep-until tag or annotation, and the TTL is zero if the dashboard has no TTL tag or
+// annotation.
+//
+// If a dashboard has several keep-until or TTL values, the latest keep-until time and the longest TTL apply, as these
+// keep the dashboard the longest. Invalid values are ignored and returned as an error along with the valid values.
+func lifetimeOf(tags []string, annotations map[string]string) (time.Time, time.Duration, error) {
+	var keepUntilValues, ttlValues []string
+	for _, tag := range tags {
+		if value, ok := strings.CutPrefix(tag, keepUntilKey+":"); ok {
+			keepUntilValues = append(keepUntilValues, value)
+		}
+		if value, ok := strings.CutPrefix(tag, ttlKey+":"); ok {
+			ttlValues = append(ttlValues, value)
+		}
+	}
+	if value, ok := annotations[keepUntilKey]; ok {
+		keepUntilValues = append(keepUntilValues, value)
+	}
+	if value, ok := annotations[ttlKey]; ok {
+		ttlValues = append(ttlValues, value)
+	}
+
+	var errs error
+	var keepUntil time.Time
+	for _, value := range keepUntilValues {
+		t, err := parseKeepUntil(value)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("parsing %s %q: %w", keepUntilKey, value, err))
+			continue
+		}
+		if t.After(keepUntil) {
+			keepUntil = t
+		}
+	}
+
+	var ttl time.Duration
+	for _, value := range ttlValues {
+		d, err := parseTTL(value)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("parsing %s %q: %w", ttlKey, value, err))
+			continue
+		}
+		if d > ttl {
+			ttl = d
+		}
+	}
+
+	return keepUntil, ttl, errs
+}
+
+// parseKeepUntil parses a keep-until value. The value is either a date, e.g., "2026-12-31", or an RFC 3339 time. A
+// dashboard kept until a date is kept until the end of that date in UTC.
+func parseKeepUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("expected a date in the format %s or an RFC 3339 time", time.DateOnly)
+	}
+
+	return t, nil
+}
+
+// parseTTL parses a TTL value. The value is either a number of days, e.g., "7d", or a Go duration, e.g., "36h". The TTL
+// must be positive.
+func parseTTL(value string) (time.Duration, error) {
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("expected a number of days or a duration: %w", err)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("expected a number of days or a duration: %w", err)
+		}
+		ttl = d
+	}
+
+	if ttl <= 0 {
+		return 0, errors.New("must be positive")
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,89 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifetimeOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		tags              []string
+		annotations       map[string]string
+		expectedKeepUntil time.Time
+		expectedTTL       time.Duration
+		expectedErr       string
+	}{
+		"no lifetime": {
+			tags:              []string{"keep", "team-a"},
+			annotations:       map[string]string{"grafana.app/folder": "abc"},
+			expectedKeepUntil: time.Time{},
+			expectedTTL:       0,
+			expectedErr:       "",
+		},
+		"keep-until date tag keeps dashboard until end of date": {
+			tags:              []string{"frigg-keep-until:2026-12-31"},
+			annotations:       nil,
+			expectedKeepUntil: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedTTL:       0,
+			expectedErr:       "",
+		},
+		"keep-until time annotation": {
+			tags:              nil,
+			annotations:       map[string]string{"frigg-keep-until": "2026-12-31T12:00:00Z"},
+			expectedKeepUntil: time.Date(2026, time.December, 31, 12, 0, 0, 0, time.UTC),
+			expectedTTL:       0,
+			expectedErr:       "",
+		},
+		"ttl in days": {
+			tags:              []string{"frigg-ttl:7d"},
+			annotations:       nil,
+			expectedKeepUntil: time.Time{},
+			expectedTTL:       7 * 24 * time.Hour,
+			expectedErr:       "",
+		},
+		"ttl as duration annotation": {
+			tags:              nil,
+			annotations:       map[string]string{"frigg-ttl": "36h"},
+			expectedKeepUntil: time.Time{},
+			expectedTTL:       36 * time.Hour,
+			expectedErr:       "",
+		},
+		"latest keep-until and longest ttl apply": {
+			tags:              []string{"frigg-keep-until:2026-12-31", "frigg-ttl:7d", "frigg-ttl:1d"},
+			annotations:       map[string]string{"frigg-keep-until": "2027-01-31", "frigg-ttl": "48h"},
+			expectedKeepUntil: time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC),
+			expectedTTL:       7 * 24 * time.Hour,
+			expectedErr:       "",
+		},
+		"invalid values are ignored": {
+			tags:              []string{"frigg-keep-until:tomorrow", "frigg-ttl:7d", "frigg-ttl:-1d"},
+			annotations:       map[string]string{"frigg-ttl": "a week"},
+			expectedKeepUntil: time.Time{},
+			expectedTTL:       7 * 24 * time.Hour,
+			expectedErr: `parsing frigg-keep-until "tomorrow": expected a date in the format 2006-01-02 or an RFC ` +
+				`3339 time; parsing frigg-ttl "-1d": must be positive; parsing frigg-ttl "a week": expected a number ` +
+				`of days or a duration: time: invalid duration "a week"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keepUntil, ttl, err := lifetimeOf(tt.tags, tt.annotations)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedKeepUntil, keepUntil)
+			assert.Equal(t, tt.expectedTTL, ttl)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package grafana
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// abortReasonDeletionPercentage is an abort reason where too large a share of dashboards would have been deleted.
+	abortReasonDeletionPercentage = "deletion_percentage"
+	// abortReasonMissingCanary is an abort reason where a canary dashboard was not found among the used dashboards.
+	abortReasonMissingCanary = "missing_canary"
+	// abortReasonPeriodExceedsRetention is an abort reason where Loki did not hold Grafana logs for the entire period.
+	abortReasonPeriodExceedsRetention = "period_exceeds_retention"
+)
+
+// Metrics holds the Prometheus metrics of DashboardPruner.
+type Metrics struct {
+	abortedRuns *prometheus.CounterVec
+}
+
+// NewMetrics creates Metrics and registers them with registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		abortedRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "frigg_prune_aborted_total",
+			Help: "Total number of prune runs that were aborted before deleting any dashboards.",
+		}, []string{"namespace", "reason"}),
+	}
+
+	registerer.MustRegister(m.abortedRuns)
+
+	return m
+}
@@ -0,0 +1,54 @@
+package grafana
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// orgID returns the ID of the organisation that namespace belongs to. The namespace of the organisation with ID 1 is
+// "default", the namespace of any other organisation is "org-<ID>" and the namespace of a Grafana Cloud stack is
+// "stacks-<ID>". A Grafana Cloud stack has a single organisation with ID 1.
+//
+// See [Namespace].
+//
+// [Namespace]: https://grafana.com/docs/grafana/v12.0/developers/http_api/apis/#namespace-namespace
+func orgID(namespace string) (int64, error) {
+	if namespace == "default" || strings.HasPrefix(namespace, "stacks-") {
+		return 1, nil
+	}
+
+	id, ok := strings.CutPrefix(namespace, "org-")
+	if !ok {
+		return 0, fmt.Errorf("unexpected namespace format: %q", namespace)
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("unexpected namespace format: %q", namespace)
+	}
+
+	return n, nil
+}
+
+// namespaceOf returns the namespace of the organisation with the given ID. namespaceOf returns own if it is the
+// namespace of the organisation, i.e., the namespace that Frigg prunes. A Grafana Cloud stack has a single
+// organisation with ID 1 whose namespace is "stacks-<ID>", which cannot be derived from the organisation ID alone.
+// Otherwise, namespaceOf is the inverse of orgID.
+func namespaceOf(id, own string) (string, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("unexpected organisation ID: %q", id)
+	}
+
+	ownID, err := orgID(own)
+	if err == nil && ownID == n {
+		return own, nil
+	}
+
+	if n == 1 {
+		return "default", nil
+	}
+
+	return fmt.Sprintf("org-%d", n), nil
+}
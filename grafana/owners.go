@@ -0,0 +1,83 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ownerTagPrefix is the prefix of tags that name the owner of a dashboard, e.g., "owner:team-a@example.com".
+	ownerTagPrefix = "owner:"
+	// createdByAnnotation holds the identity that created a dashboard, e.g., "user:cef2t2rfm73lsb".
+	createdByAnnotation = "grafana.app/createdBy"
+	// userIdentityPrefix is the prefix of identities that are users rather than, e.g., service accounts.
+	userIdentityPrefix = "user:"
+)
+
+// creators returns the owner of each user identity that can appear in the createdBy annotation of a dashboard. The
+// owner of a user is the user's email address, or the user's login if the user has no email address.
+func (d *DashboardPruner) creators(ctx context.Context) (map[string]string, error) {
+	users, err := d.grafana.OrgUsers(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana organisation users: %w", err)
+	}
+
+	creators := make(map[string]string, len(users))
+	for _, user := range users {
+		if user.UID == "" {
+			continue
+		}
+
+		owner := user.Email
+		if owner == "" {
+			owner = user.Login
+		}
+		creators[userIdentityPrefix+user.UID] = owner
+	}
+
+	return creators, nil
+}
+
+// creatorLogins returns the login of each user identity that can appear in the createdBy annotation of a dashboard.
+// creatorLogins returns an empty map unless the policy reads the creators of dashboards.
+func (d *DashboardPruner) creatorLogins(ctx context.Context) (map[string]string, error) {
+	logins := make(map[string]string)
+	if d.policy == nil || !d.policy.reads("creator") {
+		return logins, nil
+	}
+
+	users, err := d.grafana.OrgUsers(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana organisation users: %w", err)
+	}
+
+	for _, user := range users {
+		if user.UID != "" {
+			logins[userIdentityPrefix+user.UID] = user.Login
+		}
+	}
+
+	return logins, nil
+}
+
+// ownerOf returns the owner of dashboard. The owner is, in order of precedence, the value of the dashboard's first
+// owner tag, the user that created the dashboard, the owner of the closest of the dashboard's folders that is mapped to
+// an owner or the default owner. creators maps user identities to owners, see creators.
+func (d *DashboardPruner) ownerOf(dashboard *Dashboard, folders map[string]Folder, creators map[string]string) string {
+	for _, tag := range dashboard.Tags {
+		if owner, ok := strings.CutPrefix(tag, ownerTagPrefix); ok && strings.TrimSpace(owner) != "" {
+			return strings.TrimSpace(owner)
+		}
+	}
+
+	if owner, ok := creators[dashboard.Annotations[createdByAnnotation]]; ok {
+		return owner
+	}
+
+	if folder, ok := matchFolder(dashboard, folders, d.ownerFolderPatterns); ok {
+		return d.ownerFolders[folder]
+	}
+
+	return d.defaultOwner
+}
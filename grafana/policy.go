@@ -0,0 +1,170 @@
+package grafana
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"cel.dev/cel-go/cel"
+	celast "cel.dev/cel-go/common/ast"
+	"cel.dev/cel-go/ext"
+	"github.com/pkg/errors"
+)
+
+// PolicyConfig holds the [CEL] expressions that decide whether to skip or force the deletion of a dashboard. Each
+// expression is evaluated against the variables dashboard, which holds the fields of policyDashboard, and now, which
+// is the start time of the prune run.
+//
+// [CEL]: https://cel.dev
+type PolicyConfig struct {
+	// Skip expressions. A dashboard is never deleted if any skip expression evaluates to true.
+	Skip []string `yaml:"skip" validate:"dive,required"`
+	// Force expressions. A dashboard is deleted if any force expression evaluates to true, even if it is used. Skip
+	// expressions, skip tags and keep-until times take precedence over force expressions.
+	Force []string `yaml:"force" validate:"dive,required"`
+}
+
+// policyDashboard is the dashboard that policy expressions are evaluated against.
+type policyDashboard struct {
+	Name        string            `cel:"name"`
+	Title       string            `cel:"title"`
+	Tags        []string          `cel:"tags"`
+	Folder      string            `cel:"folder"`
+	FolderUID   string            `cel:"folder_uid"`
+	Annotations map[string]string `cel:"annotations"`
+	Created     time.Time         `cel:"created"`
+	Modified    time.Time         `cel:"modified"`
+	Reads       int               `cel:"reads"`
+	QueryReads  int               `cel:"query_reads"`
+	Users       []string          `cel:"users"`
+	// LastViewed is the zero time if the dashboard has not been read in the period.
+	LastViewed time.Time `cel:"last_viewed"`
+	// Creator is the login of the user that created the dashboard. Creator is empty if the dashboard was created by a
+	// service account or by a user that is no longer in the organisation, or if no expression reads it. See
+	// Policy.reads.
+	Creator string `cel:"creator"`
+}
+
+// Policy decides whether to skip or force the deletion of dashboards. See PolicyConfig.
+type Policy struct {
+	skipRules  []policyRule
+	forceRules []policyRule
+}
+
+type policyRule struct {
+	expression string
+	program    cel.Program
+	// fields holds the names of the fields that the expression selects, e.g., "creator" for dashboard.creator.
+	fields []string
+}
+
+// Compile compiles and type-checks the expressions of p.
+func (p *PolicyConfig) Compile() (*Policy, error) {
+	env, err := cel.NewEnv(
+		ext.NativeTypes(reflect.TypeFor[policyDashboard](), ext.ParseStructTags(true)),
+		cel.Variable("dashboard", cel.ObjectType("grafana.policyDashboard")),
+		cel.Variable("now", cel.TimestampType),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating CEL environment")
+	}
+
+	skip, err := compileRules(env, p.Skip)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling skip expression")
+	}
+
+	force, err := compileRules(env, p.Force)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling force expression")
+	}
+
+	return &Policy{skipRules: skip, forceRules: force}, nil
+}
+
+func compileRules(env *cel.Env, expressions []string) ([]policyRule, error) {
+	rules := make([]policyRule, 0, len(expressions))
+	for _, expression := range expressions {
+		ast, issues := env.Compile(expression)
+		if issues.Err() != nil {
+			return nil, errors.Wrapf(issues.Err(), "%q", expression)
+		}
+
+		if ast.OutputType() != cel.BoolType {
+			return nil, errors.Errorf("%q: must evaluate to bool, got %s", expression, ast.OutputType())
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%q", expression)
+		}
+
+		rules = append(rules, policyRule{
+			expression: expression,
+			program:    program,
+			fields:     selectedFields(ast),
+		})
+	}
+
+	return rules, nil
+}
+
+// selectedFields returns the names of the fields that ast selects from any value, e.g., "creator" for
+// dashboard.creator.
+func selectedFields(ast *cel.Ast) []string {
+	var fields []string
+	celast.PostOrderVisit(ast.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if e.Kind() == celast.SelectKind {
+			fields = append(fields, e.AsSelect().FieldName())
+		}
+	}))
+
+	return fields
+}
+
+// reads returns true if any expression of p reads the given field of the dashboard, e.g., "creator". Some fields
+// require requests to Grafana, which Frigg only sends if an expression reads them.
+func (p *Policy) reads(field string) bool {
+	for _, rule := range slices.Concat(p.skipRules, p.forceRules) {
+		if slices.Contains(rule.fields, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skip returns the first skip expression that evaluates to true for dashboard. The returned bool is false if no skip
+// expression evaluates to true.
+func (p *Policy) skip(dashboard *policyDashboard, now time.Time) (string, bool, error) {
+	return evaluateRules(p.skipRules, dashboard, now)
+}
+
+// force returns the first force expression that evaluates to true for dashboard. The returned bool is false if no
+// force expression evaluates to true.
+func (p *Policy) force(dashboard *policyDashboard, now time.Time) (string, bool, error) {
+	return evaluateRules(p.forceRules, dashboard, now)
+}
+
+// evaluateRules returns the first rule that evaluates to true. evaluateRules returns an error for the first rule that
+// cannot be evaluated, e.g., because it reads an annotation that the dashboard does not have.
+func evaluateRules(rules []policyRule, dashboard *policyDashboard, now time.Time) (string, bool, error) {
+	vars := map[string]any{
+		"dashboard": *dashboard,
+		"now":       now,
+	}
+
+	for _, rule := range rules {
+		out, _, err := rule.program.Eval(vars)
+		if err != nil {
+			return rule.expression, false, fmt.Errorf("evaluating %q: %w", rule.expression, err)
+		}
+
+		if matched, ok := out.Value().(bool); ok && matched {
+			return rule.expression, true, nil
+		}
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,213 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyConfig_Compile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config      PolicyConfig
+		expectedErr string
+	}{
+		"valid expressions": {
+			config: PolicyConfig{
+				Skip: []string{
+					`dashboard.title.startsWith("[Team-X]") && dashboard.folder == "Y"`,
+					`"keep" in dashboard.tags || dashboard.annotations["team"] == "core"`,
+				},
+				Force: []string{
+					`dashboard.users.all(u, u == "creator") && dashboard.created < now - duration("168h")`,
+					`dashboard.reads < 3 && dashboard.last_viewed < now - duration("720h")`,
+				},
+			},
+			expectedErr: "",
+		},
+		"syntax error": {
+			config: PolicyConfig{
+				Skip:  []string{`dashboard.title ==`},
+				Force: nil,
+			},
+			expectedErr: "compiling skip expression: \"dashboard.title ==\": ERROR: <input>:1:19: Syntax error: " +
+				"mismatched input '<EOF>' expecting {'[', '{', '(', '.', '-', '!', 'true', 'false', 'null', NUM_FLOAT, " +
+				"NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}\n | dashboard.title ==\n" +
+				" | ..................^",
+		},
+		"undefined field": {
+			config: PolicyConfig{
+				Skip:  nil,
+				Force: []string{`dashboard.views > 10`},
+			},
+			expectedErr: "compiling force expression: \"dashboard.views > 10\": ERROR: <input>:1:10: undefined " +
+				"field 'views'\n | dashboard.views > 10\n | .........^",
+		},
+		"expression does not evaluate to bool": {
+			config: PolicyConfig{
+				Skip:  []string{`dashboard.reads`},
+				Force: nil,
+			},
+			expectedErr: "compiling skip expression: \"dashboard.reads\": must evaluate to bool, got int",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := tt.config.Compile()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, policy)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, policy)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	policy, err := (&PolicyConfig{
+		Skip: []string{
+			`dashboard.title.startsWith("[Team-X]") && dashboard.folder == "Y"`,
+			`dashboard.annotations["team"] == "core"`,
+		},
+		Force: []string{
+			`dashboard.reads > 0 && dashboard.users.all(u, u == "creator") && dashboard.last_viewed < now - duration("24h")`,
+		},
+	}).Compile()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		dashboard     policyDashboard
+		expectedSkip  string
+		skipped       bool
+		expectedForce string
+		forced        bool
+		expectedErr   string
+	}{
+		"matches skip expression": {
+			dashboard: policyDashboard{
+				Title:       "[Team-X] Overview",
+				Folder:      "Y",
+				Annotations: map[string]string{"team": "x"},
+			},
+			expectedSkip:  `dashboard.title.startsWith("[Team-X]") && dashboard.folder == "Y"`,
+			skipped:       true,
+			expectedForce: "",
+			forced:        false,
+			expectedErr:   "",
+		},
+		"matches force expression": {
+			dashboard: policyDashboard{
+				Title:       "Incident",
+				Annotations: map[string]string{"team": "x"},
+				Reads:       4,
+				Users:       []string{"creator"},
+				LastViewed:  now.Add(-48 * time.Hour),
+			},
+			expectedSkip: "",
+			skipped:      false,
+			expectedForce: `dashboard.reads > 0 && dashboard.users.all(u, u == "creator") && ` +
+				`dashboard.last_viewed < now - duration("24h")`,
+			forced:      true,
+			expectedErr: "",
+		},
+		"matches no expression": {
+			dashboard: policyDashboard{
+				Title:       "Incident",
+				Annotations: map[string]string{"team": "x"},
+				Reads:       4,
+				Users:       []string{"creator", "someone-else"},
+				LastViewed:  now.Add(-48 * time.Hour),
+			},
+			expectedSkip:  "",
+			skipped:       false,
+			expectedForce: "",
+			forced:        false,
+			expectedErr:   "",
+		},
+		"errors if expression cannot be evaluated": {
+			dashboard:     policyDashboard{Title: "No annotations"},
+			expectedSkip:  `dashboard.annotations["team"] == "core"`,
+			skipped:       false,
+			expectedForce: "",
+			forced:        false,
+			expectedErr:   `evaluating "dashboard.annotations[\"team\"] == \"core\"": no such key: team`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			skip, skipped, err := policy.skip(&tt.dashboard, now)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedSkip, skip)
+			assert.Equal(t, tt.skipped, skipped)
+
+			if tt.expectedErr != "" {
+				return
+			}
+
+			force, forced, err := policy.force(&tt.dashboard, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedForce, force)
+			assert.Equal(t, tt.forced, forced)
+		})
+	}
+}
+
+func TestPolicy_Reads(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config   PolicyConfig
+		field    string
+		expected bool
+	}{
+		"skip expression reads field": {
+			config:   PolicyConfig{Skip: []string{`dashboard.creator == "admin"`}},
+			field:    "creator",
+			expected: true,
+		},
+		"force expression reads field": {
+			config:   PolicyConfig{Force: []string{`dashboard.users.all(u, u == dashboard.creator)`}},
+			field:    "creator",
+			expected: true,
+		},
+		"annotation with the name of the field": {
+			config:   PolicyConfig{Skip: []string{`dashboard.annotations["creator"] == "admin"`}},
+			field:    "creator",
+			expected: false,
+		},
+		"field with the same prefix": {
+			config:   PolicyConfig{Skip: []string{`dashboard.folder_uid == "abc"`}},
+			field:    "folder",
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := tt.config.Compile()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy.reads(tt.field))
+		})
+	}
+}
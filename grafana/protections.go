@@ -0,0 +1,300 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+)
+
+// protectOptions select the features that protect dashboards from deletion even if they are unused.
+type protectOptions struct {
+	references bool
+	// referenceDepth is the number of links that are followed from referencing dashboards.
+	referenceDepth int
+	alertRules     bool
+	starred        bool
+	public         bool
+	snapshots      bool
+}
+
+// protections holds the reason why each protected dashboard is not deleted, by the feature that protects it. A
+// dashboard that several features protect is listed by each of them.
+type protections struct {
+	alertRules map[DashboardKey]string
+	starred    map[DashboardKey]string
+	public     map[DashboardKey]string
+	snapshots  map[DashboardKey]string
+	references map[DashboardKey]string
+	snoozed    map[DashboardKey]string
+}
+
+// gatherProtections returns the dashboards that the configured features protect at start. used holds the used
+// dashboards, which protect the dashboards that they reference.
+func (d *DashboardPruner) gatherProtections(
+	ctx context.Context,
+	all []Dashboard,
+	used map[DashboardKey]DashboardReads,
+	start time.Time,
+) (*protections, error) {
+	alertRules, err := d.alertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	starred, err := d.starred(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := d.publicDashboards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := d.snapshots(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	references, err := d.references(ctx, all, used, alertRules, start)
+	if err != nil {
+		return nil, err
+	}
+
+	snoozed, err := d.snoozed(start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protections{
+		alertRules: alertRules,
+		starred:    starred,
+		public:     public,
+		snapshots:  snapshots,
+		references: references,
+		snoozed:    snoozed,
+	}, nil
+}
+
+// all returns the reason why each protected dashboard is protected, regardless of the feature that protects it.
+func (p *protections) all() map[DashboardKey]string {
+	all := maps.Clone(p.references)
+	maps.Copy(all, p.alertRules)
+	maps.Copy(all, p.starred)
+	maps.Copy(all, p.public)
+	maps.Copy(all, p.snapshots)
+	maps.Copy(all, p.snoozed)
+
+	return all
+}
+
+// protects returns true if any feature protects dashboard. protects logs the first feature that protects dashboard.
+func (p *protections) protects(logger *slog.Logger, dashboard *Dashboard) bool {
+	key := dashboard.Key()
+
+	if reason, linked := p.alertRules[key]; linked {
+		logger.Info("Skipping dashboard linked from alert rule", slog.String("reason", reason))
+		return true
+	}
+
+	if reason, isStarred := p.starred[key]; isStarred {
+		logger.Info("Skipping starred dashboard", slog.String("reason", reason))
+		return true
+	}
+
+	if reason, isPublic := p.public[key]; isPublic {
+		logger.Info("Skipping public dashboard", slog.String("reason", reason))
+		return true
+	}
+
+	if reason, isShared := p.snapshots[key]; isShared {
+		logger.Info("Skipping dashboard shared as snapshot", slog.String("reason", reason))
+		return true
+	}
+
+	if reason, referenced := p.references[key]; referenced {
+		logger.Info("Skipping referenced dashboard", slog.String("reason", reason))
+		return true
+	}
+
+	if reason, isSnoozed := p.snoozed[key]; isSnoozed {
+		logger.Info("Skipping snoozed dashboard", slog.String("reason", reason))
+		return true
+	}
+
+	return false
+}
+
+// alertRules returns the reason why each dashboard that an alert rule links to is protected. alertRules returns an
+// empty map if alert rules are not protected.
+func (d *DashboardPruner) alertRules(ctx context.Context) (map[DashboardKey]string, error) {
+	linked := make(map[DashboardKey]string)
+	if !d.protect.alertRules {
+		return linked, nil
+	}
+
+	rules, err := d.grafana.AlertRules(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana alert rules: %w", err)
+	}
+
+	for i := range rules {
+		uid := rules[i].DashboardUID()
+		if uid == "" {
+			continue
+		}
+
+		key := DashboardKey{name: uid, namespace: d.namespace}
+		if _, ok := linked[key]; !ok {
+			linked[key] = fmt.Sprintf("linked from alert rule %q", rules[i].Title)
+		}
+	}
+
+	return linked, nil
+}
+
+// starred returns the reason why each dashboard that users have starred is protected. starred returns an empty map if
+// starred dashboards are not protected.
+func (d *DashboardPruner) starred(ctx context.Context) (map[DashboardKey]string, error) {
+	starred := make(map[DashboardKey]string)
+	if !d.protect.starred {
+		return starred, nil
+	}
+
+	dashboards, err := d.grafana.StarredDashboards(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching starred Grafana dashboards: %w", err)
+	}
+
+	for _, dashboard := range dashboards {
+		key := DashboardKey{name: dashboard.UID, namespace: d.namespace}
+		starred[key] = fmt.Sprintf("starred by %d user(s)", dashboard.Users)
+	}
+
+	return starred, nil
+}
+
+// publicDashboards returns the reason why each dashboard that is published as an enabled public dashboard is
+// protected. publicDashboards returns an empty map if public dashboards are not protected.
+func (d *DashboardPruner) publicDashboards(ctx context.Context) (map[DashboardKey]string, error) {
+	public := make(map[DashboardKey]string)
+	if !d.protect.public {
+		return public, nil
+	}
+
+	dashboards, err := d.grafana.PublicDashboards(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana public dashboards: %w", err)
+	}
+
+	for _, dashboard := range dashboards {
+		if !dashboard.Enabled {
+			continue
+		}
+
+		key := DashboardKey{name: dashboard.DashboardUID, namespace: d.namespace}
+		public[key] = fmt.Sprintf("published as public dashboard %q", dashboard.UID)
+	}
+
+	return public, nil
+}
+
+// snapshots returns the reason why each dashboard that is shared as a snapshot that has not expired at start is
+// protected. snapshots returns an empty map if snapshots are not protected.
+func (d *DashboardPruner) snapshots(ctx context.Context, start time.Time) (map[DashboardKey]string, error) {
+	shared := make(map[DashboardKey]string)
+	if !d.protect.snapshots {
+		return shared, nil
+	}
+
+	snapshots, err := d.grafana.Snapshots(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana snapshots: %w", err)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.DashboardUID == "" || snapshot.Expires.Before(start) {
+			continue
+		}
+
+		key := DashboardKey{name: snapshot.DashboardUID, namespace: d.namespace}
+		if _, ok := shared[key]; !ok {
+			shared[key] = fmt.Sprintf("shared as snapshot %q", snapshot.Name)
+		}
+	}
+
+	return shared, nil
+}
+
+// snoozed returns the reason why each dashboard that its owner has snoozed is protected. snoozed returns an empty map
+// if snoozes are not considered.
+func (d *DashboardPruner) snoozed(start time.Time) (map[DashboardKey]string, error) {
+	snoozed := make(map[DashboardKey]string)
+	if d.snoozes == nil {
+		return snoozed, nil
+	}
+
+	dashboards, err := d.snoozes.Snoozed(d.namespace, start)
+	if err != nil {
+		return nil, fmt.Errorf("fetching snoozed dashboards: %w", err)
+	}
+
+	for name, until := range dashboards {
+		key := DashboardKey{name: name, namespace: d.namespace}
+		snoozed[key] = fmt.Sprintf("snoozed until %s", until.UTC().Format(time.RFC3339))
+	}
+
+	return snoozed, nil
+}
+
+// references returns the reason why each unused dashboard is referenced by a used dashboard, a playlist, a home
+// dashboard setting or a dashboard that an alert rule links to. alertRules holds the dashboards that alert rules link
+// to. references returns an empty map if references are not protected.
+func (d *DashboardPruner) references(
+	ctx context.Context,
+	all []Dashboard,
+	used map[DashboardKey]DashboardReads,
+	alertRules map[DashboardKey]string,
+	start time.Time,
+) (map[DashboardKey]string, error) {
+	if !d.protect.references {
+		return map[DashboardKey]string{}, nil
+	}
+
+	roots := maps.Clone(alertRules)
+
+	playlists, err := d.grafana.Playlists(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana playlists: %w", err)
+	}
+	for _, playlist := range playlists {
+		for _, item := range playlist.Items {
+			key := DashboardKey{name: item.Value, namespace: d.namespace}
+			if _, ok := roots[key]; !ok && item.Type == playlistItemByUID {
+				roots[key] = fmt.Sprintf("included in playlist %q", playlist.Name)
+			}
+		}
+	}
+
+	homes, err := d.grafana.HomeDashboards(ctx, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Grafana home dashboards: %w", err)
+	}
+	for _, home := range homes {
+		key := DashboardKey{name: home.UID, namespace: d.namespace}
+		if _, ok := roots[key]; ok {
+			continue
+		}
+		if home.Team == "" {
+			roots[key] = "home dashboard of the organisation"
+		} else {
+			roots[key] = fmt.Sprintf("home dashboard of team %q", home.Team)
+		}
+	}
+
+	return referenceGraph(all, roots, d.protect.referenceDepth, func(dashboard *Dashboard) bool {
+		return d.used(dashboard, used, start)
+	}), nil
+}
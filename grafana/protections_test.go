@@ -0,0 +1,98 @@
+package grafana
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardPruner_GatherProtections(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	key := func(name string) DashboardKey {
+		return DashboardKey{name: name, namespace: "default"}
+	}
+
+	t.Run("does not query Grafana for protections that are disabled", func(t *testing.T) {
+		t.Parallel()
+
+		l, _ := logger()
+		// The mock panics if any of its methods is called.
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:   &mockGrafanaClient{},
+			Logger:    l,
+			Namespace: "default",
+		})
+
+		p, err := pruner.gatherProtections(t.Context(), nil, nil, start)
+		require.NoError(t, err)
+		assert.Empty(t, p.all())
+	})
+
+	t.Run("gathers the dashboards protected by each feature", func(t *testing.T) {
+		t.Parallel()
+
+		client := &mockGrafanaClient{
+			alertRules: func(_ context.Context, _ string) ([]AlertRule, error) {
+				return []AlertRule{
+					{Title: "High latency", Annotations: map[string]string{dashboardUIDAnnotation: "latency"}},
+				}, nil
+			},
+			starredDashboards: func(_ context.Context, _ string) ([]StarredDashboard, error) {
+				return []StarredDashboard{{UID: "latency", Users: 1}, {UID: "hosts", Users: 2}}, nil
+			},
+			publicDashboards: func(_ context.Context, _ string) ([]PublicDashboard, error) {
+				return []PublicDashboard{
+					{UID: "p1", DashboardUID: "status", Enabled: true},
+					{UID: "p2", DashboardUID: "paused", Enabled: false},
+				}, nil
+			},
+			snapshots: func(_ context.Context, _ string) ([]Snapshot, error) {
+				return []Snapshot{{Key: "s1", Name: "Review", DashboardUID: "review", Expires: start.Add(time.Hour)}}, nil
+			},
+		}
+		snoozes := &mockSnoozes{
+			snoozed: func(_ string, _ time.Time) (map[string]time.Time, error) {
+				return map[string]time.Time{"errors": start.Add(time.Hour)}, nil
+			},
+		}
+
+		l, logs := logger()
+		pruner := NewDashboardPruner(&NewDashboardPrunerOptions{
+			Grafana:           client,
+			Logger:            l,
+			Namespace:         "default",
+			ProtectAlertRules: true,
+			ProtectStarred:    true,
+			ProtectPublic:     true,
+			ProtectSnapshots:  true,
+			Snoozes:           snoozes,
+		})
+
+		p, err := pruner.gatherProtections(t.Context(), nil, nil, start)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[DashboardKey]string{key("latency"): `linked from alert rule "High latency"`}, p.alertRules)
+		assert.Equal(
+			t,
+			map[DashboardKey]string{key("latency"): "starred by 1 user(s)", key("hosts"): "starred by 2 user(s)"},
+			p.starred,
+		)
+		assert.Equal(t, map[DashboardKey]string{key("status"): `published as public dashboard "p1"`}, p.public)
+		assert.Equal(t, map[DashboardKey]string{key("review"): `shared as snapshot "Review"`}, p.snapshots)
+		assert.Empty(t, p.references)
+		assert.Equal(t, map[DashboardKey]string{key("errors"): "snoozed until 2026-10-01T13:00:00Z"}, p.snoozed)
+		assert.Len(t, p.all(), 5)
+
+		// A dashboard that several features protect is only logged as protected by the first of them.
+		assert.True(t, p.protects(l, &Dashboard{Name: "latency", Namespace: "default"}))
+		assert.Contains(t, logs.String(), `"msg":"Skipping dashboard linked from alert rule"`)
+		assert.NotContains(t, logs.String(), `"msg":"Skipping starred dashboard"`)
+
+		assert.False(t, p.protects(l, &Dashboard{Name: "paused", Namespace: "default"}))
+	})
+}
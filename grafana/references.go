@@ -0,0 +1,252 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// playlistItemByUID is the type of playlist items that refer to a single dashboard by its UID.
+	playlistItemByUID = "dashboard_by_uid"
+	// defaultReferenceDepth is the default number of links that are followed from referencing dashboards.
+	defaultReferenceDepth = 1
+)
+
+// dashboardURL matches the UID in the URL of a dashboard, e.g., "/d/abc123/my-dashboard?orgId=1" or
+// "https://grafana.example.com/d-solo/abc123".
+var dashboardURL = regexp.MustCompile(`(?:^|/)d(?:-solo)?/([^/?#]+)`)
+
+// Playlist is a Grafana playlist.
+type Playlist struct {
+	UID   string
+	Name  string
+	Items []PlaylistItem
+}
+
+// PlaylistItem is an entry of a playlist. Type is "dashboard_by_uid" for items that refer to a single dashboard, in
+// which case Value is the dashboard's UID.
+type PlaylistItem struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// HomeDashboard is a dashboard that is set as the home dashboard of the organisation or of a team.
+type HomeDashboard struct {
+	UID string
+	// Team is the name of the team whose home dashboard it is. Team is empty for the home dashboard of the
+	// organisation.
+	Team string
+}
+
+type playlistResponse struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+type preferencesResponse struct {
+	HomeDashboardUID string `json:"homeDashboardUID"`
+}
+
+// linkSpec holds the parts of a dashboard spec that can link to other dashboards.
+type linkSpec struct {
+	Links  []link      `json:"links"`
+	Panels []panelSpec `json:"panels"`
+}
+
+type panelSpec struct {
+	Links       []link `json:"links"`
+	FieldConfig struct {
+		Defaults struct {
+			Links []link `json:"links"`
+		} `json:"defaults"`
+	} `json:"fieldConfig"`
+	// Panels holds the panels of a collapsed row.
+	Panels []panelSpec `json:"panels"`
+}
+
+type link struct {
+	URL string `json:"url"`
+}
+
+// Playlists returns all playlists of the organisation that namespace belongs to, along with their items.
+//
+// Playlists uses the Grafana HTTP API endpoints GET /api/playlists and GET /api/playlists/:uid/items.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/playlist/.
+func (c *Client) Playlists(ctx context.Context, namespace string) ([]Playlist, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []playlistResponse
+	if err := c.getJSON(ctx, c.endpoint.JoinPath("api", "playlists"), id, &responses); err != nil {
+		return nil, errors.Wrap(err, "getting playlists")
+	}
+
+	playlists := make([]Playlist, 0, len(responses))
+	for _, response := range responses {
+		var items []PlaylistItem
+		u := c.endpoint.JoinPath("api", "playlists", response.UID, "items")
+		if err := c.getJSON(ctx, u, id, &items); err != nil {
+			return nil, errors.Wrapf(err, "getting items of playlist %q", response.Name)
+		}
+
+		playlists = append(playlists, Playlist{UID: response.UID, Name: response.Name, Items: items})
+	}
+
+	return playlists, nil
+}
+
+// HomeDashboards returns the home dashboards of the organisation that namespace belongs to and of its teams.
+//
+// HomeDashboards uses the Grafana HTTP API endpoints GET /api/org/preferences, GET /api/teams/search and
+// GET /api/teams/:id/preferences.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/preferences/.
+func (c *Client) HomeDashboards(ctx context.Context, namespace string) ([]HomeDashboard, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var homes []HomeDashboard
+
+	var org preferencesResponse
+	if err := c.getJSON(ctx, c.endpoint.JoinPath("api", "org", "preferences"), id, &org); err != nil {
+		return nil, errors.Wrap(err, "getting organisation preferences")
+	}
+	if org.HomeDashboardUID != "" {
+		homes = append(homes, HomeDashboard{UID: org.HomeDashboardUID})
+	}
+
+	var teams []team
+	for page := 1; ; page++ {
+		u := c.endpoint.JoinPath("api", "teams", "search")
+		q := u.Query()
+		q.Set("perpage", strconv.Itoa(legacyPageSize))
+		q.Set("page", strconv.Itoa(page))
+		u.RawQuery = q.Encode()
+
+		var response teamSearchResponse
+		if err := c.getJSON(ctx, u, id, &response); err != nil {
+			return nil, errors.Wrap(err, "getting teams page")
+		}
+
+		teams = append(teams, response.Teams...)
+
+		if len(response.Teams) == 0 || len(teams) >= response.TotalCount {
+			break
+		}
+	}
+
+	for _, t := range teams {
+		var preferences preferencesResponse
+		u := c.endpoint.JoinPath("api", "teams", strconv.FormatInt(t.ID, 10), "preferences")
+		if err := c.getJSON(ctx, u, id, &preferences); err != nil {
+			return nil, errors.Wrapf(err, "getting preferences of team %q", t.Name)
+		}
+		if preferences.HomeDashboardUID != "" {
+			homes = append(homes, HomeDashboard{UID: preferences.HomeDashboardUID, Team: t.Name})
+		}
+	}
+
+	return homes, nil
+}
+
+// linkedDashboards returns the UIDs of the dashboards that the dashboard links and panel links of spec point to, in
+// the order in which they appear. Dashboard links that list dashboards by tag are not included, as they do not break
+// if a dashboard is deleted.
+func linkedDashboards(spec json.RawMessage) []string {
+	var s linkSpec
+	if err := json.Unmarshal(spec, &s); err != nil {
+		return nil
+	}
+
+	var uids []string
+	collect := func(links []link) {
+		for _, l := range links {
+			if matches := dashboardURL.FindStringSubmatch(l.URL); matches != nil {
+				uids = append(uids, matches[1])
+			}
+		}
+	}
+
+	var collectPanels func(panels []panelSpec)
+	collectPanels = func(panels []panelSpec) {
+		for i := range panels {
+			collect(panels[i].Links)
+			collect(panels[i].FieldConfig.Defaults.Links)
+			collectPanels(panels[i].Panels)
+		}
+	}
+
+	collect(s.Links)
+	collectPanels(s.Panels)
+
+	return uids
+}
+
+// referenceGraph returns the reason why each unused dashboard of all is referenced. roots holds the dashboards that are
+// referenced by configuration, e.g., playlists, along with the reason. used returns true for dashboards that are used.
+//
+// A dashboard is referenced if a used dashboard or a root links to it within depth links. With a depth of 1, only the
+// dashboards that used dashboards and roots link to directly are referenced, so a chain of unused dashboards is not
+// kept alive by a single link from a used dashboard.
+func referenceGraph(
+	all []Dashboard,
+	roots map[DashboardKey]string,
+	depth int,
+	used func(dashboard *Dashboard) bool,
+) map[DashboardKey]string {
+	type source struct {
+		dashboard *Dashboard
+		// depth is the number of links between dashboard and the used dashboard or root that it is reached from.
+		depth int
+	}
+
+	dashboards := make(map[DashboardKey]*Dashboard, len(all))
+	for i := range all {
+		dashboards[all[i].Key()] = &all[i]
+	}
+
+	references := make(map[DashboardKey]string, len(roots))
+	var queue []source
+	for i := range all {
+		dashboard := &all[i]
+		if used(dashboard) {
+			queue = append(queue, source{dashboard: dashboard})
+		} else if reason, ok := roots[dashboard.Key()]; ok {
+			references[dashboard.Key()] = reason
+			queue = append(queue, source{dashboard: dashboard})
+		}
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s.depth >= depth {
+			continue
+		}
+
+		for _, uid := range linkedDashboards(s.dashboard.Spec) {
+			// The UID of a dashboard is the name of the dashboard in Grafana v12's API.
+			key := DashboardKey{name: uid, namespace: s.dashboard.Namespace}
+			target, ok := dashboards[key]
+			if !ok || used(target) {
+				continue
+			}
+			if _, referenced := references[key]; referenced {
+				continue
+			}
+
+			references[key] = fmt.Sprintf("linked from dashboard %q", s.dashboard.Name)
+			queue = append(queue, source{dashboard: target, depth: s.depth + 1})
+		}
+	}
+
+	return references
+}
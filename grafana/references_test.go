@@ -0,0 +1,111 @@
+package grafana
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkedDashboards(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		spec         string
+		expectedUIDs []string
+	}{
+		"returns nothing for invalid spec": {
+			spec:         `not json`,
+			expectedUIDs: nil,
+		},
+		"returns nothing for spec without links": {
+			spec:         `{"title":"Overview","panels":[{"type":"timeseries"}]}`,
+			expectedUIDs: nil,
+		},
+		"returns dashboard links": {
+			spec: `{"links":[
+				{"type":"link","url":"/d/details/details-dashboard?orgId=1"},
+				{"type":"dashboards","tags":["team-a"],"url":""},
+				{"type":"link","url":"https://example.com/docs"}
+			]}`,
+			expectedUIDs: []string{"details"},
+		},
+		"returns panel links, data links and links of panels in collapsed rows": {
+			spec: `{"panels":[
+				{"type":"stat","links":[{"url":"https://grafana.example.com/d/panel-link"}]},
+				{"type":"table","fieldConfig":{"defaults":{"links":[{"url":"/d-solo/data-link?var-host=${__value.raw}"}]}}},
+				{"type":"row","collapsed":true,"panels":[
+					{"type":"stat","links":[{"url":"d/row-link#panel-2"}]}
+				]}
+			]}`,
+			expectedUIDs: []string{"panel-link", "data-link", "row-link"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expectedUIDs, linkedDashboards(json.RawMessage(tt.spec)))
+		})
+	}
+}
+
+func TestReferenceGraph(t *testing.T) {
+	t.Parallel()
+
+	dashboard := func(name, namespace, spec string) Dashboard {
+		return Dashboard{Name: name, Namespace: namespace, Spec: json.RawMessage(spec)}
+	}
+
+	all := []Dashboard{
+		dashboard("overview", "default", `{"links":[{"url":"/d/details"},{"url":"/d/used"}]}`),
+		dashboard("details", "default", `{"panels":[{"links":[{"url":"/d/drilldown"}]}]}`),
+		dashboard("drilldown", "default", `{"links":[{"url":"/d/details"}]}`),
+		dashboard("used", "default", `{}`),
+		dashboard("tv", "default", `{"links":[{"url":"/d/tv-details"}]}`),
+		dashboard("tv-details", "default", `{}`),
+		dashboard("orphan", "default", `{"links":[{"url":"/d/orphan-details"}]}`),
+		dashboard("orphan-details", "default", `{}`),
+		// Links only refer to dashboards in the namespace of the linking dashboard.
+		dashboard("details", "org-2", `{}`),
+	}
+	roots := map[DashboardKey]string{
+		{name: "tv", namespace: "default"}:   `included in playlist "NOC"`,
+		{name: "used", namespace: "default"}: `included in playlist "NOC"`,
+	}
+	used := func(dashboard *Dashboard) bool {
+		return dashboard.Name == "overview" || dashboard.Name == "used"
+	}
+
+	tests := map[string]struct {
+		depth    int
+		expected map[DashboardKey]string
+	}{
+		"references dashboards linked directly from used dashboards and roots": {
+			depth: 1,
+			expected: map[DashboardKey]string{
+				{name: "details", namespace: "default"}:    `linked from dashboard "overview"`,
+				{name: "tv", namespace: "default"}:         `included in playlist "NOC"`,
+				{name: "tv-details", namespace: "default"}: `linked from dashboard "tv"`,
+			},
+		},
+		"follows links up to depth": {
+			depth: 2,
+			expected: map[DashboardKey]string{
+				{name: "details", namespace: "default"}:    `linked from dashboard "overview"`,
+				{name: "drilldown", namespace: "default"}:  `linked from dashboard "details"`,
+				{name: "tv", namespace: "default"}:         `included in playlist "NOC"`,
+				{name: "tv-details", namespace: "default"}: `linked from dashboard "tv"`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, referenceGraph(all, roots, tt.depth, used))
+		})
+	}
+}
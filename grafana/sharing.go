@@ -0,0 +1,201 @@
+package grafana
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// dashboardGroup and dashboardKind identify dashboards among the resources that a user has starred.
+	dashboardGroup = "dashboard.grafana.app"
+	dashboardKind  = "Dashboard"
+	// snapshotLimit is the maximum number of snapshots that Grafana lists. Grafana's snapshot API is not paginated.
+	snapshotLimit = 10000
+)
+
+// StarredDashboard is a dashboard that at least one user has starred.
+type StarredDashboard struct {
+	UID string
+	// Users is the number of users that have starred the dashboard.
+	Users int
+}
+
+// PublicDashboard is the configuration that publishes a dashboard as a public dashboard, i.e., a dashboard that anyone
+// with its link can view without signing in to Grafana.
+type PublicDashboard struct {
+	UID          string `json:"uid"`
+	DashboardUID string `json:"dashboardUid"`
+	// Enabled is false if the public dashboard is paused, in which case it cannot be viewed.
+	Enabled bool `json:"isEnabled"`
+}
+
+// Snapshot is a snapshot of a dashboard, i.e., a copy of the dashboard and its data that anyone with its link can view
+// without signing in to Grafana.
+type Snapshot struct {
+	Key  string
+	Name string
+	// DashboardUID is the UID of the dashboard that the snapshot was taken of. DashboardUID is empty if the snapshot
+	// was published to an external server, as Grafana does not keep the dashboard of such snapshots.
+	DashboardUID string
+	// Expires is the time at which Grafana deletes the snapshot.
+	Expires time.Time
+}
+
+type snapshotListItem struct {
+	Key     string    `json:"key"`
+	Name    string    `json:"name"`
+	Expires time.Time `json:"expires"`
+}
+
+type snapshotResponse struct {
+	Dashboard struct {
+		UID string `json:"uid"`
+	} `json:"dashboard"`
+}
+
+type starsListResponse struct {
+	Metadata listMetadata `json:"metadata"`
+	Items    []starsItem  `json:"items"`
+}
+
+type starsItem struct {
+	Spec struct {
+		Resource []starredResource `json:"resource"`
+	} `json:"spec"`
+}
+
+type starredResource struct {
+	Group string   `json:"group"`
+	Kind  string   `json:"kind"`
+	Names []string `json:"names"`
+}
+
+type publicDashboardListResponse struct {
+	TotalCount       int               `json:"totalCount"`
+	PublicDashboards []PublicDashboard `json:"publicDashboards"`
+}
+
+// StarredDashboards returns the dashboards in namespace that any user has starred, sorted by UID.
+//
+// StarredDashboards uses the Grafana HTTP API endpoint GET /apis/preferences.grafana.app/v1alpha1/namespaces/:namespace/stars,
+// which lists the stars of every user in namespace. StarredDashboards handles pagination automatically and fetches all
+// pages.
+//
+//nolint:lll
+func (c *Client) StarredDashboards(ctx context.Context, namespace string) ([]StarredDashboard, error) {
+	users := make(map[string]int)
+	continueToken := ""
+
+	for {
+		u := c.endpoint.JoinPath("apis", "preferences.grafana.app", "v1alpha1", "namespaces", namespace, "stars")
+
+		var response starsListResponse
+		if err := c.listPage(ctx, u, 500, continueToken, &response); err != nil {
+			return nil, errors.Wrap(err, "getting stars page")
+		}
+
+		for _, item := range response.Items {
+			for _, resource := range item.Spec.Resource {
+				if resource.Group != dashboardGroup || resource.Kind != dashboardKind {
+					continue
+				}
+				for _, name := range resource.Names {
+					users[name]++
+				}
+			}
+		}
+
+		if response.Metadata.Continue == "" {
+			break
+		}
+
+		continueToken = response.Metadata.Continue
+	}
+
+	starred := make([]StarredDashboard, 0, len(users))
+	for uid, count := range users {
+		starred = append(starred, StarredDashboard{UID: uid, Users: count})
+	}
+
+	sort.Slice(starred, func(i, j int) bool {
+		return starred[i].UID < starred[j].UID
+	})
+
+	return starred, nil
+}
+
+// PublicDashboards returns the public dashboards of the organisation that namespace belongs to, including paused ones.
+//
+// PublicDashboards uses the Grafana HTTP API endpoint GET /api/dashboards/public-dashboards and fetches all pages.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/dashboard_public/.
+func (c *Client) PublicDashboards(ctx context.Context, namespace string) ([]PublicDashboard, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var public []PublicDashboard
+	for page := 1; ; page++ {
+		u := c.endpoint.JoinPath("api", "dashboards", "public-dashboards")
+		q := u.Query()
+		q.Set("perpage", strconv.Itoa(legacyPageSize))
+		q.Set("page", strconv.Itoa(page))
+		u.RawQuery = q.Encode()
+
+		var response publicDashboardListResponse
+		if err := c.getJSON(ctx, u, id, &response); err != nil {
+			return nil, errors.Wrap(err, "getting public dashboards page")
+		}
+
+		public = append(public, response.PublicDashboards...)
+
+		if len(response.PublicDashboards) == 0 || len(public) >= response.TotalCount {
+			break
+		}
+	}
+
+	return public, nil
+}
+
+// Snapshots returns the snapshots of the organisation that namespace belongs to, including expired ones.
+//
+// Snapshots uses the Grafana HTTP API endpoints GET /api/dashboard/snapshots and GET /api/snapshots/:key, as only the
+// latter includes the UID of the dashboard that a snapshot was taken of. Grafana lists at most snapshotLimit snapshots.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/snapshot/.
+func (c *Client) Snapshots(ctx context.Context, namespace string) ([]Snapshot, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.endpoint.JoinPath("api", "dashboard", "snapshots")
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(snapshotLimit))
+	u.RawQuery = q.Encode()
+
+	var items []snapshotListItem
+	if err := c.getJSON(ctx, u, id, &items); err != nil {
+		return nil, errors.Wrap(err, "getting snapshots")
+	}
+
+	snapshots := make([]Snapshot, 0, len(items))
+	for _, item := range items {
+		var response snapshotResponse
+		if err := c.getJSON(ctx, c.endpoint.JoinPath("api", "snapshots", item.Key), id, &response); err != nil {
+			return nil, errors.Wrapf(err, "getting snapshot %q", item.Name)
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Key:          item.Key,
+			Name:         item.Name,
+			DashboardUID: response.Dashboard.UID,
+			Expires:      item.Expires,
+		})
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,264 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+// tombstoneAnnotation is the annotation that marks a dashboard as the tombstone of a deleted dashboard. The annotation
+// holds the time at which the deleted dashboard was deleted.
+const tombstoneAnnotation = "frigg-tombstone"
+
+// tombstoneHashAnnotation is the annotation that holds the hash of the spec of a tombstone, see tombstoneHash.
+const tombstoneHashAnnotation = "frigg-tombstone-hash"
+
+type dashboardResource struct {
+	Metadata dashboardResourceMetadata `json:"metadata"`
+	Spec     any                       `json:"spec"`
+}
+
+type dashboardResourceMetadata struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CreateDashboard creates a dashboard with name as its UID, annotations and spec in namespace. The dashboard is created
+// in folder, or at the root if folder is empty.
+//
+// CreateDashboard uses the Grafana HTTP API endpoint POST
+// /apis/dashboard.grafana.app/v1beta1/namespaces/:namespace/dashboards.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/dashboard/.
+func (c *Client) CreateDashboard(
+	ctx context.Context,
+	namespace, name, folder string,
+	annotations map[string]string,
+	spec any,
+) error {
+	if name == "" {
+		return errors.New("dashboard name must not be empty")
+	}
+
+	metadata := dashboardResourceMetadata{Name: name, Annotations: make(map[string]string, len(annotations)+1)}
+	for key, value := range annotations {
+		metadata.Annotations[key] = value
+	}
+	if folder != "" {
+		metadata.Annotations[folderAnnotation] = folder
+	}
+
+	u := c.endpoint.JoinPath("apis", "dashboard.grafana.app", "v1beta1", "namespaces", namespace, "dashboards")
+	if err := c.sendJSON(ctx, http.MethodPost, u, 0, dashboardResource{Metadata: metadata, Spec: spec}, nil); err != nil {
+		return errors.Wrapf(err, "creating dashboard %s", name)
+	}
+
+	return nil
+}
+
+// ImportURL returns the URL of the page on which dashboards are imported into the organisation of namespace.
+func (c *Client) ImportURL(namespace string) (string, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	u := c.endpoint.JoinPath("dashboard", "import")
+	u.RawQuery = url.Values{"orgId": {strconv.FormatInt(id, 10)}}.Encode()
+
+	return u.String(), nil
+}
+
+// Tombstone returns true if d is the tombstone of a deleted dashboard. A dashboard with the tombstone annotations whose
+// spec no longer matches the tombstone is not a tombstone, e.g., a deleted dashboard that was restored over its
+// tombstone in a way that kept the annotations.
+func (d *Dashboard) Tombstone() bool {
+	if _, ok := d.Annotations[tombstoneAnnotation]; !ok {
+		return false
+	}
+
+	hash, err := tombstoneHash(d.Spec)
+
+	return err == nil && hash == d.Annotations[tombstoneHashAnnotation]
+}
+
+// tombstoneHash returns the hash of the canonical JSON of spec. The JSON is canonicalised so that the spec of a
+// tombstone as created by Frigg and as returned by Grafana hash the same even if Grafana orders or spaces its keys
+// differently.
+func tombstoneHash(spec any) (string, error) {
+	buf, err := json.Marshal(spec)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding tombstone spec")
+	}
+
+	var canonical any
+	if err := json.Unmarshal(buf, &canonical); err != nil {
+		return "", errors.Wrap(err, "decoding tombstone spec")
+	}
+
+	buf, err = json.Marshal(canonical)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding canonical tombstone spec")
+	}
+
+	return backup.Hash(buf), nil
+}
+
+// tombstoneExpired returns true if the tombstone dashboard has been kept for the tombstone period at start. A
+// tombstone without a valid deletion time is considered created when it was last modified.
+func (d *DashboardPruner) tombstoneExpired(dashboard *Dashboard, start time.Time) bool {
+	deletedAt, err := time.Parse(time.RFC3339, dashboard.Annotations[tombstoneAnnotation])
+	if err != nil {
+		deletedAt = dashboard.LastModified()
+	}
+
+	return !start.Before(deletedAt.Add(d.tombstone.Period))
+}
+
+// removeTombstone deletes the tombstone dashboard without backing it up if it has expired. Tombstones are never deleted
+// if tombstones are disabled, as their period is unknown. Provisioned tombstones are never deleted, and deleted
+// tombstones count towards the maximum deletions of the run.
+func (d *DashboardPruner) removeTombstone(
+	ctx context.Context,
+	logger *slog.Logger,
+	dashboard *Dashboard,
+	start time.Time,
+	result *runResult,
+) error {
+	if d.tombstone == nil || !d.tombstoneExpired(dashboard, start) {
+		logger.Debug("Skipping tombstone of deleted dashboard")
+		return nil
+	}
+
+	if dashboard.Provisioned() {
+		logger.Debug("Skipping provisioned tombstone", slog.String("managed_by", *dashboard.ManagedBy))
+		return nil
+	}
+
+	if d.dry {
+		logger.Info("Found expired tombstone, skipping deletion due to dry run")
+		return nil
+	}
+
+	if d.maxDeletions != nil && result.deletions() >= *d.maxDeletions {
+		result.skippedDueToLimit++
+		return nil
+	}
+
+	if err := d.grafana.DeleteBackedUpDashboard(ctx, dashboard.Namespace, dashboard.Name); err != nil {
+		return fmt.Errorf("deleting expired tombstone %s: %w", dashboard.UID, err)
+	}
+	logger.Info("Deleted expired tombstone")
+	result.removedTombstones++
+
+	return nil
+}
+
+// createTombstone creates a tombstone under the UID of the deleted dashboard that explains why the dashboard was
+// deleted and how to restore it. createTombstone logs failures instead of returning them as the dashboard has already
+// been deleted.
+func (d *DashboardPruner) createTombstone(
+	ctx context.Context,
+	logger *slog.Logger,
+	dashboard *backup.Dashboard,
+	reason string,
+	start time.Time,
+) {
+	if d.tombstone == nil {
+		return
+	}
+
+	importURL, err := d.grafana.ImportURL(dashboard.Namespace)
+	if err != nil {
+		logger.Error("Failed to build restore link of deleted dashboard", slog.String("error", err.Error()))
+		return
+	}
+
+	// Dashboards that Frigg archived are backed up with the folder that they were archived from, see originalFolder.
+	// Users cannot see tombstones in the archive folder, so tombstones of dashboards that users moved to the archive
+	// folder themselves are created at the root.
+	folder := dashboard.FolderUID
+	if d.archive != nil && folder == d.archive.Folder {
+		folder = ""
+	}
+
+	spec := tombstoneSpec(dashboard, reason, importURL, start, start.Add(d.tombstone.Period))
+	hash, err := tombstoneHash(spec)
+	if err != nil {
+		logger.Error("Failed to hash tombstone of deleted dashboard", slog.String("error", err.Error()))
+		return
+	}
+
+	// The hash tells a tombstone apart from a deleted dashboard that was restored over it, see Dashboard.Tombstone.
+	annotations := map[string]string{
+		tombstoneAnnotation:     start.UTC().Format(time.RFC3339),
+		tombstoneHashAnnotation: hash,
+	}
+	if err := d.grafana.CreateDashboard(ctx, dashboard.Namespace, dashboard.Name, folder, annotations, spec); err != nil {
+		logger.Error("Failed to create tombstone of deleted dashboard", slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info("Created tombstone of deleted dashboard")
+}
+
+// tombstoneSpec returns the spec of the tombstone of dashboard, which holds a single text panel. dashboard was deleted
+// at deletedAt and the tombstone is removed after until. importURL is the page on which the backup of dashboard is
+// imported to restore it.
+func tombstoneSpec(dashboard *backup.Dashboard, reason, importURL string, deletedAt, until time.Time) map[string]any {
+	var content strings.Builder
+	fmt.Fprintf(
+		&content,
+		"## %s was deleted\n\nFrigg deleted this dashboard on %s as %s.\n\n",
+		dashboard.Title,
+		deletedAt.UTC().Format(time.RFC3339),
+		reason,
+	)
+	fmt.Fprintf(&content, "The dashboard was backed up to %s.\n\n", dashboard.Location)
+	fmt.Fprintf(
+		&content,
+		"To restore the dashboard, download its backup and [import it](%s), overwriting this tombstone. The restored "+
+			"dashboard keeps its UID, so links to it work again.\n\n",
+		importURL,
+	)
+	fmt.Fprintf(&content, "This tombstone is removed after %s.\n", until.UTC().Format(time.RFC3339))
+
+	return map[string]any{
+		"title":    dashboard.Title + " (deleted)",
+		"editable": false,
+		"panels": []map[string]any{
+			{
+				"id":      1,
+				"type":    "text",
+				"title":   "",
+				"gridPos": map[string]int{"h": 12, "w": 24, "x": 0, "y": 0},
+				"options": map[string]any{
+					"mode":    "markdown",
+					"content": content.String(),
+				},
+			},
+		},
+	}
+}
+
+// deletionReason returns why dashboard is deleted in a form that completes the sentence "Frigg deleted this dashboard
+// as ...". period is the period in which the dashboard was not used.
+func deletionReason(dashboard *Dashboard, decision policyDecision, start time.Time, period time.Duration) string {
+	switch {
+	case dashboard.Expired(start):
+		return fmt.Sprintf("its TTL of %s had expired", dashboard.TTL)
+	case decision.force != "":
+		return fmt.Sprintf("it matched the policy expression `%s`", decision.force)
+	default:
+		return fmt.Sprintf("it had not been used in the preceding %s", period)
+	}
+}
@@ -0,0 +1,179 @@
+package grafana
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// legacyPageSize is the page size used when listing users, service accounts and teams through Grafana's legacy HTTP
+// API.
+const legacyPageSize = 500
+
+// OrgUser is a user of the organisation that a namespace belongs to.
+type OrgUser struct {
+	Login string `json:"login"`
+	// Role of the user in the organisation, e.g., "Admin".
+	Role  string `json:"role"`
+	UID   string `json:"uid"`
+	Email string `json:"email"`
+}
+
+type serviceAccountSearchResponse struct {
+	TotalCount      int              `json:"totalCount"`
+	ServiceAccounts []serviceAccount `json:"serviceAccounts"`
+}
+
+type serviceAccount struct {
+	Login string `json:"login"`
+}
+
+type teamSearchResponse struct {
+	TotalCount int    `json:"totalCount"`
+	Teams      []team `json:"teams"`
+}
+
+type team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type teamMember struct {
+	Login string `json:"login"`
+}
+
+// OrgUsers returns all users of the organisation that namespace belongs to, along with their role in the organisation.
+//
+// OrgUsers uses the Grafana HTTP API endpoint GET /api/org/users.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/org/#get-all-users-within-the-current-organization.
+//
+//nolint:lll
+func (c *Client) OrgUsers(ctx context.Context, namespace string) ([]OrgUser, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []OrgUser
+	if err := c.getJSON(ctx, c.endpoint.JoinPath("api", "org", "users"), id, &users); err != nil {
+		return nil, errors.Wrap(err, "getting organisation users")
+	}
+
+	return users, nil
+}
+
+// ServiceAccounts returns the logins of all service accounts of the organisation that namespace belongs to. Grafana
+// logs the login of a service account as the user name of its requests.
+//
+// ServiceAccounts uses the Grafana HTTP API endpoint GET /api/serviceaccounts/search and fetches all pages.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/serviceaccount/#search-service-accounts-with-paging.
+//
+//nolint:lll
+func (c *Client) ServiceAccounts(ctx context.Context, namespace string) ([]string, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var logins []string
+	for page := 1; ; page++ {
+		u := c.endpoint.JoinPath("api", "serviceaccounts", "search")
+		q := u.Query()
+		q.Set("perpage", strconv.Itoa(legacyPageSize))
+		q.Set("page", strconv.Itoa(page))
+		u.RawQuery = q.Encode()
+
+		var response serviceAccountSearchResponse
+		if err := c.getJSON(ctx, u, id, &response); err != nil {
+			return nil, errors.Wrap(err, "getting service accounts page")
+		}
+
+		for _, account := range response.ServiceAccounts {
+			logins = append(logins, account.Login)
+		}
+
+		if len(response.ServiceAccounts) == 0 || len(logins) >= response.TotalCount {
+			break
+		}
+	}
+
+	return logins, nil
+}
+
+// TeamMembers returns the logins of the members of the team with the given name in the organisation that namespace
+// belongs to. The returned bool is false if no team has the given name.
+//
+// TeamMembers uses the Grafana HTTP API endpoints GET /api/teams/search and GET /api/teams/:id/members.
+// See https://grafana.com/docs/grafana/v12.2/developer-resources/api-reference/http-api/team/.
+func (c *Client) TeamMembers(ctx context.Context, namespace, name string) ([]string, bool, error) {
+	id, err := orgID(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+
+	u := c.endpoint.JoinPath("api", "teams", "search")
+	q := u.Query()
+	q.Set("name", name)
+	u.RawQuery = q.Encode()
+
+	var response teamSearchResponse
+	if err := c.getJSON(ctx, u, id, &response); err != nil {
+		return nil, false, errors.Wrapf(err, "searching for team %q", name)
+	}
+
+	for _, t := range response.Teams {
+		if t.Name != name {
+			continue
+		}
+
+		var members []teamMember
+		u := c.endpoint.JoinPath("api", "teams", strconv.FormatInt(t.ID, 10), "members")
+		if err := c.getJSON(ctx, u, id, &members); err != nil {
+			return nil, false, errors.Wrapf(err, "getting members of team %q", name)
+		}
+
+		logins := make([]string, 0, len(members))
+		for _, member := range members {
+			logins = append(logins, member.Login)
+		}
+
+		return logins, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// userFilter decides whether the reads of a user are ignored.
+type userFilter struct {
+	users    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+func newUserFilter(users []string, patterns []*regexp.Regexp) *userFilter {
+	f := &userFilter{
+		users:    make(map[string]struct{}, len(users)),
+		patterns: patterns,
+	}
+	for _, user := range users {
+		f.users[user] = struct{}{}
+	}
+
+	return f
+}
+
+// ignored returns true if user is one of the ignored users or matches any of the ignored patterns.
+func (f *userFilter) ignored(user string) bool {
+	if _, ok := f.users[user]; ok {
+		return true
+	}
+
+	for _, pattern := range f.patterns {
+		if pattern.MatchString(user) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -96,7 +96,7 @@ func (c *Client) QueryRange(ctx context.Context, query string, start, end time.T
 	currentStart := start
 
 	for {
-		logs, maxTimestamp, err := c.queryRangePage(ctx, query, currentStart, end)
+		logs, maxTimestamp, err := c.queryRangePage(ctx, query, currentStart, end, c.limit)
 		if err != nil {
 			return nil, err
 		}
@@ -120,9 +120,33 @@ func (c *Client) QueryRange(ctx context.Context, query string, start, end time.T
 	return allLogs, nil
 }
 
+// OldestLog returns the oldest log that matches query in the range between start and end. The returned bool is false
+// if no log matches.
+//
+// OldestLog executes a single query_range request with a limit of one, which makes it a cheap way to probe how far back
+// Loki holds logs.
+func (c *Client) OldestLog(ctx context.Context, query string, start, end time.Time) (Log, bool, error) {
+	logs, _, err := c.queryRangePage(ctx, query, start, end, 1)
+	if err != nil {
+		return Log{}, false, err
+	}
+
+	if len(logs) == 0 {
+		return Log{}, false, nil
+	}
+
+	return logs[0], true, nil
+}
+
 // queryRangePage executes a single query_range request to Loki.
 // Returns the logs, the maximum timestamp among all logs (for pagination), and any error.
-func (c *Client) queryRangePage(ctx context.Context, query string, start, end time.Time) ([]Log, time.Time, error) {
+func (c *Client) queryRangePage(
+	ctx context.Context,
+	query string,
+	start,
+	end time.Time,
+	limit int,
+) ([]Log, time.Time, error) {
 	u, err := url.Parse(fmt.Sprintf("%s/loki/api/v1/query_range", c.endpoint))
 	if err != nil {
 		return nil, time.Time{}, fmt.Errorf("parsing URL: %w", err)
@@ -132,7 +156,7 @@ func (c *Client) queryRangePage(ctx context.Context, query string, start, end ti
 	q.Set("query", query)
 	q.Set("start", fmt.Sprintf("%d", start.UnixNano()))
 	q.Set("end", fmt.Sprintf("%d", end.UnixNano()))
-	q.Set("limit", strconv.Itoa(c.limit))
+	q.Set("limit", strconv.Itoa(limit))
 	q.Set("direction", "forward")
 	u.RawQuery = q.Encode()
 
@@ -365,6 +365,96 @@ func TestClient_QueryRange(t *testing.T) {
 	})
 }
 
+func TestClient_OldestLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns oldest log", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockHTTPClient{
+			responses: []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: io.NopCloser(strings.NewReader(`{
+						"status": "success",
+						"data": {
+							"resultType": "streams",
+							"result": [
+								{
+									"stream": {"app": "test"},
+									"values": [["1609459200000000000", "log message 1"]]
+								}
+							]
+						}
+					}`)),
+				},
+			},
+		}
+
+		client := loki.NewClient(loki.ClientOptions{
+			Endpoint:   "http://localhost:1234",
+			HTTPClient: mock,
+			Logger:     slog.Default(),
+			Limit:      100,
+		})
+
+		log, found, err := client.OldestLog(t.Context(), `{app="test"}`, time.Now().Add(-1*time.Hour), time.Now())
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), log.Timestamp())
+		assert.Equal(t, "log message 1", log.Message())
+
+		require.NotNil(t, mock.lastRequest)
+		assert.Equal(t, "1", mock.lastRequest.URL.Query().Get("limit"))
+		assert.Equal(t, "forward", mock.lastRequest.URL.Query().Get("direction"))
+		assert.Equal(t, 1, mock.callCount)
+	})
+
+	t.Run("reports no log if no log matches", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockHTTPClient{
+			responses: []*http.Response{
+				{
+					StatusCode: http.StatusOK,
+					Body: io.NopCloser(strings.NewReader(`{
+						"status": "success",
+						"data": {
+							"resultType": "streams",
+							"result": []
+						}
+					}`)),
+				},
+			},
+		}
+
+		client := loki.NewClient(loki.ClientOptions{
+			Endpoint:   "http://localhost:1234",
+			HTTPClient: mock,
+			Logger:     slog.Default(),
+			Limit:      100,
+		})
+
+		_, found, err := client.OldestLog(t.Context(), `{app="test"}`, time.Now().Add(-1*time.Hour), time.Now())
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("errors if request fails", func(t *testing.T) {
+		t.Parallel()
+
+		client := loki.NewClient(loki.ClientOptions{
+			Endpoint:   "http://localhost:1234",
+			HTTPClient: &mockHTTPClient{err: errors.New("connection refused")},
+			Logger:     slog.Default(),
+			Limit:      100,
+		})
+
+		_, _, err := client.OldestLog(t.Context(), `{app="test"}`, time.Now().Add(-1*time.Hour), time.Now())
+		require.EqualError(t, err, "executing request: connection refused")
+	})
+}
+
 func mustParseInt64(t *testing.T, s string) int64 {
 	t.Helper()
 	v, err := strconv.ParseInt(s, 10, 64)
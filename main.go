@@ -82,6 +82,10 @@ func run(ctx context.Context, configPath, secretsPath string, w io.Writer) error
 	if err != nil {
 		return errors.Wrap(err, "reading secrets")
 	}
+
+	if err := cfg.ValidateSecrets(secrets); err != nil {
+		return errors.Wrap(err, "validating secrets against configuration")
+	}
 	l := logger(w, cfg.Log.Level)
 
 	f, err := cfg.Initialise(l, registry, secrets)
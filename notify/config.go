@@ -0,0 +1,51 @@
+package notify
+
+type Config struct {
+	// Events on which owners are notified. Defaults to all events.
+	Events  []Event        `yaml:"events" validate:"dive,oneof=proposed archived deleted"`
+	Owners  OwnersConfig   `yaml:"owners"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+	Email   *EmailConfig   `yaml:"email"`
+}
+
+// OwnersConfig determines the owner of dashboards that have neither an owner tag nor a creator.
+type OwnersConfig struct {
+	// Folders maps folders to the owner of the dashboards in them, including dashboards in nested subfolders. Each
+	// folder is either a folder UID or a title path, i.e., the titles of the folder and its parent folders separated by
+	// '/'.
+	Folders map[string]string `yaml:"folders" validate:"dive,keys,required,endkeys,required"`
+	// Default is the owner of dashboards whose owner cannot be resolved otherwise.
+	Default string `yaml:"default"`
+}
+
+// WebhookConfig sends each message as JSON in the body of a POST request to URL.
+type WebhookConfig struct {
+	URL string `yaml:"url" validate:"required,url"`
+}
+
+// EmailConfig sends each message as an email through an SMTP server.
+type EmailConfig struct {
+	Host string `yaml:"host" validate:"required"`
+	Port int    `yaml:"port" validate:"required,min=1,max=65535"`
+	From string `yaml:"from" validate:"required,email"`
+	// Username authenticates with the SMTP server along with EmailSecrets.Password. Emails are sent without
+	// authentication if Username is empty.
+	Username string `yaml:"username"`
+	// To receive the messages of owners that are not email addresses.
+	To []string `yaml:"to" validate:"dive,email"`
+}
+
+// Secrets of the notification channels.
+type Secrets struct {
+	Slack *SlackSecrets `yaml:"slack" json:"slack"`
+	Email *EmailSecrets `yaml:"email" json:"email"`
+}
+
+// SlackSecrets enable notifications through a Slack incoming webhook.
+type SlackSecrets struct {
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url" validate:"required,url"`
+}
+
+type EmailSecrets struct {
+	Password string `yaml:"password" json:"password" validate:"required"`
+}
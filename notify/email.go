@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// email sends each message to its owner through an SMTP server. Messages of owners that are not email addresses are
+// sent to the configured fallback recipients instead.
+type email struct {
+	addr   string
+	from   string
+	to     []string
+	auth   smtp.Auth
+	logger *slog.Logger
+}
+
+func newEmail(config *EmailConfig, password string, logger *slog.Logger) *email {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, password, config.Host)
+	}
+
+	return &email{
+		addr:   net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		from:   config.From,
+		to:     config.To,
+		auth:   auth,
+		logger: logger,
+	}
+}
+
+// Send sends message as an email. ctx is not used as net/smtp does not support contexts.
+func (e *email) Send(_ context.Context, message *Message) error {
+	to := e.to
+	if address, err := mail.ParseAddress(message.Owner); err == nil {
+		to = []string{address.Address}
+	}
+
+	if len(to) == 0 {
+		e.logger.Warn("Skipping email to dashboard owner without email address", slog.String("owner", message.Owner))
+		return nil
+	}
+
+	messageID, err := e.messageID()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject()))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Text(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(e.addr, e.auth, e.from, to, []byte(b.String())); err != nil {
+		return errors.Wrap(err, "sending email")
+	}
+
+	return nil
+}
+
+// messageID returns a new globally unique Message-ID for an email. Many mail servers and spam filters penalise emails
+// without a Message-ID. The domain of the Message-ID is the domain of the sender.
+func (e *email) messageID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "generating message ID")
+	}
+
+	domain := "frigg"
+	if address, err := mail.ParseAddress(e.from); err == nil {
+		if _, d, found := strings.Cut(address.Address, "@"); found {
+			domain = d
+		}
+	}
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain), nil
+}
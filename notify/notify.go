@@ -0,0 +1,266 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+// Event is the occasion on which the owners of dashboards are notified.
+type Event string
+
+const (
+	// EventProposed notifies owners ahead of the deletion of their dashboards, once the deletion has been proposed.
+	EventProposed Event = "proposed"
+	// EventArchived notifies owners right after their dashboards have been moved to the archive folder.
+	EventArchived Event = "archived"
+	// EventDeleted notifies owners right after their dashboards have been deleted.
+	EventDeleted Event = "deleted"
+)
+
+// restoreInstructions explain how to restore a deleted dashboard from its backup.
+const restoreInstructions = "To restore a dashboard, download its backup and import it in Grafana under Dashboards > " +
+	"New > Import. The restored dashboard keeps its UID, so existing links to it work again."
+
+// archiveInstructions explain how to restore an archived dashboard.
+const archiveInstructions = "To restore a dashboard, move it out of the archive folder."
+
+// snoozer creates links that snooze the deletion of a dashboard.
+type snoozer interface {
+	SnoozeURL(dashboard *backup.Dashboard) string
+}
+
+// channel delivers messages to owners.
+type channel interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// Message notifies a single owner about the deletion of some of their dashboards.
+type Message struct {
+	Event Event `json:"event"`
+	// Owner of the dashboards. Owner is empty if the owner of the dashboards could not be resolved.
+	Owner               string      `json:"owner"`
+	Dashboards          []Dashboard `json:"dashboards"`
+	RestoreInstructions string      `json:"restore_instructions"`
+}
+
+// Dashboard is a dashboard in a Message.
+type Dashboard struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	// Folder is the title of the folder that contains the dashboard. Folder is empty for dashboards at the root.
+	Folder string `json:"folder"`
+	// LastViewed is zero if the dashboard was not viewed in the prune period.
+	LastViewed time.Time `json:"last_viewed,omitzero"`
+	// Backup is the location of the dashboard's backup. See backup.Dashboard.Location. Backup is empty for dashboards
+	// that have not been backed up, e.g., archived dashboards, which are only backed up once they are deleted.
+	Backup string `json:"backup,omitempty"`
+	// Snooze is a link that keeps the dashboard, e.g., while it is restored. Snooze is empty if snooze links are not
+	// enabled.
+	Snooze string `json:"snooze,omitempty"`
+}
+
+// Notifier notifies the owners of dashboards about their deletion, grouping the dashboards of each owner into a single
+// message per channel.
+type Notifier struct {
+	channels []channel
+	events   map[Event]struct{}
+	snoozer  snoozer
+	logger   *slog.Logger
+}
+
+type NewNotifierOptions struct {
+	Config  *Config
+	Secrets *Secrets
+	// Snoozer adds a snooze link to each dashboard in a message. Messages have no snooze links if Snoozer is nil.
+	Snoozer    snoozer
+	HTTPClient *http.Client
+	Logger     *slog.Logger
+}
+
+// NewNotifier creates a Notifier that sends messages to every channel that is configured. NewNotifier returns an error
+// if no channel is configured.
+func NewNotifier(opts *NewNotifierOptions) (*Notifier, error) {
+	var channels []channel
+	if opts.Config.Webhook != nil {
+		channels = append(channels, &webhook{url: opts.Config.Webhook.URL, httpClient: opts.HTTPClient})
+	}
+	if opts.Secrets.Slack != nil {
+		channels = append(channels, &slack{url: opts.Secrets.Slack.WebhookURL, httpClient: opts.HTTPClient})
+	}
+	if email := opts.Config.Email; email != nil {
+		password := ""
+		if opts.Secrets.Email != nil {
+			password = opts.Secrets.Email.Password
+		}
+		channels = append(channels, newEmail(email, password, opts.Logger))
+	}
+
+	if len(channels) == 0 {
+		return nil, errors.New("at least one of the webhook, Slack and email channels must be configured")
+	}
+
+	events := opts.Config.Events
+	if len(events) == 0 {
+		events = []Event{EventProposed, EventArchived, EventDeleted}
+	}
+
+	n := &Notifier{
+		channels: channels,
+		events:   make(map[Event]struct{}, len(events)),
+		snoozer:  opts.Snoozer,
+		logger:   opts.Logger,
+	}
+	for _, event := range events {
+		n.events[event] = struct{}{}
+	}
+
+	return n, nil
+}
+
+// Notify sends a message about event to the owner of each of dashboards through every channel. Notify does nothing if
+// the Notifier is not configured for event. A failure to notify one owner does not prevent the others from being
+// notified.
+func (n *Notifier) Notify(ctx context.Context, event Event, dashboards []backup.Dashboard) error {
+	if _, ok := n.events[event]; !ok || len(dashboards) == 0 {
+		return nil
+	}
+
+	byOwner := make(map[string][]Dashboard)
+	for i := range dashboards {
+		dashboard := &dashboards[i]
+
+		snooze := ""
+		if n.snoozer != nil {
+			snooze = n.snoozer.SnoozeURL(dashboard)
+		}
+
+		byOwner[dashboard.Owner] = append(byOwner[dashboard.Owner], Dashboard{
+			Namespace:  dashboard.Namespace,
+			Name:       dashboard.Name,
+			Title:      dashboard.Title,
+			Folder:     dashboard.FolderTitle,
+			LastViewed: dashboard.LastViewed,
+			Backup:     dashboard.Location,
+			Snooze:     snooze,
+		})
+	}
+
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	instructions := restoreInstructions
+	if event == EventArchived {
+		instructions = archiveInstructions
+	}
+
+	var errs error
+	for _, owner := range owners {
+		message := &Message{
+			Event:               event,
+			Owner:               owner,
+			Dashboards:          byOwner[owner],
+			RestoreInstructions: instructions,
+		}
+
+		var ownerErrs error
+		for _, c := range n.channels {
+			ownerErrs = multierr.Append(ownerErrs, c.Send(ctx, message))
+		}
+		if ownerErrs != nil {
+			errs = multierr.Append(errs, errors.Wrapf(ownerErrs, "notifying owner %q", owner))
+			continue
+		}
+
+		n.logger.Info(
+			"Notified dashboard owner",
+			slog.String("event", string(event)),
+			slog.String("owner", owner),
+			slog.Int("dashboard_count", len(message.Dashboards)),
+		)
+	}
+
+	return errs
+}
+
+// Subject summarises m in a single line.
+func (m *Message) Subject() string {
+	verb := "were deleted"
+	switch m.Event {
+	case EventProposed:
+		verb = "will be deleted"
+	case EventArchived:
+		verb = "were archived"
+	case EventDeleted:
+	}
+
+	return fmt.Sprintf("%d unused Grafana dashboard(s) of %s %s", len(m.Dashboards), m.ownerName(), verb)
+}
+
+// Text renders m as plain text.
+func (m *Message) Text() string {
+	var b strings.Builder
+
+	switch m.Event {
+	case EventProposed:
+		fmt.Fprintf(&b, "The following Grafana dashboards of %s have not been used recently and will be deleted once "+
+			"their deletion has been approved. Open a dashboard before then to keep it.\n\n", m.ownerName())
+	case EventArchived:
+		fmt.Fprintf(&b, "The following Grafana dashboards of %s were moved to the archive folder as they had not been "+
+			"used recently. They will be deleted once their archive period has ended.\n\n", m.ownerName())
+	case EventDeleted:
+		fmt.Fprintf(&b, "The following Grafana dashboards of %s were deleted as they had not been used recently.\n\n",
+			m.ownerName())
+	}
+
+	for _, dashboard := range m.Dashboards {
+		fmt.Fprintf(&b, "- %q (%s/%s)", dashboard.Title, dashboard.Namespace, dashboard.Name)
+		if dashboard.Folder != "" {
+			fmt.Fprintf(&b, " in folder %q", dashboard.Folder)
+		}
+		b.WriteString("\n")
+
+		if dashboard.LastViewed.IsZero() {
+			b.WriteString("  Last viewed: not in the prune period\n")
+		} else {
+			fmt.Fprintf(&b, "  Last viewed: %s\n", dashboard.LastViewed.UTC().Format(time.RFC3339))
+		}
+		if dashboard.Backup != "" {
+			fmt.Fprintf(&b, "  Backup: %s\n", dashboard.Backup)
+		}
+		if dashboard.Snooze != "" && m.Event != EventDeleted {
+			fmt.Fprintf(&b, "  Keep: %s\n", dashboard.Snooze)
+		} else if dashboard.Snooze != "" {
+			// A restored dashboard is deleted again by the next run unless it is used or snoozed.
+			fmt.Fprintf(&b, "  Keep once restored: %s\n", dashboard.Snooze)
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.RestoreInstructions)
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// ownerName returns the owner of m for use in sentences.
+func (m *Message) ownerName() string {
+	if m.Owner == "" {
+		return "an unknown owner"
+	}
+
+	return m.Owner
+}
@@ -0,0 +1,402 @@
+package notify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/notify"
+)
+
+// recorder is an HTTP stand-in for a webhook that records the body of each request.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+	status int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, string(body))
+
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		_, _ = w.Write([]byte("unavailable"))
+	}
+}
+
+func (r *recorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.bodies...)
+}
+
+// email is an email received by the SMTP stand-in.
+type email struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer starts a minimal SMTP stand-in that accepts every email without authentication and returns its host and
+// port along with the emails that it receives.
+func smtpServer(t *testing.T) (string, int, <-chan email) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	emails := make(chan email, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, emails)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), addr.Port, emails
+}
+
+func serveSMTP(conn net.Conn, emails chan<- email) {
+	c := textproto.NewConn(conn)
+	defer func() {
+		_ = c.Close()
+	}()
+
+	_ = c.PrintfLine("220 localhost ESMTP")
+
+	var e email
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			_ = c.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			e.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			_ = c.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			e.to = append(e.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			_ = c.PrintfLine("250 OK")
+		case command == "DATA":
+			_ = c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			// ReadDotBytes converts the CRLF line endings of the email to LF.
+			e.data = string(data)
+			emails <- e
+			e = email{}
+			_ = c.PrintfLine("250 OK")
+		case command == "QUIT":
+			_ = c.PrintfLine("221 Bye")
+			return
+		default:
+			_ = c.PrintfLine("250 OK")
+		}
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	t.Parallel()
+
+	_, err := notify.NewNotifier(&notify.NewNotifierOptions{
+		Config:  &notify.Config{},
+		Secrets: &notify.Secrets{},
+		Logger:  slog.Default(),
+	})
+	require.EqualError(t, err, "at least one of the webhook, Slack and email channels must be configured")
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	webhook := &recorder{}
+	webhookServer := httptest.NewServer(webhook)
+	defer webhookServer.Close()
+
+	slack := &recorder{}
+	slackServer := httptest.NewServer(slack)
+	defer slackServer.Close()
+
+	host, port, emails := smtpServer(t)
+
+	notifier, err := notify.NewNotifier(&notify.NewNotifierOptions{
+		Config: &notify.Config{
+			Webhook: &notify.WebhookConfig{URL: webhookServer.URL},
+			Email: &notify.EmailConfig{
+				Host: host,
+				Port: port,
+				From: "frigg@example.com",
+				To:   []string{"grafana-admins@example.com"},
+			},
+		},
+		Secrets: &notify.Secrets{
+			Slack: &notify.SlackSecrets{WebhookURL: slackServer.URL},
+		},
+		HTTPClient: http.DefaultClient,
+		Logger:     slog.Default(),
+	})
+	require.NoError(t, err)
+
+	dashboards := []backup.Dashboard{
+		{
+			Namespace:   "default",
+			Name:        "latency",
+			Title:       "Latency",
+			FolderTitle: "Team A",
+			LastViewed:  time.Date(2026, time.August, 3, 9, 30, 0, 0, time.UTC),
+			Owner:       "alice@example.com",
+			Location:    "https://github.com/octocat/backups/blob/main/default/latency.json",
+		},
+		{
+			Namespace: "default",
+			Name:      "hosts",
+			Title:     "Hosts",
+			Owner:     "team-b",
+			Location:  "https://github.com/octocat/backups/blob/main/default/hosts.json",
+		},
+		{
+			Namespace: "default",
+			Name:      "errors",
+			Title:     "Errors",
+			Owner:     "alice@example.com",
+			Location:  "https://github.com/octocat/backups/blob/main/default/errors.json",
+		},
+	}
+
+	require.NoError(t, notifier.Notify(t.Context(), notify.EventDeleted, dashboards))
+
+	//nolint:lll
+	expectedWebhookBodies := []string{
+		`{"event":"deleted","owner":"alice@example.com","dashboards":[{"namespace":"default","name":"latency","title":"Latency","folder":"Team A","last_viewed":"2026-08-03T09:30:00Z","backup":"https://github.com/octocat/backups/blob/main/default/latency.json"},{"namespace":"default","name":"errors","title":"Errors","folder":"","backup":"https://github.com/octocat/backups/blob/main/default/errors.json"}],"restore_instructions":"To restore a dashboard, download its backup and import it in Grafana under Dashboards \u003e New \u003e Import. The restored dashboard keeps its UID, so existing links to it work again."}`,
+		`{"event":"deleted","owner":"team-b","dashboards":[{"namespace":"default","name":"hosts","title":"Hosts","folder":"","backup":"https://github.com/octocat/backups/blob/main/default/hosts.json"}],"restore_instructions":"To restore a dashboard, download its backup and import it in Grafana under Dashboards \u003e New \u003e Import. The restored dashboard keeps its UID, so existing links to it work again."}`,
+	}
+	assert.Equal(t, expectedWebhookBodies, webhook.received())
+
+	//nolint:lll
+	expectedText := `The following Grafana dashboards of alice@example.com were deleted as they had not been used recently.
+
+- "Latency" (default/latency) in folder "Team A"
+  Last viewed: 2026-08-03T09:30:00Z
+  Backup: https://github.com/octocat/backups/blob/main/default/latency.json
+- "Errors" (default/errors)
+  Last viewed: not in the prune period
+  Backup: https://github.com/octocat/backups/blob/main/default/errors.json
+
+To restore a dashboard, download its backup and import it in Grafana under Dashboards > New > Import. The restored dashboard keeps its UID, so existing links to it work again.
+`
+
+	slackBodies := slack.received()
+	require.Len(t, slackBodies, 2)
+	var slackMessage struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(slackBodies[0]), &slackMessage))
+	expectedSlackText := "*2 unused Grafana dashboard(s) of alice@example.com were deleted*\n\n" + expectedText
+	assert.Equal(t, expectedSlackText, slackMessage.Text)
+
+	aliceEmail := <-emails
+	assert.Equal(t, "frigg@example.com", aliceEmail.from)
+	assert.Equal(t, []string{"alice@example.com"}, aliceEmail.to)
+	assert.Contains(t, aliceEmail.data, "Subject: 2 unused Grafana dashboard(s) of alice@example.com were deleted\n")
+	assert.Contains(t, aliceEmail.data, expectedText)
+	assert.Regexp(t, `(?m)^Date: \w{3}, \d{2} \w{3} \d{4} \d{2}:\d{2}:\d{2} [+-]\d{4}$`, aliceEmail.data)
+	assert.Regexp(t, `(?m)^Message-ID: <[0-9a-f]{32}@example\.com>$`, aliceEmail.data)
+
+	// team-b is not an email address, so its email is sent to the fallback recipients.
+	teamEmail := <-emails
+	assert.Equal(t, []string{"grafana-admins@example.com"}, teamEmail.to)
+	assert.Contains(t, teamEmail.data, "Subject: 1 unused Grafana dashboard(s) of team-b were deleted\n")
+}
+
+func TestNotifier_Notify_Events(t *testing.T) {
+	t.Parallel()
+
+	webhook := &recorder{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	notifier, err := notify.NewNotifier(&notify.NewNotifierOptions{
+		Config: &notify.Config{
+			Events:  []notify.Event{notify.EventProposed},
+			Webhook: &notify.WebhookConfig{URL: server.URL},
+		},
+		Secrets:    &notify.Secrets{},
+		HTTPClient: http.DefaultClient,
+		Logger:     slog.Default(),
+	})
+	require.NoError(t, err)
+
+	dashboards := []backup.Dashboard{{Namespace: "default", Name: "latency", Title: "Latency", Owner: "team-a"}}
+
+	require.NoError(t, notifier.Notify(t.Context(), notify.EventDeleted, dashboards))
+	assert.Empty(t, webhook.received())
+
+	require.NoError(t, notifier.Notify(t.Context(), notify.EventProposed, dashboards))
+	require.Len(t, webhook.received(), 1)
+	assert.Contains(t, webhook.received()[0], `"event":"proposed"`)
+	// The dashboard has not been backed up, so its message has no backup.
+	assert.NotContains(t, webhook.received()[0], `"backup"`)
+}
+
+func TestNotifier_Notify_Archived(t *testing.T) {
+	t.Parallel()
+
+	webhook := &recorder{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	slack := &recorder{}
+	slackServer := httptest.NewServer(slack)
+	defer slackServer.Close()
+
+	notifier, err := notify.NewNotifier(&notify.NewNotifierOptions{
+		Config:     &notify.Config{Webhook: &notify.WebhookConfig{URL: server.URL}},
+		Secrets:    &notify.Secrets{Slack: &notify.SlackSecrets{WebhookURL: slackServer.URL}},
+		HTTPClient: http.DefaultClient,
+		Logger:     slog.Default(),
+	})
+	require.NoError(t, err)
+
+	dashboards := []backup.Dashboard{
+		{Namespace: "default", Name: "latency", Title: "Latency", FolderTitle: "Team A", Owner: "team-a"},
+	}
+
+	require.NoError(t, notifier.Notify(t.Context(), notify.EventArchived, dashboards))
+
+	//nolint:lll
+	expectedWebhookBodies := []string{
+		`{"event":"archived","owner":"team-a","dashboards":[{"namespace":"default","name":"latency","title":"Latency","folder":"Team A"}],"restore_instructions":"To restore a dashboard, move it out of the archive folder."}`,
+	}
+	assert.Equal(t, expectedWebhookBodies, webhook.received())
+
+	slackBodies := slack.received()
+	require.Len(t, slackBodies, 1)
+	var slackMessage struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(slackBodies[0]), &slackMessage))
+	//nolint:lll
+	expectedText := `*1 unused Grafana dashboard(s) of team-a were archived*
+
+The following Grafana dashboards of team-a were moved to the archive folder as they had not been used recently. They will be deleted once their archive period has ended.
+
+- "Latency" (default/latency) in folder "Team A"
+  Last viewed: not in the prune period
+
+To restore a dashboard, move it out of the archive folder.
+`
+	assert.Equal(t, expectedText, slackMessage.Text)
+}
+
+func TestNotifier_Notify_Failures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("notifies remaining owners if a message fails", func(t *testing.T) {
+		t.Parallel()
+
+		webhook := &recorder{status: http.StatusServiceUnavailable}
+		server := httptest.NewServer(webhook)
+		defer server.Close()
+
+		var logs bytes.Buffer
+		notifier, err := notify.NewNotifier(&notify.NewNotifierOptions{
+			Config:     &notify.Config{Webhook: &notify.WebhookConfig{URL: server.URL}},
+			Secrets:    &notify.Secrets{},
+			HTTPClient: http.DefaultClient,
+			Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
+		})
+		require.NoError(t, err)
+
+		dashboards := []backup.Dashboard{
+			{Namespace: "default", Name: "latency", Owner: "team-a"},
+			{Namespace: "default", Name: "hosts", Owner: "team-b"},
+		}
+
+		err = notifier.Notify(t.Context(), notify.EventDeleted, dashboards)
+		require.EqualError(
+			t,
+			err,
+			`notifying owner "team-a": sending webhook: unexpected status code: 503, body: unavailable; `+
+				`notifying owner "team-b": sending webhook: unexpected status code: 503, body: unavailable`,
+		)
+		assert.Len(t, webhook.received(), 2)
+		assert.Empty(t, logs.String())
+	})
+}
+
+// snoozerFunc returns the snooze link of a dashboard.
+type snoozerFunc func(dashboard *backup.Dashboard) string
+
+func (f snoozerFunc) SnoozeURL(dashboard *backup.Dashboard) string {
+	return f(dashboard)
+}
+
+func TestNotifier_Notify_SnoozeLinks(t *testing.T) {
+	t.Parallel()
+
+	webhook := &recorder{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	notifier, err := notify.NewNotifier(&notify.NewNotifierOptions{
+		Config:  &notify.Config{Webhook: &notify.WebhookConfig{URL: server.URL}},
+		Secrets: &notify.Secrets{},
+		Snoozer: snoozerFunc(func(dashboard *backup.Dashboard) string {
+			return "https://frigg.example.com/api/v1/snooze?ns=" + dashboard.Namespace + "&name=" + dashboard.Name
+		}),
+		HTTPClient: http.DefaultClient,
+		Logger:     slog.Default(),
+	})
+	require.NoError(t, err)
+
+	dashboards := []backup.Dashboard{{Namespace: "default", Name: "latency", Title: "Latency", Owner: "team-a"}}
+	require.NoError(t, notifier.Notify(t.Context(), notify.EventProposed, dashboards))
+
+	bodies := webhook.received()
+	require.Len(t, bodies, 1)
+	var message notify.Message
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &message))
+	require.Len(t, message.Dashboards, 1)
+	assert.Equal(t, "https://frigg.example.com/api/v1/snooze?ns=default&name=latency", message.Dashboards[0].Snooze)
+
+	assert.Contains(t, message.Text(), "  Keep: https://frigg.example.com/api/v1/snooze?ns=default&name=latency\n")
+
+	message.Event = notify.EventDeleted
+	assert.Contains(
+		t,
+		message.Text(),
+		"  Keep once restored: https://frigg.example.com/api/v1/snooze?ns=default&name=latency\n",
+	)
+}
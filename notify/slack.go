@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// slack posts each message to a Slack incoming webhook. The webhook determines the Slack channel.
+//
+// See https://docs.slack.dev/messaging/sending-messages-using-incoming-webhooks.
+type slack struct {
+	url        string
+	httpClient *http.Client
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func (s *slack) Send(ctx context.Context, message *Message) error {
+	body := slackMessage{Text: "*" + message.Subject() + "*\n\n" + message.Text()}
+	if err := postJSON(ctx, s.httpClient, s.url, body); err != nil {
+		return errors.Wrap(err, "posting Slack message")
+	}
+
+	return nil
+}
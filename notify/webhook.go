@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// maxErrorBodySize is the maximum number of bytes of a response body that are included in an error.
+const maxErrorBodySize = 1024
+
+// webhook sends each message as JSON to a generic webhook.
+type webhook struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (w *webhook) Send(ctx context.Context, message *Message) error {
+	if err := postJSON(ctx, w.httpClient, w.url, message); err != nil {
+		return errors.Wrap(err, "sending webhook")
+	}
+
+	return nil
+}
+
+// postJSON sends body as JSON in a POST request to u. postJSON returns an error if the response status code is not 2xx.
+func postJSON(ctx context.Context, httpClient *http.Client, u string, body any) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "encoding request body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(buf))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "making request")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, responseBody)
+	}
+
+	return nil
+}
@@ -4,3 +4,9 @@ type Config struct {
 	Host string `yaml:"host" validate:"required"`       // Host name of the Server.
 	Port int    `yaml:"port" validate:"required,min=1"` // Port for the Server to listen on.
 }
+
+// Secrets of the Server.
+type Secrets struct {
+	// APIToken authenticates requests to the Server's API. The API is disabled if APIToken is empty.
+	APIToken string `yaml:"api_token" json:"api_token" validate:"omitempty,min=16"`
+}
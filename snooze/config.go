@@ -0,0 +1,20 @@
+package snooze
+
+import "time"
+
+// DefaultDuration is the default Config.Duration.
+const DefaultDuration = 30 * 24 * time.Hour
+
+type Config struct {
+	// ExternalURL is the URL at which users reach Frigg's server. Snooze links point to ExternalURL.
+	ExternalURL string `yaml:"external_url" validate:"required,url"`
+	// Duration is how long a snooze link keeps a dashboard, counted from the prune run that created the link. Defaults
+	// to DefaultDuration. Duration has a minimum value of 1 hour (3600000000000 nanoseconds).
+	Duration time.Duration `yaml:"duration" validate:"omitempty,min=3600000000000"`
+}
+
+// Secrets of snooze links.
+type Secrets struct {
+	// SigningKey signs snooze links so that they cannot be forged or extended.
+	SigningKey string `yaml:"signing_key" json:"signing_key" validate:"required,min=32"`
+}
@@ -0,0 +1,162 @@
+package snooze
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stateKey is the key of the snoozes in the state store.
+const stateKey = "snoozes"
+
+// store persists snoozes between restarts of Frigg.
+type store interface {
+	Get(key string, target any) (bool, error)
+	Set(key string, value any) error
+}
+
+// Snooze exempts a dashboard from deletion until a point in time.
+type Snooze struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Until     time.Time `json:"until"`
+	Created   time.Time `json:"created"`
+}
+
+// Registry records snoozes in a state store.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	// mu guards the snoozes in store, which are read, changed and written back as a whole.
+	mu    sync.Mutex
+	store store
+}
+
+func NewRegistry(store store) *Registry {
+	return &Registry{store: store}
+}
+
+// Add records snooze. If the dashboard of snooze is already snoozed, the snooze that lasts longer is kept. Add removes
+// snoozes that expired before now.
+func (r *Registry) Add(snooze *Snooze, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snoozes, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]Snooze, 0, len(snoozes)+1)
+	added := false
+	for _, s := range snoozes {
+		if s.Namespace == snooze.Namespace && s.Name == snooze.Name {
+			if s.Until.After(snooze.Until) {
+				kept = append(kept, s)
+			} else {
+				kept = append(kept, *snooze)
+			}
+			added = true
+			continue
+		}
+		if s.Until.After(now) {
+			kept = append(kept, s)
+		}
+	}
+	if !added {
+		kept = append(kept, *snooze)
+	}
+
+	return r.save(kept)
+}
+
+// List returns the snoozes that have not expired at now, sorted by namespace and name.
+func (r *Registry) List(now time.Time) ([]Snooze, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snoozes, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]Snooze, 0, len(snoozes))
+	for _, s := range snoozes {
+		if s.Until.After(now) {
+			active = append(active, s)
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Namespace != active[j].Namespace {
+			return active[i].Namespace < active[j].Namespace
+		}
+		return active[i].Name < active[j].Name
+	})
+
+	return active, nil
+}
+
+// Revoke removes the snooze of the dashboard with name in namespace. Revoke returns false if the dashboard is not
+// snoozed.
+func (r *Registry) Revoke(namespace, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snoozes, err := r.load()
+	if err != nil {
+		return false, err
+	}
+
+	kept := make([]Snooze, 0, len(snoozes))
+	for _, s := range snoozes {
+		if s.Namespace != namespace || s.Name != name {
+			kept = append(kept, s)
+		}
+	}
+
+	if len(kept) == len(snoozes) {
+		return false, nil
+	}
+
+	return true, r.save(kept)
+}
+
+// Snoozed returns the time until which each dashboard in namespace that is snoozed at now is snoozed, keyed by the
+// name of the dashboard.
+func (r *Registry) Snoozed(namespace string, now time.Time) (map[string]time.Time, error) {
+	snoozes, err := r.List(now)
+	if err != nil {
+		return nil, err
+	}
+
+	snoozed := make(map[string]time.Time)
+	for _, s := range snoozes {
+		if s.Namespace == namespace {
+			snoozed[s.Name] = s.Until
+		}
+	}
+
+	return snoozed, nil
+}
+
+// load returns the snoozes in the store. load must be called with mu held.
+func (r *Registry) load() ([]Snooze, error) {
+	var snoozes []Snooze
+	if _, err := r.store.Get(stateKey, &snoozes); err != nil {
+		return nil, errors.Wrap(err, "getting snoozes")
+	}
+
+	return snoozes, nil
+}
+
+// save writes snoozes to the store. save must be called with mu held.
+func (r *Registry) save(snoozes []Snooze) error {
+	if err := r.store.Set(stateKey, snoozes); err != nil {
+		return errors.Wrap(err, "saving snoozes")
+	}
+
+	return nil
+}
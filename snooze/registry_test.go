@@ -0,0 +1,124 @@
+package snooze_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/snooze"
+	"github.com/LasseHels/frigg/state"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("lists snoozes that have not expired", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := snooze.NewRegistry(store)
+
+		require.NoError(t, registry.Add(&snooze.Snooze{
+			Namespace: "org-2",
+			Name:      "hosts",
+			Until:     now.Add(48 * time.Hour),
+			Created:   now,
+		}, now))
+		require.NoError(t, registry.Add(&snooze.Snooze{
+			Namespace: "default",
+			Name:      "latency",
+			Until:     now.Add(24 * time.Hour),
+			Created:   now,
+		}, now))
+		require.NoError(t, registry.Add(&snooze.Snooze{
+			Namespace: "default",
+			Name:      "errors",
+			Until:     now.Add(time.Hour),
+			Created:   now,
+		}, now))
+
+		snoozes, err := registry.List(now.Add(2 * time.Hour))
+		require.NoError(t, err)
+		expected := []snooze.Snooze{
+			{Namespace: "default", Name: "latency", Until: now.Add(24 * time.Hour), Created: now},
+			{Namespace: "org-2", Name: "hosts", Until: now.Add(48 * time.Hour), Created: now},
+		}
+		assert.Equal(t, expected, snoozes)
+
+		snoozed, err := registry.Snoozed("default", now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]time.Time{"latency": now.Add(24 * time.Hour)}, snoozed)
+	})
+
+	t.Run("keeps snooze that lasts longer", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := snooze.NewRegistry(store)
+
+		long := &snooze.Snooze{Namespace: "default", Name: "latency", Until: now.Add(48 * time.Hour), Created: now}
+		short := &snooze.Snooze{Namespace: "default", Name: "latency", Until: now.Add(time.Hour), Created: now}
+		require.NoError(t, registry.Add(long, now))
+		require.NoError(t, registry.Add(short, now))
+
+		snoozes, err := registry.List(now)
+		require.NoError(t, err)
+		assert.Equal(t, []snooze.Snooze{*long}, snoozes)
+	})
+
+	t.Run("revokes snooze", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := state.NewStore("")
+		require.NoError(t, err)
+		registry := snooze.NewRegistry(store)
+
+		require.NoError(t, registry.Add(&snooze.Snooze{
+			Namespace: "default",
+			Name:      "latency",
+			Until:     now.Add(time.Hour),
+			Created:   now,
+		}, now))
+
+		revoked, err := registry.Revoke("default", "latency")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = registry.Revoke("default", "latency")
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		snoozes, err := registry.List(now)
+		require.NoError(t, err)
+		assert.Empty(t, snoozes)
+	})
+
+	t.Run("persists snoozes", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "state.json")
+		store, err := state.NewStore(path)
+		require.NoError(t, err)
+
+		require.NoError(t, snooze.NewRegistry(store).Add(&snooze.Snooze{
+			Namespace: "default",
+			Name:      "latency",
+			Until:     now.Add(time.Hour),
+			Created:   now,
+		}, now))
+
+		reloaded, err := state.NewStore(path)
+		require.NoError(t, err)
+
+		snoozed, err := snooze.NewRegistry(reloaded).Snoozed("default", now)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]time.Time{"latency": now.Add(time.Hour)}, snoozed)
+	})
+}
@@ -0,0 +1,73 @@
+package snooze
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/LasseHels/frigg/backup"
+)
+
+// Signer signs and verifies snooze links with HMAC-SHA256.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key string) *Signer {
+	return &Signer{key: []byte(key)}
+}
+
+// Sign returns the signature of a snooze of the dashboard with name in namespace until until.
+func (s *Signer) Sign(namespace, name string, until time.Time) string {
+	mac := hmac.New(sha256.New, s.key)
+	// The fields are separated by a newline, which neither a namespace nor a name can contain, so that different
+	// fields cannot produce the same signature.
+	mac.Write([]byte(namespace + "\n" + name + "\n" + until.UTC().Format(time.RFC3339)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a snooze of the dashboard with name in namespace until until.
+func (s *Signer) Verify(namespace, name string, until time.Time, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	actual, _ := hex.DecodeString(s.Sign(namespace, name, until))
+
+	return hmac.Equal(expected, actual)
+}
+
+// Links creates signed snooze links.
+type Links struct {
+	externalURL *url.URL
+	duration    time.Duration
+	signer      *Signer
+}
+
+// NewLinks creates Links that point to externalURL and keep dashboards for duration.
+func NewLinks(externalURL *url.URL, duration time.Duration, signer *Signer) *Links {
+	return &Links{
+		externalURL: externalURL,
+		duration:    duration,
+		signer:      signer,
+	}
+}
+
+// SnoozeURL returns a link that keeps dashboard until the configured duration after the start of its prune run.
+func (l *Links) SnoozeURL(dashboard *backup.Dashboard) string {
+	until := dashboard.RunStart.Add(l.duration).UTC().Truncate(time.Second)
+
+	u := l.externalURL.JoinPath("api", "v1", "snooze")
+	q := url.Values{}
+	q.Set("ns", dashboard.Namespace)
+	q.Set("name", dashboard.Name)
+	q.Set("until", until.Format(time.RFC3339))
+	q.Set("sig", l.signer.Sign(dashboard.Namespace, dashboard.Name, until))
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
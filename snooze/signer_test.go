@@ -0,0 +1,104 @@
+package snooze_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/backup"
+	"github.com/LasseHels/frigg/snooze"
+)
+
+const signingKey = "a-signing-key-of-at-least-32-bytes"
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+
+	signer := snooze.NewSigner(signingKey)
+	until := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	signature := signer.Sign("default", "latency", until)
+
+	tests := map[string]struct {
+		namespace string
+		name      string
+		until     time.Time
+		signature string
+		expected  bool
+	}{
+		"accepts signature of snooze": {
+			namespace: "default",
+			name:      "latency",
+			until:     until,
+			signature: signature,
+			expected:  true,
+		},
+		"rejects signature of other dashboard": {
+			namespace: "default",
+			name:      "errors",
+			until:     until,
+			signature: signature,
+			expected:  false,
+		},
+		"rejects extended snooze": {
+			namespace: "default",
+			name:      "latency",
+			until:     until.Add(24 * time.Hour),
+			signature: signature,
+			expected:  false,
+		},
+		"rejects signature of other key": {
+			namespace: "default",
+			name:      "latency",
+			until:     until,
+			signature: snooze.NewSigner("another-signing-key-of-at-least-32-bytes").Sign("default", "latency", until),
+			expected:  false,
+		},
+		"rejects malformed signature": {
+			namespace: "default",
+			name:      "latency",
+			until:     until,
+			signature: "not-hex",
+			expected:  false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, signer.Verify(tt.namespace, tt.name, tt.until, tt.signature))
+		})
+	}
+}
+
+func TestLinks_SnoozeURL(t *testing.T) {
+	t.Parallel()
+
+	externalURL, err := url.Parse("https://frigg.example.com/prefix")
+	require.NoError(t, err)
+
+	signer := snooze.NewSigner(signingKey)
+	links := snooze.NewLinks(externalURL, 7*24*time.Hour, signer)
+
+	link := links.SnoozeURL(&backup.Dashboard{
+		Namespace: "default",
+		Name:      "latency",
+		RunStart:  time.Date(2026, time.October, 1, 12, 30, 15, 500, time.UTC),
+	})
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "https://frigg.example.com/prefix/api/v1/snooze", u.Scheme+"://"+u.Host+u.Path)
+
+	q := u.Query()
+	assert.Equal(t, "default", q.Get("ns"))
+	assert.Equal(t, "latency", q.Get("name"))
+	assert.Equal(t, "2026-10-08T12:30:15Z", q.Get("until"))
+
+	until, err := time.Parse(time.RFC3339, q.Get("until"))
+	require.NoError(t, err)
+	assert.True(t, signer.Verify("default", "latency", until, q.Get("sig")))
+}
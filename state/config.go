@@ -0,0 +1,7 @@
+package state
+
+type Config struct {
+	// Path of the file that Store persists state to. If empty, state is only kept in memory and is lost when Frigg
+	// restarts.
+	Path string `yaml:"path"`
+}
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Store holds state that must survive between prune runs, e.g., statistics of earlier runs. Store keeps state in
+// memory and, if it has a path, persists state to a JSON file after each change.
+//
+// Store is safe for concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	values map[string]json.RawMessage
+}
+
+// NewStore creates a Store that persists state to the file at path. NewStore loads existing state from the file if it
+// exists. If path is empty, the Store only keeps state in memory.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:   path,
+		values: make(map[string]json.RawMessage),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading state file at path %q", path)
+	}
+
+	if err := json.Unmarshal(buf, &s.values); err != nil {
+		return nil, errors.Wrapf(err, "parsing state file at path %q", path)
+	}
+
+	return s, nil
+}
+
+// Get decodes the value of key into target. Get returns false if key has no value.
+func (s *Store) Get(key string, target any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.values[key]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(buf, target); err != nil {
+		return false, errors.Wrapf(err, "decoding state %q", key)
+	}
+
+	return true, nil
+}
+
+// Set sets the value of key and persists the state of the Store.
+func (s *Store) Set(key string, value any) error {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "encoding state %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = buf
+
+	return s.persist()
+}
+
+// persist writes the state to the file of the Store. persist writes to a temporary file first and then renames it so
+// that the file is never left half-written. persist must be called with mu held.
+func (s *Store) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	buf, err := json.MarshalIndent(s.values, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding state")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary state file")
+	}
+	defer func() {
+		// The temporary file no longer exists if it has been renamed.
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(buf); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "writing temporary state file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "closing temporary state file")
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return errors.Wrapf(err, "renaming temporary state file to %q", s.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LasseHels/frigg/state"
+)
+
+type value struct {
+	Count int `json:"count"`
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("persists state across stores", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "state.json")
+
+		s, err := state.NewStore(path)
+		require.NoError(t, err)
+
+		var v value
+		found, err := s.Get("key", &v)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.NoError(t, s.Set("key", value{Count: 3}))
+
+		reloaded, err := state.NewStore(path)
+		require.NoError(t, err)
+
+		found, err = reloaded.Get("key", &v)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, value{Count: 3}, v)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary state files must be removed")
+	})
+
+	t.Run("keeps state in memory without path", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := state.NewStore("")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Set("key", value{Count: 5}))
+
+		var v value
+		found, err := s.Get("key", &v)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, value{Count: 5}, v)
+	})
+
+	t.Run("returns error for malformed state file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+		s, err := state.NewStore(path)
+		require.ErrorContains(t, err, `parsing state file at path "`+path+`": invalid character`)
+		assert.Nil(t, s)
+	})
+}